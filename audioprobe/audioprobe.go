@@ -0,0 +1,332 @@
+package audioprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupported is returned for data that is not audio in one of the
+// supported formats, or is too damaged to read its duration
+var ErrUnsupported = errors.New("unsupported audio format")
+
+// Info is what Probe found out about an audio file
+type Info struct {
+	MimeType   string
+	DurationMs int
+}
+
+// Probe detects the format of an audio file from its content, not its name,
+// and measures its duration. MP3, AAC (ADTS), M4A, Ogg Vorbis/Opus and WAV are
+// supported.
+func Probe(data []byte) (Info, error) {
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return probeWAV(data)
+	case len(data) >= 4 && string(data[0:4]) == "OggS":
+		return probeOgg(data)
+	case len(data) >= 8 && string(data[4:8]) == "ftyp":
+		return probeMP4(data)
+	}
+
+	// MP3 and ADTS streams may start with an ID3v2 tag
+	offset := skipID3v2(data)
+	if offset+2 <= len(data) && data[offset] == 0xFF {
+		if data[offset+1]&0xF6 == 0xF0 {
+			return probeADTS(data[offset:])
+		}
+		if data[offset+1]&0xE0 == 0xE0 {
+			return probeMPEG(data[offset:])
+		}
+	}
+
+	return Info{}, ErrUnsupported
+}
+
+func durationMs(samples int64, sampleRate int) int {
+	return int(samples * 1000 / int64(sampleRate))
+}
+
+// skipID3v2 returns the offset of the audio after an ID3v2 tag, or 0 without one
+func skipID3v2(data []byte) int {
+	if len(data) < 10 || string(data[0:3]) != "ID3" {
+		return 0
+	}
+	// The tag size is a 28-bit synchsafe integer
+	size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+	offset := 10 + size
+	if data[5]&0x10 != 0 {
+		offset += 10 // footer
+	}
+	if offset > len(data) {
+		return len(data)
+	}
+	return offset
+}
+
+var (
+	mpegBitrates = map[[2]int][15]int{
+		{1, 1}: {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{1, 2}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{1, 3}: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{2, 1}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{2, 2}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{2, 3}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	mpegSampleRates = map[int][3]int{
+		1:  {44100, 48000, 32000},
+		2:  {22050, 24000, 16000},
+		25: {11025, 12000, 8000},
+	}
+)
+
+type mpegFrame struct {
+	length     int
+	samples    int
+	sampleRate int
+}
+
+// parseMPEGFrame reads an MPEG audio frame header
+func parseMPEGFrame(h []byte) (mpegFrame, bool) {
+	if len(h) < 4 || h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return mpegFrame{}, false
+	}
+
+	var version int
+	switch (h[1] >> 3) & 3 {
+	case 0:
+		version = 25
+	case 2:
+		version = 2
+	case 3:
+		version = 1
+	default:
+		return mpegFrame{}, false
+	}
+
+	layer := 4 - int((h[1]>>1)&3)
+	if layer == 4 {
+		return mpegFrame{}, false
+	}
+
+	bitrateIndex := int(h[2] >> 4)
+	sampleRateIndex := int((h[2] >> 2) & 3)
+	if bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return mpegFrame{}, false
+	}
+	padding := int((h[2] >> 1) & 1)
+
+	tableVersion := version
+	if tableVersion == 25 {
+		tableVersion = 2
+	}
+	bitrate := mpegBitrates[[2]int{tableVersion, layer}][bitrateIndex] * 1000
+	sampleRate := mpegSampleRates[version][sampleRateIndex]
+
+	frame := mpegFrame{sampleRate: sampleRate}
+	switch {
+	case layer == 1:
+		frame.samples = 384
+		frame.length = (12*bitrate/sampleRate + padding) * 4
+	case layer == 3 && version != 1:
+		frame.samples = 576
+		frame.length = 72*bitrate/sampleRate + padding
+	default:
+		frame.samples = 1152
+		frame.length = 144*bitrate/sampleRate + padding
+	}
+
+	return frame, frame.length > 4
+}
+
+// probeMPEG walks every frame, which also gets variable bitrate files right
+func probeMPEG(data []byte) (Info, error) {
+	var samplesMs float64
+	frames := 0
+	for offset := 0; offset+4 <= len(data); {
+		frame, ok := parseMPEGFrame(data[offset:])
+		if !ok || offset+frame.length > len(data) {
+			break
+		}
+		samplesMs += float64(frame.samples) * 1000 / float64(frame.sampleRate)
+		frames++
+		offset += frame.length
+	}
+
+	// A lone frame header is more likely noise than an MP3
+	if frames < 2 {
+		return Info{}, fmt.Errorf("%w: no MPEG audio frames", ErrUnsupported)
+	}
+
+	return Info{MimeType: "audio/mpeg", DurationMs: int(samplesMs)}, nil
+}
+
+var adtsSampleRates = []int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// probeADTS walks the frames of a raw AAC stream; each raw data block holds 1024 samples
+func probeADTS(data []byte) (Info, error) {
+	var samples int64
+	sampleRate := 0
+	frames := 0
+	for offset := 0; offset+7 <= len(data); {
+		h := data[offset:]
+		if h[0] != 0xFF || h[1]&0xF6 != 0xF0 {
+			break
+		}
+		rateIndex := int((h[2] >> 2) & 0xF)
+		if rateIndex >= len(adtsSampleRates) {
+			break
+		}
+		length := int(h[3]&3)<<11 | int(h[4])<<3 | int(h[5]>>5)
+		if length < 7 || offset+length > len(data) {
+			break
+		}
+		sampleRate = adtsSampleRates[rateIndex]
+		samples += int64(h[6]&3+1) * 1024
+		frames++
+		offset += length
+	}
+
+	if frames < 2 {
+		return Info{}, fmt.Errorf("%w: no AAC frames", ErrUnsupported)
+	}
+
+	return Info{MimeType: "audio/aac", DurationMs: durationMs(samples, sampleRate)}, nil
+}
+
+func probeWAV(data []byte) (Info, error) {
+	byteRate := 0
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := data[offset+8:]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 12 {
+				return Info{}, fmt.Errorf("%w: short WAV fmt chunk", ErrUnsupported)
+			}
+			byteRate = int(binary.LittleEndian.Uint32(body[8:12]))
+		case "data":
+			if byteRate == 0 {
+				return Info{}, fmt.Errorf("%w: WAV data before fmt chunk", ErrUnsupported)
+			}
+			// Streamed WAVs may declare more data than the file holds
+			if size > len(body) {
+				size = len(body)
+			}
+			return Info{MimeType: "audio/wav", DurationMs: durationMs(int64(size), byteRate)}, nil
+		}
+
+		// Chunks are padded to an even size
+		offset += 8 + size + size%2
+	}
+
+	return Info{}, fmt.Errorf("%w: WAV without data chunk", ErrUnsupported)
+}
+
+// probeOgg reads the sample rate from the first packet and the length from the
+// granule position of the last page
+func probeOgg(data []byte) (Info, error) {
+	if len(data) < 27 {
+		return Info{}, fmt.Errorf("%w: short Ogg page", ErrUnsupported)
+	}
+	segments := int(data[26])
+	if len(data) < 27+segments {
+		return Info{}, fmt.Errorf("%w: short Ogg page", ErrUnsupported)
+	}
+	serial := binary.LittleEndian.Uint32(data[14:18])
+	packet := data[27+segments:]
+
+	var sampleRate int
+	var preSkip int64
+	switch {
+	case len(packet) >= 16 && string(packet[0:7]) == "\x01vorbis":
+		sampleRate = int(binary.LittleEndian.Uint32(packet[12:16]))
+	case len(packet) >= 12 && string(packet[0:8]) == "OpusHead":
+		// Opus granule positions always count 48 kHz samples
+		sampleRate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return Info{}, fmt.Errorf("%w: Ogg stream is neither Vorbis nor Opus", ErrUnsupported)
+	}
+	if sampleRate == 0 {
+		return Info{}, fmt.Errorf("%w: Ogg stream without sample rate", ErrUnsupported)
+	}
+
+	for offset := bytes.LastIndex(data, []byte("OggS")); offset >= 0; offset = bytes.LastIndex(data[:offset], []byte("OggS")) {
+		page := data[offset:]
+		if len(page) < 27 || binary.LittleEndian.Uint32(page[14:18]) != serial {
+			continue
+		}
+		granule := int64(binary.LittleEndian.Uint64(page[6:14]))
+		// -1 marks a page on which no packet ends
+		if granule < 0 {
+			continue
+		}
+		samples := granule - preSkip
+		if samples < 0 {
+			samples = 0
+		}
+		return Info{MimeType: "audio/ogg", DurationMs: durationMs(samples, sampleRate)}, nil
+	}
+
+	return Info{}, fmt.Errorf("%w: Ogg stream without granule position", ErrUnsupported)
+}
+
+// mp4Box finds the first box of the given type among the boxes in data
+func mp4Box(data []byte, boxType string) ([]byte, bool) {
+	for offset := 0; offset+8 <= len(data); {
+		size := int64(binary.BigEndian.Uint32(data[offset : offset+4]))
+		header := int64(8)
+		switch size {
+		case 0:
+			size = int64(len(data) - offset)
+		case 1:
+			if offset+16 > len(data) {
+				return nil, false
+			}
+			size = int64(binary.BigEndian.Uint64(data[offset+8 : offset+16]))
+			header = 16
+		}
+		if size < header || int64(offset)+size > int64(len(data)) {
+			return nil, false
+		}
+
+		if string(data[offset+4:offset+8]) == boxType {
+			return data[int64(offset)+header : int64(offset)+size], true
+		}
+		offset += int(size)
+	}
+	return nil, false
+}
+
+// probeMP4 reads the duration from the movie header (moov/mvhd)
+func probeMP4(data []byte) (Info, error) {
+	moov, ok := mp4Box(data, "moov")
+	if !ok {
+		return Info{}, fmt.Errorf("%w: MP4 without moov box", ErrUnsupported)
+	}
+	mvhd, ok := mp4Box(moov, "mvhd")
+	if !ok || len(mvhd) < 20 {
+		return Info{}, fmt.Errorf("%w: MP4 without mvhd box", ErrUnsupported)
+	}
+
+	var timescale, duration int64
+	if mvhd[0] == 1 {
+		if len(mvhd) < 32 {
+			return Info{}, fmt.Errorf("%w: short mvhd box", ErrUnsupported)
+		}
+		timescale = int64(binary.BigEndian.Uint32(mvhd[20:24]))
+		duration = int64(binary.BigEndian.Uint64(mvhd[24:32]))
+	} else {
+		timescale = int64(binary.BigEndian.Uint32(mvhd[12:16]))
+		duration = int64(binary.BigEndian.Uint32(mvhd[16:20]))
+	}
+	if timescale == 0 {
+		return Info{}, fmt.Errorf("%w: mvhd without timescale", ErrUnsupported)
+	}
+
+	return Info{MimeType: "audio/mp4", DurationMs: int(duration * 1000 / timescale)}, nil
+}
@@ -0,0 +1,154 @@
+package audioprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// mp3Frames builds frames of MPEG-1 Layer III at 128 kbps and 44.1 kHz, which
+// are 417 bytes and 1152 samples each
+func mp3Frames(n int) []byte {
+	var b bytes.Buffer
+	for i := 0; i < n; i++ {
+		frame := make([]byte, 417)
+		copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+		b.Write(frame)
+	}
+	return b.Bytes()
+}
+
+// adtsFrames builds AAC frames of 200 bytes at 44.1 kHz, each one raw data block
+func adtsFrames(n int) []byte {
+	var b bytes.Buffer
+	for i := 0; i < n; i++ {
+		frame := make([]byte, 200)
+		length := len(frame)
+		copy(frame, []byte{0xFF, 0xF1, 0x50, 0x80 | byte(length>>11), byte(length >> 3), byte(length<<5) | 0x1F, 0xFC})
+		b.Write(frame)
+	}
+	return b.Bytes()
+}
+
+func wav(byteRate, dataSize int) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+dataSize))
+	b.WriteString("WAVE")
+	b.WriteString("fmt ")
+	binary.Write(&b, binary.LittleEndian, uint32(16))
+	binary.Write(&b, binary.LittleEndian, uint16(1))          // PCM
+	binary.Write(&b, binary.LittleEndian, uint16(1))          // mono
+	binary.Write(&b, binary.LittleEndian, uint32(byteRate/2)) // sample rate
+	binary.Write(&b, binary.LittleEndian, uint32(byteRate))   // byte rate
+	binary.Write(&b, binary.LittleEndian, uint16(2))          // block align
+	binary.Write(&b, binary.LittleEndian, uint16(16))         // bits per sample
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(dataSize))
+	b.Write(make([]byte, dataSize))
+	return b.Bytes()
+}
+
+func oggPage(serial uint32, granule int64, packet []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("OggS")
+	b.WriteByte(0)
+	b.WriteByte(0)
+	binary.Write(&b, binary.LittleEndian, granule)
+	binary.Write(&b, binary.LittleEndian, serial)
+	binary.Write(&b, binary.LittleEndian, uint32(0)) // sequence
+	binary.Write(&b, binary.LittleEndian, uint32(0)) // checksum, not verified
+	b.WriteByte(1)
+	b.WriteByte(byte(len(packet)))
+	b.Write(packet)
+	return b.Bytes()
+}
+
+func vorbisIdentification(sampleRate uint32) []byte {
+	packet := make([]byte, 30)
+	copy(packet, "\x01vorbis")
+	binary.LittleEndian.PutUint32(packet[12:16], sampleRate)
+	return packet
+}
+
+func opusHead(preSkip uint16) []byte {
+	packet := make([]byte, 19)
+	copy(packet, "OpusHead")
+	packet[8] = 1
+	packet[9] = 2
+	binary.LittleEndian.PutUint16(packet[10:12], preSkip)
+	binary.LittleEndian.PutUint32(packet[12:16], 44100) // input rate, informational only
+	return packet
+}
+
+func box(boxType string, body []byte) []byte {
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	copy(b[4:], boxType)
+	return append(b, body...)
+}
+
+func mvhd(timescale, duration uint32) []byte {
+	body := make([]byte, 100)
+	binary.BigEndian.PutUint32(body[12:16], timescale)
+	binary.BigEndian.PutUint32(body[16:20], duration)
+	return box("mvhd", body)
+}
+
+func TestProbe(t *testing.T) {
+	id3 := append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20}, make([]byte, 20)...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+		duration int
+	}{
+		{"mp3", mp3Frames(100), "audio/mpeg", 2612},
+		{"mp3 after ID3 tag", append(append([]byte{}, id3...), mp3Frames(100)...), "audio/mpeg", 2612},
+		{"mp3 with ID3v1 trailer", append(mp3Frames(10), append([]byte("TAG"), make([]byte, 125)...)...), "audio/mpeg", 261},
+		{"aac", adtsFrames(431), "audio/aac", 10007},
+		{"wav", wav(32000, 64000), "audio/wav", 2000},
+		{"wav with truncated data", wav(32000, 64000)[:44+16000], "audio/wav", 500},
+		{"ogg vorbis", append(oggPage(7, 0, vorbisIdentification(44100)), oggPage(7, 441000, []byte{0})...), "audio/ogg", 10000},
+		{"ogg opus", append(oggPage(7, 0, opusHead(312)), oggPage(7, 96312, []byte{0})...), "audio/ogg", 2000},
+		{"m4a", append(box("ftyp", []byte("M4A \x00\x00\x00\x00")), box("moov", mvhd(1000, 3500))...), "audio/mp4", 3500},
+		{"m4a with moov at the end", append(append(box("ftyp", []byte("M4A ")), box("mdat", make([]byte, 64))...), box("moov", mvhd(44100, 88200))...), "audio/mp4", 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Probe(tt.data)
+			if err != nil {
+				t.Fatalf("Probe() error = %v", err)
+			}
+			if info.MimeType != tt.mimeType || info.DurationMs != tt.duration {
+				t.Fatalf("Probe() = %+v, want %s %dms", info, tt.mimeType, tt.duration)
+			}
+		})
+	}
+}
+
+func TestProbeUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"text", []byte("this is not audio at all")},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")},
+		{"single mp3 frame", mp3Frames(1)},
+		{"wav without data", wav(32000, 0)[:36]},
+		{"ogg flac", oggPage(7, 0, []byte("\x7fFLAC\x01\x00"))},
+		{"mp4 without moov", box("ftyp", []byte("isom"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Probe(tt.data); !errors.Is(err, ErrUnsupported) {
+				t.Fatalf("Probe() error = %v, want ErrUnsupported", err)
+			}
+		})
+	}
+}
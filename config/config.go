@@ -10,8 +10,85 @@ type DBConfiguration struct {
 	DB_NAME     string
 }
 
+type MediaConfiguration struct {
+	STORAGE_DRIVER string
+	MEDIA_DIR      string
+	MEDIA_BASE_URL string
+	S3_ENDPOINT    string
+	S3_REGION      string
+	S3_BUCKET      string
+	S3_ACCESS_KEY  string
+	S3_SECRET_KEY  string
+	S3_PUBLIC_URL  string
+}
+
+type ModerationConfiguration struct {
+	COMMENT_TRUST_THRESHOLD int
+	BANNED_WORDS_ID         []string
+	BANNED_WORDS_EN         []string
+}
+
+type NotificationConfiguration struct {
+	PUSH_DRIVER          string
+	FCM_PROJECT_ID       string
+	FCM_CREDENTIALS_FILE string
+}
+
+type WebhookConfiguration struct {
+	WEBHOOK_MAX_ATTEMPTS    int
+	WEBHOOK_TIMEOUT_SECONDS int
+}
+
+type FeedConfiguration struct {
+	SITE_URL string
+}
+
 func GetDBConfig() DBConfiguration {
 	conf := DBConfiguration{}
 	gonfig.GetConf("config/config.json", &conf)
 	return conf
 }
+
+func GetMediaConfig() MediaConfiguration {
+	conf := MediaConfiguration{
+		STORAGE_DRIVER: "local",
+		MEDIA_DIR:      "media",
+		MEDIA_BASE_URL: "/media",
+		S3_REGION:      "us-east-1",
+	}
+	gonfig.GetConf("config/config.json", &conf)
+	return conf
+}
+
+func GetModerationConfig() ModerationConfiguration {
+	conf := ModerationConfiguration{
+		COMMENT_TRUST_THRESHOLD: 3,
+	}
+	gonfig.GetConf("config/config.json", &conf)
+	return conf
+}
+
+func GetNotificationConfig() NotificationConfiguration {
+	conf := NotificationConfiguration{
+		PUSH_DRIVER: "log",
+	}
+	gonfig.GetConf("config/config.json", &conf)
+	return conf
+}
+
+func GetWebhookConfig() WebhookConfiguration {
+	conf := WebhookConfiguration{
+		WEBHOOK_MAX_ATTEMPTS:    8,
+		WEBHOOK_TIMEOUT_SECONDS: 10,
+	}
+	gonfig.GetConf("config/config.json", &conf)
+	return conf
+}
+
+func GetFeedConfig() FeedConfiguration {
+	conf := FeedConfiguration{
+		SITE_URL: "http://localhost:3000",
+	}
+	gonfig.GetConf("config/config.json", &conf)
+	return conf
+}
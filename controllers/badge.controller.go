@@ -0,0 +1,120 @@
+// Badge Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetAllBadges(c echo.Context) error {
+	badges, err := models.GetAllBadges()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, badges)
+}
+
+func GetBadgeDetail(c echo.Context) error {
+	badgeID, err := strconv.Atoi(c.Param("badge_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid badge_id"})
+	}
+
+	badge, err := models.GetBadgeDetail(badgeID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Badge not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, badge)
+}
+
+func CreateBadge(c echo.Context) error {
+	var badge models.Badge
+
+	if err := c.Bind(&badge); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	id, err := models.CreateBadge(badge)
+	if errors.Is(err, models.ErrInvalidBadge) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if errors.Is(err, models.ErrDuplicateBadge) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"badge_id": id})
+}
+
+// UpdateBadge replaces a badge identified by badge_id in the body
+func UpdateBadge(c echo.Context) error {
+	var badge models.Badge
+
+	if err := c.Bind(&badge); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if badge.BadgeID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid badge_id"})
+	}
+
+	rowsAffected, err := models.UpdateBadge(badge)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Badge not found"})
+	}
+	if errors.Is(err, models.ErrInvalidBadge) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if errors.Is(err, models.ErrDuplicateBadge) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteBadge(c echo.Context) error {
+	badgeID, err := strconv.Atoi(c.Param("badge_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid badge_id"})
+	}
+
+	rowsAffected, err := models.DeleteBadge(badgeID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+// GetUserBadges lists the earned and locked badges of a user with their progress
+func GetUserBadges(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	result, err := models.GetUserBadges(userID)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,186 @@
+// Character Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllCharacters lists characters, searching by name with the keyword query param
+func GetAllCharacters(c echo.Context) error {
+	characters, err := models.GetAllCharacters(c.QueryParam("keyword"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, characters)
+}
+
+func GetCharacterDetail(c echo.Context) error {
+	characterID, err := strconv.Atoi(c.Param("character_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid character_id"})
+	}
+
+	character, err := models.GetCharacterDetail(characterID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Character not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, character)
+}
+
+func CreateCharacter(c echo.Context) error {
+	var character models.Character
+
+	if err := c.Bind(&character); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	id, err := models.CreateCharacter(character)
+	if errors.Is(err, models.ErrInvalidCharacter) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"character_id": id})
+}
+
+// UpdateCharacter replaces a character identified by character_id in the body
+func UpdateCharacter(c echo.Context) error {
+	var character models.Character
+
+	if err := c.Bind(&character); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if character.CharacterID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid character_id"})
+	}
+
+	rowsAffected, err := models.UpdateCharacter(character)
+	if errors.Is(err, models.ErrInvalidCharacter) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteCharacter(c echo.Context) error {
+	characterID, err := strconv.Atoi(c.Param("character_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid character_id"})
+	}
+
+	rowsAffected, err := models.DeleteCharacter(characterID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+// UploadCharacterImage stores a picture sent as the multipart field file and
+// sets it as the character's image
+func UploadCharacterImage(c echo.Context) error {
+	characterID, err := strconv.Atoi(c.Param("character_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid character_id"})
+	}
+
+	file, err := openImageUpload(c)
+	if file == nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := models.SaveCharacterImage(characterID, file)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Character not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			mediaErrorStatus(err),
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetCharacterStories lists the published stories a character appears in
+func GetCharacterStories(c echo.Context) error {
+	characterID, err := strconv.Atoi(c.Param("character_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid character_id"})
+	}
+
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	result, err := models.GetCharacterStories(characterID, page, pageSize, requestLocale(c))
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Character not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// SetStoryCharacters replaces the characters appearing in a story
+func SetStoryCharacters(c echo.Context) error {
+	// Parse the request body to populate the story characters struct
+	var storyCharacters struct {
+		StoryID      int   `json:"story_id"`
+		CharacterIDs []int `json:"character_ids"`
+	}
+	if err := c.Bind(&storyCharacters); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if storyCharacters.StoryID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	result, err := models.SetStoryCharacters(storyCharacters.StoryID, storyCharacters.CharacterIDs)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if errors.Is(err, models.ErrInvalidCharacter) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
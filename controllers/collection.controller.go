@@ -0,0 +1,208 @@
+// Collection Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllCollections lists the collections inside their visibility window
+func GetAllCollections(c echo.Context) error {
+	return listCollections(c, false)
+}
+
+// GetAllCollectionsAdmin lists every collection, including hidden ones
+func GetAllCollectionsAdmin(c echo.Context) error {
+	return listCollections(c, true)
+}
+
+func listCollections(c echo.Context, includeHidden bool) error {
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	result, err := models.GetAllCollections(page, pageSize, includeHidden)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetCollectionDetail returns a visible collection with a page of its stories
+func GetCollectionDetail(c echo.Context) error {
+	return collectionDetail(c, false)
+}
+
+// GetCollectionDetailAdmin returns any collection, hidden or not, with a page of its stories
+func GetCollectionDetailAdmin(c echo.Context) error {
+	return collectionDetail(c, true)
+}
+
+func collectionDetail(c echo.Context, includeHidden bool) error {
+	collectionID, err := strconv.Atoi(c.Param("collection_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid collection_id"})
+	}
+
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	result, err := models.GetCollectionDetail(collectionID, page, pageSize, includeHidden, requestLocale(c))
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Collection not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func CreateCollection(c echo.Context) error {
+	var collection models.Collection
+
+	if err := c.Bind(&collection); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	id, err := models.CreateCollection(collection)
+	if errors.Is(err, models.ErrInvalidCollection) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"collection_id": id})
+}
+
+// UpdateCollection replaces a collection identified by collection_id in the body
+func UpdateCollection(c echo.Context) error {
+	var collection models.Collection
+
+	if err := c.Bind(&collection); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if collection.CollectionID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid collection_id"})
+	}
+
+	rowsAffected, err := models.UpdateCollection(collection)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Collection not found"})
+	}
+	if errors.Is(err, models.ErrInvalidCollection) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteCollection(c echo.Context) error {
+	collectionID, err := strconv.Atoi(c.Param("collection_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid collection_id"})
+	}
+
+	rowsAffected, err := models.DeleteCollection(collectionID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Collection not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+// UploadCollectionCover stores a picture sent as the multipart field file and
+// sets it as the collection's cover
+func UploadCollectionCover(c echo.Context) error {
+	collectionID, err := strconv.Atoi(c.Param("collection_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid collection_id"})
+	}
+
+	file, err := openImageUpload(c)
+	if file == nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := models.SaveCollectionCover(collectionID, file)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Collection not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			mediaErrorStatus(err),
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// SetCollectionStories replaces the stories of a collection, in the given order
+func SetCollectionStories(c echo.Context) error {
+	// Parse the request body to populate the collection stories struct
+	var collectionStories struct {
+		CollectionID int   `json:"collection_id"`
+		StoryIDs     []int `json:"story_ids"`
+	}
+	if err := c.Bind(&collectionStories); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if collectionStories.CollectionID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid collection_id"})
+	}
+
+	result, err := models.SetCollectionStories(collectionStories.CollectionID, collectionStories.StoryIDs)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Collection not found"})
+	}
+	if errors.Is(err, models.ErrInvalidCollection) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
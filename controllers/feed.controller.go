@@ -0,0 +1,168 @@
+// Feed Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"kisahloka_be/feed"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	feedFormatAtom = "atom"
+	feedFormatRSS  = "rss"
+)
+
+// GetStoriesFeed serves the latest published stories as Atom or RSS, depending
+// on the extension of the requested path
+func GetStoriesFeed(c echo.Context) error {
+	return serveStoryFeed(c, models.FeedScope{})
+}
+
+// GetGenreStoriesFeed serves the latest published stories of a genre
+func GetGenreStoriesFeed(c echo.Context) error {
+	genreID, err := strconv.Atoi(c.Param("genre_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid genre_id"})
+	}
+
+	return serveStoryFeed(c, models.FeedScope{GenreID: genreID})
+}
+
+// GetOriginStoriesFeed serves the latest published stories of an origin and the origins below it
+func GetOriginStoriesFeed(c echo.Context) error {
+	originID, err := strconv.Atoi(c.Param("origin_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid origin_id"})
+	}
+
+	return serveStoryFeed(c, models.FeedScope{OriginID: originID})
+}
+
+func serveStoryFeed(c echo.Context, scope models.FeedScope) error {
+	format := feedFormatAtom
+	if strings.HasSuffix(c.Path(), ".rss") {
+		format = feedFormatRSS
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	locale := requestLocale(c)
+
+	scopeName, err := models.GetFeedScopeName(scope, locale)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Feed not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+	}
+
+	stories, err := models.GetFeedStories(scope, limit, locale)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+	}
+
+	// Relative media URLs are served by this API, so they are resolved against the request
+	baseURL := c.Scheme() + "://" + c.Request().Host
+
+	storyFeed := feed.Feed{
+		Title:    "Kisahloka",
+		Subtitle: "Cerita rakyat Nusantara terbaru",
+		Language: locale,
+		SelfURL:  baseURL + c.Request().URL.RequestURI(),
+	}
+	if locale == models.LocaleEnglish {
+		storyFeed.Subtitle = "The latest folk tales of the archipelago"
+	}
+	if storyFeed.Language == "" {
+		storyFeed.Language = models.LocaleIndonesian
+	}
+	if scopeName != "" {
+		storyFeed.Title += " - " + scopeName
+	}
+
+	for _, story := range stories {
+		imageURL := story.ThumbnailImage
+		if strings.HasPrefix(imageURL, "/") {
+			imageURL = baseURL + imageURL
+		}
+
+		storyFeed.Entries = append(storyFeed.Entries, feed.Entry{
+			ID:         story.StoryID,
+			Title:      story.Title,
+			Summary:    story.Synopsis,
+			ImageURL:   imageURL,
+			Categories: []string{story.TypeName, story.OriginName},
+			Published:  story.ReleasedDate,
+			Updated:    story.UpdatedAt,
+		})
+	}
+
+	etag := storyFeed.ETag(format)
+	lastModified := storyFeed.Updated()
+
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "public, max-age=300")
+	// Titles and synopses follow the Accept-Language header unless lang is given
+	header.Set("Vary", "Accept-Language")
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if feedNotModified(c.Request(), etag, lastModified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	var body []byte
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == feedFormatRSS {
+		body, err = feed.RSS(storyFeed)
+		contentType = "application/rss+xml; charset=utf-8"
+	} else {
+		body, err = feed.Atom(storyFeed)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+	}
+
+	return c.Blob(http.StatusOK, contentType, body)
+}
+
+// feedNotModified checks the conditional GET headers. If-None-Match takes
+// precedence over If-Modified-Since, as in RFC 9110.
+func feedNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// Last-Modified is sent with second precision
+	return !lastModified.Truncate(time.Second).After(since)
+}
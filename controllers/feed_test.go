@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFeedNotModified(t *testing.T) {
+	etag := `W/"abc"`
+	lastModified := time.Date(2026, 3, 1, 8, 0, 0, 500, time.UTC)
+
+	tests := []struct {
+		name            string
+		ifNoneMatch     string
+		ifModifiedSince string
+		lastModified    time.Time
+		want            bool
+	}{
+		{"no conditions", "", "", lastModified, false},
+		{"matching etag", `W/"abc"`, "", lastModified, true},
+		{"strong form of etag", `"abc"`, "", lastModified, true},
+		{"etag in list", `"xyz", W/"abc"`, "", lastModified, true},
+		{"wildcard", "*", "", lastModified, true},
+		{"other etag", `"xyz"`, "", lastModified, false},
+		{"etag wins over date", `"xyz"`, "Sun, 01 Mar 2026 09:00:00 GMT", lastModified, false},
+		{"same second", "", "Sun, 01 Mar 2026 08:00:00 GMT", lastModified, true},
+		{"later date", "", "Sun, 01 Mar 2026 09:00:00 GMT", lastModified, true},
+		{"earlier date", "", "Sun, 01 Mar 2026 07:59:59 GMT", lastModified, false},
+		{"invalid date", "", "yesterday", lastModified, false},
+		{"empty feed", "", "Sun, 01 Mar 2026 09:00:00 GMT", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feed/stories.atom", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			if tt.ifModifiedSince != "" {
+				req.Header.Set("If-Modified-Since", tt.ifModifiedSince)
+			}
+
+			if got := feedNotModified(req, etag, tt.lastModified); got != tt.want {
+				t.Errorf("feedNotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
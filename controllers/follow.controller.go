@@ -0,0 +1,77 @@
+// Follow Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetUserFollows lists the genres and origins a user follows
+func GetUserFollows(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	follows, err := models.GetUserFollows(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, follows)
+}
+
+// CreateFollow makes a user follow a genre or origin; target_type is genre or origin
+func CreateFollow(c echo.Context) error {
+	var follow models.Follow
+
+	if err := c.Bind(&follow); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if follow.UserID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	if !models.IsValidFollowTarget(follow.TargetType) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid target_type"})
+	}
+
+	id, err := models.CreateFollow(follow.UserID, follow.TargetType, follow.TargetID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Follow target not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"follow_id": id})
+}
+
+// DeleteFollow unfollows on behalf of the user given as the user_id query param
+func DeleteFollow(c echo.Context) error {
+	followID, err := strconv.Atoi(c.Param("follow_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid follow_id"})
+	}
+
+	userID, err := strconv.Atoi(c.QueryParam("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	rowsAffected, err := models.DeleteFollow(followID, userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Follow not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
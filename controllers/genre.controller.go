@@ -9,7 +9,7 @@ import (
 )
 
 func GetAllGenres(c echo.Context) error {
-	genres, err := models.GetAllGenres()
+	genres, err := models.GetAllGenres(requestLocale(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -0,0 +1,191 @@
+// Glossary Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllGlossary lists glossary terms, filtered by the optional keyword and locale query params
+func GetAllGlossary(c echo.Context) error {
+	glossaries, err := models.GetAllGlossary(c.QueryParam("keyword"), c.QueryParam("locale"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, glossaries)
+}
+
+func GetGlossaryDetail(c echo.Context) error {
+	glossaryID, err := strconv.Atoi(c.Param("glossary_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid glossary_id"})
+	}
+
+	glossary, err := models.GetGlossaryDetail(glossaryID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Glossary not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, glossary)
+}
+
+func CreateGlossary(c echo.Context) error {
+	var glossary models.Glossary
+
+	if err := c.Bind(&glossary); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	id, err := models.CreateGlossary(glossary)
+	if errors.Is(err, models.ErrInvalidGlossary) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"glossary_id": id})
+}
+
+// UpdateGlossary replaces a glossary term identified by glossary_id in the body
+func UpdateGlossary(c echo.Context) error {
+	var glossary models.Glossary
+
+	if err := c.Bind(&glossary); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if glossary.GlossaryID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid glossary_id"})
+	}
+
+	rowsAffected, err := models.UpdateGlossary(glossary)
+	if errors.Is(err, models.ErrInvalidGlossary) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteGlossary(c echo.Context) error {
+	glossaryID, err := strconv.Atoi(c.Param("glossary_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid glossary_id"})
+	}
+
+	rowsAffected, err := models.DeleteGlossary(glossaryID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+// GetStoryGlossary lists the glossary terms used in a story, optionally for one locale
+func GetStoryGlossary(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	locale := c.QueryParam("locale")
+	if locale != "" && !models.IsSupportedLocale(locale) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported locale"})
+	}
+
+	result, err := models.GetStoryGlossary(storyID, locale)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// CreateStoryContentAnnotation links a span of a page's text to a glossary term
+func CreateStoryContentAnnotation(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	order, err := strconv.Atoi(c.Param("order"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid order"})
+	}
+
+	// Parse the request body to populate the annotation struct
+	var annotation struct {
+		Locale     string `json:"locale"`
+		GlossaryID int    `json:"glossary_id"`
+		CharStart  int    `json:"char_start"`
+		CharEnd    int    `json:"char_end"`
+	}
+	if err := c.Bind(&annotation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if !models.IsSupportedLocale(annotation.Locale) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported locale"})
+	}
+
+	result, err := models.SaveStoryContentAnnotation(models.StoryContentAnnotation{
+		StoryID:    storyID,
+		Order:      order,
+		Locale:     annotation.Locale,
+		GlossaryID: annotation.GlossaryID,
+		CharStart:  annotation.CharStart,
+		CharEnd:    annotation.CharEnd,
+	})
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Page has no text in this locale"})
+	}
+	if errors.Is(err, models.ErrInvalidAnnotation) {
+		return c.JSON(
+			http.StatusUnprocessableEntity,
+			map[string]string{"message": err.Error()},
+		)
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func DeleteStoryContentAnnotation(c echo.Context) error {
+	annotationID, err := strconv.Atoi(c.Param("annotation_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid annotation_id"})
+	}
+
+	result, err := models.DeleteStoryContentAnnotation(annotationID)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
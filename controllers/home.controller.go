@@ -3,12 +3,30 @@ package controllers
 import (
 	"kisahloka_be/models"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
 
+// GetHomeData returns the home page. Passing user_id or uid personalizes it with
+// sections such as continue reading and because you bookmarked; without them
+// the editorial home is returned.
 func GetHomeData(c echo.Context) error {
-	genres, err := models.GetHomeData()
+	var reader models.HomeReader
+
+	if userIDParam := c.QueryParam("user_id"); userIDParam != "" {
+		userID, err := strconv.Atoi(userIDParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+		}
+		reader.UserID = &userID
+	}
+
+	if uid := c.QueryParam("uid"); uid != "" {
+		reader.UID = &uid
+	}
+
+	genres, err := models.GetHomeData(reader, requestLocale(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
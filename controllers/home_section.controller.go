@@ -0,0 +1,130 @@
+// Home Section Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllHomeSections lists every home section in page order, including inactive ones
+func GetAllHomeSections(c echo.Context) error {
+	sections, err := models.GetAllHomeSections()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, sections)
+}
+
+func GetHomeSectionDetail(c echo.Context) error {
+	sectionID, err := strconv.Atoi(c.Param("section_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid section_id"})
+	}
+
+	section, err := models.GetHomeSectionDetail(sectionID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Home section not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, section)
+}
+
+func CreateHomeSection(c echo.Context) error {
+	var section models.HomeSection
+
+	if err := c.Bind(&section); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	id, err := models.CreateHomeSection(section)
+	if errors.Is(err, models.ErrInvalidHomeSection) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"section_id": id})
+}
+
+// UpdateHomeSection replaces a section identified by section_id in the body
+func UpdateHomeSection(c echo.Context) error {
+	var section models.HomeSection
+
+	if err := c.Bind(&section); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if section.SectionID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid section_id"})
+	}
+
+	rowsAffected, err := models.UpdateHomeSection(section)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Home section not found"})
+	}
+	if errors.Is(err, models.ErrInvalidHomeSection) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteHomeSection(c echo.Context) error {
+	sectionID, err := strconv.Atoi(c.Param("section_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid section_id"})
+	}
+
+	rowsAffected, err := models.DeleteHomeSection(sectionID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Home section not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+// ReorderHomeSections puts the sections in the order of section_ids
+func ReorderHomeSections(c echo.Context) error {
+	var order struct {
+		SectionIDs []int `json:"section_ids"`
+	}
+
+	if err := c.Bind(&order); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// Each section can only take one position
+	seen := make(map[int]bool)
+	for _, sectionID := range order.SectionIDs {
+		if seen[sectionID] {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Duplicate section_id " + strconv.Itoa(sectionID)})
+		}
+		seen[sectionID] = true
+	}
+
+	sections, err := models.ReorderHomeSections(order.SectionIDs)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Home section not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, sections)
+}
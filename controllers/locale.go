@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"kisahloka_be/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// requestLocale picks the locale a response should be written in. An explicit
+// lang query parameter wins over the Accept-Language header. It returns an empty
+// string when the client asked for the bilingual shape (mode=bilingual or
+// lang=bilingual) or did not ask for any language at all.
+func requestLocale(c echo.Context) string {
+	if c.QueryParam("mode") == "bilingual" {
+		return ""
+	}
+
+	lang := strings.ToLower(c.QueryParam("lang"))
+	if lang == "bilingual" {
+		return ""
+	}
+	if locale := matchLocale(lang); locale != "" {
+		return locale
+	}
+
+	header := c.Request().Header.Get("Accept-Language")
+	if header == "" {
+		if lang != "" {
+			return models.LocaleIndonesian
+		}
+		return ""
+	}
+
+	if locale := negotiateLocale(header); locale != "" {
+		return locale
+	}
+
+	// The client asked for a language we do not have; fall back to the original text
+	return models.LocaleIndonesian
+}
+
+// negotiateLocale returns the supported locale with the highest quality in an
+// Accept-Language header, or an empty string when none matches
+func negotiateLocale(header string) string {
+	type candidate struct {
+		locale  string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		if locale := matchLocale(strings.ToLower(strings.TrimSpace(fields[0]))); locale != "" {
+			candidates = append(candidates, candidate{locale: locale, quality: quality})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	return candidates[0].locale
+}
+
+// matchLocale maps a language tag such as "id-ID" or "en-US" to a supported locale
+func matchLocale(tag string) string {
+	if tag == "" {
+		return ""
+	}
+	if models.IsSupportedLocale(tag) {
+		return tag
+	}
+
+	primary := strings.SplitN(tag, "-", 2)[0]
+	// "in" is the deprecated ISO 639 code for Indonesian that older Android devices still send
+	if primary == "in" {
+		primary = models.LocaleIndonesian
+	}
+	if models.IsSupportedLocale(primary) {
+		return primary
+	}
+
+	return ""
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"en-US,en;q=0.9", "en"},
+		{"fr-FR, id;q=0.8, en;q=0.5", "id"},
+		{"en;q=0.4, id;q=0.6", "id"},
+		{"id;q=0, en", "en"},
+		{"in-ID", "id"},
+		{"jv", "jv"},
+		{"fr, de", ""},
+		{"en;q=abc", "en"},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateLocale(tt.header); got != tt.want {
+			t.Errorf("negotiateLocale(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"", ""},
+		{"id", "id"},
+		{"id-id", "id"},
+		{"en-gb", "en"},
+		{"in", "id"},
+		{"ban", "ban"},
+		{"zh-cn", ""},
+	}
+
+	for _, tt := range tests {
+		if got := matchLocale(tt.tag); got != tt.want {
+			t.Errorf("matchLocale(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestRequestLocale(t *testing.T) {
+	tests := []struct {
+		query  string
+		header string
+		want   string
+	}{
+		{"", "", ""},
+		{"?mode=bilingual", "en", ""},
+		{"?lang=bilingual", "en", ""},
+		{"?lang=en", "id", "en"},
+		{"", "en-US,en;q=0.9", "en"},
+		{"", "fr-FR", "id"},
+		{"?lang=fr", "", "id"},
+		{"?lang=fr", "en", "en"},
+	}
+
+	e := echo.New()
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/"+tt.query, nil)
+		if tt.header != "" {
+			req.Header.Set("Accept-Language", tt.header)
+		}
+		c := e.NewContext(req, httptest.NewRecorder())
+		if got := requestLocale(c); got != tt.want {
+			t.Errorf("requestLocale(%q, %q) = %q, want %q", tt.query, tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Media Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// openImageUpload opens the "file" field of a multipart upload, rejecting files
+// over the image size limit before reading them
+func openImageUpload(c echo.Context) (multipart.File, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing file"})
+	}
+
+	if fileHeader.Size > models.MaxImageFileSize {
+		return nil, c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "Image file is too large"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid file"})
+	}
+
+	return file, nil
+}
+
+// mediaErrorStatus maps unreadable images to 415, oversized ones to 413 and
+// anything else to 500
+func mediaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrUnsupportedImage):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, models.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusInternalServerError
+}
+
+// UploadStoryThumbnail stores a JPEG, PNG or WebP thumbnail sent as the multipart
+// field file and sets it as the story's thumbnail_image
+func UploadStoryThumbnail(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	file, err := openImageUpload(c)
+	if file == nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := models.SaveStoryThumbnail(storyID, file)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"message": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			mediaErrorStatus(err),
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// UploadStoryContentImage stores the illustration of one page sent as the
+// multipart field file and sets it as the page's image
+func UploadStoryContentImage(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	order, err := strconv.Atoi(c.Param("order"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid order"})
+	}
+
+	file, err := openImageUpload(c)
+	if file == nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := models.SaveStoryContentImage(storyID, order, file)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"message": "Page not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			mediaErrorStatus(err),
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
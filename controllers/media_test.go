@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"kisahloka_be/models"
+	"net/http"
+	"testing"
+)
+
+func TestMediaErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: type %q", models.ErrUnsupportedImage, "text/plain"), http.StatusUnsupportedMediaType},
+		{fmt.Errorf("%w: 9000x10 pixels", models.ErrImageTooLarge), http.StatusRequestEntityTooLarge},
+		{errors.New("unsupported image type, but from the storage"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := mediaErrorStatus(tt.err); got != tt.want {
+			t.Errorf("mediaErrorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
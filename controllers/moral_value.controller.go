@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetAllMoralValues(c echo.Context) error {
+	moralValues, err := models.GetAllMoralValues()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, moralValues)
+}
+
+func GetMoralValueDetail(c echo.Context) error {
+	moralValueID, err := strconv.Atoi(c.Param("moral_value_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid moral_value_id"})
+	}
+
+	moralValue, err := models.GetMoralValueDetail(moralValueID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Moral value not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, moralValue)
+}
+
+func CreateMoralValue(c echo.Context) error {
+	var moralValue struct {
+		ValueName string `json:"value_name"`
+	}
+
+	if err := c.Bind(&moralValue); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	id, err := models.CreateMoralValue(moralValue.ValueName)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"moral_value_id": id})
+}
+
+func UpdateMoralValue(c echo.Context) error {
+	var moralValue struct {
+		MoralValueID int    `json:"moral_value_id"`
+		ValueName    string `json:"value_name"`
+	}
+
+	if err := c.Bind(&moralValue); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if moralValue.MoralValueID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid moral_value_id"})
+	}
+
+	rowsAffected, err := models.UpdateMoralValue(moralValue.MoralValueID, moralValue.ValueName)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteMoralValue(c echo.Context) error {
+	moralValueID, err := strconv.Atoi(c.Param("moral_value_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid moral_value_id"})
+	}
+
+	rowsAffected, err := models.DeleteMoralValue(moralValueID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+// SetStoryMoralValues replaces the values a story teaches
+func SetStoryMoralValues(c echo.Context) error {
+	// Parse the request body to populate the story moral values struct
+	var storyMoralValues struct {
+		StoryID       int   `json:"story_id"`
+		MoralValueIDs []int `json:"moral_value_ids"`
+	}
+	if err := c.Bind(&storyMoralValues); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if storyMoralValues.StoryID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	result, err := models.SetStoryMoralValues(storyMoralValues.StoryID, storyMoralValues.MoralValueIDs)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if errors.Is(err, models.ErrUnknownMoralValue) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,111 @@
+// Notification Controller
+
+package controllers
+
+import (
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetNotifications lists a user's inbox; unread=true leaves out read notifications
+func GetNotifications(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	unreadOnly := c.QueryParam("unread") == "true"
+
+	result, err := models.GetNotifications(userID, page, pageSize, unreadOnly)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// MarkNotificationsRead marks the listed notifications as read, or all of the
+// user's notifications when notification_ids is empty
+func MarkNotificationsRead(c echo.Context) error {
+	var readData struct {
+		UserID          int   `json:"user_id"`
+		NotificationIDs []int `json:"notification_ids"`
+	}
+
+	if err := c.Bind(&readData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if readData.UserID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	result, err := models.MarkNotificationsRead(readData.UserID, readData.NotificationIDs)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// SavePushToken registers a device to receive push notifications for a user
+func SavePushToken(c echo.Context) error {
+	var tokenData struct {
+		UserID   int    `json:"user_id"`
+		Token    string `json:"token"`
+		Platform string `json:"platform"`
+	}
+
+	if err := c.Bind(&tokenData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if tokenData.UserID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	if tokenData.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid token"})
+	}
+
+	if err := models.SavePushToken(tokenData.UserID, tokenData.Token, tokenData.Platform); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"token": tokenData.Token})
+}
+
+// DeletePushToken stops push notifications to the device given as the token query param
+func DeletePushToken(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid token"})
+	}
+
+	rowsAffected, err := models.DeletePushToken(token)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
@@ -2,6 +2,8 @@
 package controllers
 
 import (
+	"database/sql"
+	"errors"
 	"kisahloka_be/models"
 	"net/http"
 	"strconv"
@@ -23,7 +25,7 @@ func GetAllOrigins(c echo.Context) error {
 
 	keyword := c.QueryParam("keyword")
 
-	result, err := models.GetAllOrigins(page, pageSize, keyword)
+	result, err := models.GetAllOrigins(page, pageSize, keyword, requestLocale(c))
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -65,7 +67,13 @@ func CreateOrigin(c echo.Context) error {
 	}
 
 	// Call the CreateOrigin function from the models package
-	result, err := models.CreateOrigin(originObj.OriginName)
+	result, err := models.CreateOrigin(originObj)
+	if errors.Is(err, models.ErrInvalidOrigin) {
+		return c.JSON(
+			http.StatusBadRequest,
+			map[string]string{"message": err.Error()},
+		)
+	}
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -103,6 +111,18 @@ func UpdateOrigin(c echo.Context) error {
 
 	// Call the UpdateOrigin function from the models package
 	result, err := models.UpdateOrigin(convID, updateFields)
+	if err == sql.ErrNoRows {
+		return c.JSON(
+			http.StatusNotFound,
+			map[string]string{"message": "Origin not found"},
+		)
+	}
+	if errors.Is(err, models.ErrInvalidOrigin) {
+		return c.JSON(
+			http.StatusBadRequest,
+			map[string]string{"message": err.Error()},
+		)
+	}
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -132,3 +152,30 @@ func DeleteOrigin(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, result)
 }
+
+// GetOriginMap returns origins with coordinates as a GeoJSON FeatureCollection
+func GetOriginMap(c echo.Context) error {
+	collection, err := models.GetOriginMap(requestLocale(c))
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "application/geo+json")
+	return c.JSON(http.StatusOK, collection)
+}
+
+// GetOriginTree returns all origins nested under their parents
+func GetOriginTree(c echo.Context) error {
+	tree, err := models.GetOriginTree(requestLocale(c))
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"origins": tree})
+}
@@ -0,0 +1,162 @@
+// Quiz Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetStoryQuiz returns the questions of a story without their answers
+func GetStoryQuiz(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	result, err := models.GetStoryQuiz(storyID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// SaveStoryQuiz replaces the questions of a story
+func SaveStoryQuiz(c echo.Context) error {
+	// Parse the request body to populate the quiz struct
+	var quiz struct {
+		StoryID   int                   `json:"story_id"`
+		Questions []models.QuizQuestion `json:"questions"`
+	}
+	if err := c.Bind(&quiz); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if quiz.StoryID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	result, err := models.SaveStoryQuiz(quiz.StoryID, quiz.Questions)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if errors.Is(err, models.ErrInvalidQuiz) {
+		return c.JSON(
+			http.StatusUnprocessableEntity,
+			map[string]string{"message": err.Error()},
+		)
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// SubmitStoryQuiz scores the answers of a user (user_id) or device (uid)
+func SubmitStoryQuiz(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	// Parse the request body to populate the submission struct
+	var submission struct {
+		UserID  *int    `json:"user_id"`
+		UID     *string `json:"uid"`
+		Answers []struct {
+			QuestionID int `json:"question_id"`
+			OptionID   int `json:"option_id"`
+		} `json:"answers"`
+	}
+	if err := c.Bind(&submission); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if submission.UID != nil && *submission.UID == "" {
+		submission.UID = nil
+	}
+	if submission.UserID == nil && submission.UID == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_id or uid is required"})
+	}
+
+	answers := make(map[int]int)
+	for _, answer := range submission.Answers {
+		if _, ok := answers[answer.QuestionID]; ok {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Duplicate answer for question " + strconv.Itoa(answer.QuestionID)})
+		}
+		answers[answer.QuestionID] = answer.OptionID
+	}
+
+	result, err := models.SubmitStoryQuiz(storyID, submission.UserID, submission.UID, answers)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if errors.Is(err, models.ErrInvalidQuiz) {
+		return c.JSON(
+			http.StatusUnprocessableEntity,
+			map[string]string{"message": err.Error()},
+		)
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetQuizAttempts lists the attempts of the user_id or uid query param on a story's quiz
+func GetQuizAttempts(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	userIDParam := c.QueryParam("user_id")
+	var userID *int
+	if userIDParam != "" {
+		parsedUserID, err := strconv.Atoi(userIDParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+		}
+		userID = &parsedUserID
+	}
+
+	uidParam := c.QueryParam("uid")
+	var uid *string
+	if uidParam != "" {
+		uid = &uidParam
+	}
+
+	if userID == nil && uid == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_id or uid is required"})
+	}
+
+	result, err := models.GetQuizAttempts(storyID, userID, uid)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,61 @@
+// Read Event Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RecordReadEvent stores a read or finish event and returns the streak and any badges it unlocked
+func RecordReadEvent(c echo.Context) error {
+	// Parse the request body to populate the event struct
+	var event struct {
+		UserID    int    `json:"user_id"`
+		StoryID   int    `json:"story_id"`
+		EventType string `json:"event_type"`
+	}
+	if err := c.Bind(&event); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if event.UserID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	if !models.IsValidReadEventType(event.EventType) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid event_type"})
+	}
+
+	result, err := models.RecordReadEvent(event.UserID, event.StoryID, event.EventType)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetReadingStreak returns the daily reading streak of a user
+func GetReadingStreak(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	streak, err := models.GetReadingStreak(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, streak)
+}
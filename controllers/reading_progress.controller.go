@@ -0,0 +1,45 @@
+// Reading Progress Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"kisahloka_be/models"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SaveReadingProgress records the page a user (user_id) or device (uid) has reached in a story
+func SaveReadingProgress(c echo.Context) error {
+	// Parse the request body to populate the progress struct
+	var progress struct {
+		StoryID int     `json:"story_id"`
+		UserID  *int    `json:"user_id"`
+		UID     *string `json:"uid"`
+		Order   int     `json:"order"`
+	}
+	if err := c.Bind(&progress); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if progress.UID != nil && *progress.UID == "" {
+		progress.UID = nil
+	}
+	if progress.UserID == nil && progress.UID == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_id or uid is required"})
+	}
+
+	result, err := models.SaveReadingProgress(progress.StoryID, progress.UserID, progress.UID, progress.Order)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusUnprocessableEntity,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
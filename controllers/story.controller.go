@@ -3,13 +3,18 @@
 package controllers
 
 import (
+	"database/sql"
+	"errors"
 	"kisahloka_be/models"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// GetAllStoriesCompleted lists the published stories with their pages
 func GetAllStoriesCompleted(c echo.Context) error {
 	// Get query parameters for pagination
 	page, err := strconv.Atoi(c.QueryParam("page"))
@@ -24,7 +29,7 @@ func GetAllStoriesCompleted(c echo.Context) error {
 
 	keyword := c.QueryParam("keyword")
 
-	result, err := models.GetAllStoriesCompleted(page, pageSize, keyword)
+	result, err := models.GetAllStoriesCompleted(page, pageSize, keyword, "", true, requestLocale(c))
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -35,7 +40,8 @@ func GetAllStoriesCompleted(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
-func GetAllStoriesPreview(c echo.Context) error {
+// GetAllStoriesAdmin lists stories of every status, optionally filtered by the status query param
+func GetAllStoriesAdmin(c echo.Context) error {
 	// Get query parameters for pagination
 	page, err := strconv.Atoi(c.QueryParam("page"))
 	if err != nil || page < 1 {
@@ -49,12 +55,81 @@ func GetAllStoriesPreview(c echo.Context) error {
 
 	keyword := c.QueryParam("keyword")
 
-	typeID, err := strconv.Atoi(c.QueryParam("type_id"))
+	status := c.QueryParam("status")
+	if status != "" && !models.IsValidStoryStatus(status) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+	}
+
+	result, err := models.GetAllStoriesCompleted(page, pageSize, keyword, status, false, "")
 	if err != nil {
-		typeID = 0
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func GetAllStoriesPreview(c echo.Context) error {
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
 	}
 
-	result, err := models.GetAllStoriesPreview(page, pageSize, keyword, typeID)
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	filter := models.StoryPreviewFilter{
+		Keyword:       c.QueryParam("keyword"),
+		CharacterName: c.QueryParam("character"),
+		Sort:          c.QueryParam("sort"),
+	}
+
+	if _, ok := models.StoryPreviewSorts[filter.Sort]; filter.Sort != "" && !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid sort"})
+	}
+
+	typeID, err := strconv.Atoi(c.QueryParam("type_id"))
+	if err == nil {
+		filter.TypeID = typeID
+	}
+
+	// origin_id also matches the origins below it, e.g. an island matches its provinces
+	originID, err := strconv.Atoi(c.QueryParam("origin_id"))
+	if err == nil {
+		filter.OriginID = originID
+	}
+
+	characterID, err := strconv.Atoi(c.QueryParam("character_id"))
+	if err == nil {
+		filter.CharacterID = characterID
+	}
+
+	// tag accepts comma-separated tag names or slugs and matches stories with all of them
+	if tags := c.QueryParam("tag"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if slug := models.TagSlug(tag); slug != "" {
+				filter.TagSlugs = append(filter.TagSlugs, slug)
+			}
+		}
+	}
+
+	// moral_value_id accepts a comma-separated list and matches any of the values
+	if moralValueIDs := c.QueryParam("moral_value_id"); moralValueIDs != "" {
+		for _, value := range strings.Split(moralValueIDs, ",") {
+			moralValueID, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid moral_value_id"})
+			}
+			filter.MoralValueIDs = append(filter.MoralValueIDs, moralValueID)
+		}
+	}
+
+	result, err := models.GetAllStoriesPreview(page, pageSize, filter, requestLocale(c))
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -87,7 +162,10 @@ func GetStoryDetail(c echo.Context) error {
 		uid = &uidParam
 	}
 
-	storyDetail, err := models.GetStoryDetail(storyID, userID, uid)
+	storyDetail, err := models.GetStoryDetail(storyID, userID, uid, requestLocale(c))
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"message": "Story not found"})
+	}
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -104,7 +182,10 @@ func GetStoryContentOnStory(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
 	}
 
-	storyDetail, err := models.GetStoryContentOnStory(storyID)
+	storyDetail, err := models.GetStoryContentOnStory(storyID, requestLocale(c))
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"message": "Story not found"})
+	}
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -130,6 +211,9 @@ func CreateStory(c echo.Context) error {
 
 	// Call the CreateStory function from the models package
 	result, err := models.CreateStory(storyObj)
+	if errors.Is(err, models.ErrInvalidStoryStatus) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -167,6 +251,69 @@ func UpdateStory(c echo.Context) error {
 
 	// Call the UpdateStory function from the models package
 	result, err := models.UpdateStory(convID, updateFields)
+	if errors.Is(err, models.ErrInvalidStoryStatus) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func UpdateStoryStatus(c echo.Context) error {
+	var statusData struct {
+		StoryID      int        `json:"story_id"`
+		Status       string     `json:"status"`
+		ReleasedDate *time.Time `json:"released_date"`
+	}
+
+	// Parse the request body to populate statusData struct
+	if err := c.Bind(&statusData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	result, err := models.UpdateStoryStatus(statusData.StoryID, statusData.Status, statusData.ReleasedDate)
+	if errors.Is(err, models.ErrInvalidStoryStatus) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"message": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func UpdateStoryContent(c echo.Context) error {
+	var contentData struct {
+		StoryID      int                         `json:"story_id"`
+		StoryContent []models.StoryContentOnList `json:"story_content"`
+	}
+
+	// Parse the request body to populate contentData struct
+	if err := c.Bind(&contentData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// Page orders identify pages, so they have to be unique
+	seen := make(map[int]bool)
+	for _, content := range contentData.StoryContent {
+		if seen[content.Order] {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Duplicate page order " + strconv.Itoa(content.Order)})
+		}
+		seen[content.Order] = true
+	}
+
+	result, err := models.UpdateStoryContent(contentData.StoryID, contentData.StoryContent)
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -208,7 +355,7 @@ func GetStoriesRecommendationRandom(c echo.Context) error {
 		excludeStoryID = 0 // Default exclude story ID
 	}
 
-	result, err := models.GetStoriesRecommendationRandom(limit, excludeStoryID)
+	result, err := models.GetStoriesRecommendationRandom(limit, excludeStoryID, requestLocale(c))
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
@@ -0,0 +1,159 @@
+// Story Comment Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetStoryComments lists the approved comments of a story. Passing user_id also
+// shows that user's comments still waiting for moderation.
+func GetStoryComments(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	userIDParam := c.QueryParam("user_id")
+	var userID *int
+	if userIDParam != "" {
+		parsedUserID, err := strconv.Atoi(userIDParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+		}
+		userID = &parsedUserID
+	}
+
+	result, err := models.GetStoryComments(storyID, page, pageSize, userID)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// CreateStoryComment posts a comment, or a reply when parent_id is set
+func CreateStoryComment(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	// Parse the request body to populate the comment struct
+	var comment struct {
+		UserID   int    `json:"user_id"`
+		ParentID *int   `json:"parent_id"`
+		Content  string `json:"content"`
+	}
+	if err := c.Bind(&comment); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if comment.UserID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	result, err := models.CreateStoryComment(storyID, comment.UserID, comment.ParentID, comment.Content)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if errors.Is(err, models.ErrInvalidComment) {
+		return c.JSON(
+			http.StatusUnprocessableEntity,
+			map[string]string{"message": err.Error()},
+		)
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetModerationQueue lists comments for moderators, pending ones unless status says otherwise
+func GetModerationQueue(c echo.Context) error {
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	status := c.QueryParam("status")
+	if status == "" {
+		status = models.CommentStatusPending
+	}
+	if !models.IsValidCommentStatus(status) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+	}
+
+	result, err := models.GetModerationQueue(status, page, pageSize)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ModerateStoryComment approves, rejects or hides a comment
+func ModerateStoryComment(c echo.Context) error {
+	var moderation struct {
+		CommentID   int    `json:"comment_id"`
+		Status      string `json:"status"`
+		ModeratorID *int   `json:"moderator_id"`
+	}
+
+	// Parse the request body to populate the moderation struct
+	if err := c.Bind(&moderation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	switch moderation.Status {
+	case models.CommentStatusApproved, models.CommentStatusRejected, models.CommentStatusHidden:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Status must be approved, rejected or hidden"})
+	}
+
+	result, err := models.ModerateStoryComment(moderation.CommentID, moderation.Status, moderation.ModeratorID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Comment not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,103 @@
+// Story Content Audio Controller
+
+package controllers
+
+import (
+	"errors"
+	"kisahloka_be/audioprobe"
+	"kisahloka_be/models"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UploadStoryContentAudio stores a narration file sent as multipart form data
+// with the fields file and locale. The format and duration are detected from
+// the file content.
+func UploadStoryContentAudio(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	order, err := strconv.Atoi(c.Param("order"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid order"})
+	}
+
+	locale := c.FormValue("locale")
+	if !models.IsSupportedLocale(locale) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported locale"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing file"})
+	}
+
+	if fileHeader.Size > models.MaxAudioFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "Audio file is too large"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid file"})
+	}
+	defer file.Close()
+
+	result, err := models.SaveStoryContentAudio(models.StoryContentAudio{
+		StoryID: storyID,
+		Order:   order,
+		Locale:  locale,
+	}, file)
+	if errors.Is(err, audioprobe.ErrUnsupported) {
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// StreamStoryContentAudio serves a narration file, honoring Range requests so
+// players can seek without downloading the whole file
+func StreamStoryContentAudio(c echo.Context) error {
+	audioID, err := strconv.Atoi(c.Param("audio_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid audio_id"})
+	}
+
+	audio, file, err := models.OpenStoryContentAudio(audioID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Audio not found"})
+	}
+	defer file.Close()
+
+	c.Response().Header().Set(echo.HeaderContentType, audio.MimeType)
+	http.ServeContent(c.Response(), c.Request(), filepath.Base(audio.FilePath), audio.UpdatedAt, file)
+
+	return nil
+}
+
+func DeleteStoryContentAudio(c echo.Context) error {
+	audioID, err := strconv.Atoi(c.Param("audio_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid audio_id"})
+	}
+
+	result, err := models.DeleteStoryContentAudio(audioID)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,110 @@
+// Story Content Timing Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxTimingBodySize limits WebVTT uploads, which are read into memory
+const maxTimingBodySize = 1 << 20
+
+// SaveStoryContentTiming accepts word timings for one page either as JSON
+// ({"locale": "id", "words": [{"word", "start_ms", "end_ms"}]}) or as a WebVTT
+// body sent with Content-Type text/vtt and the locale in the query string
+func SaveStoryContentTiming(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	order, err := strconv.Atoi(c.Param("order"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid order"})
+	}
+
+	timing := models.StoryContentTiming{
+		StoryID: storyID,
+		Order:   order,
+	}
+
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/vtt") {
+		body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxTimingBodySize))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+
+		timing.Words, err = models.ParseWebVTTTimings(string(body))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		timing.Locale = c.QueryParam("locale")
+		timing.Format = models.TimingFormatWebVTT
+	} else {
+		var timingData struct {
+			Locale string              `json:"locale"`
+			Words  []models.WordTiming `json:"words"`
+		}
+
+		// Parse the request body to populate timingData struct
+		if err := c.Bind(&timingData); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+		timing.Locale = timingData.Locale
+		timing.Words = timingData.Words
+		timing.Format = models.TimingFormatJSON
+	}
+
+	if !models.IsSupportedLocale(timing.Locale) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported locale"})
+	}
+
+	result, err := models.SaveStoryContentTiming(timing)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Page has no text in this locale"})
+	}
+	if errors.Is(err, models.ErrInvalidTiming) {
+		return c.JSON(
+			http.StatusUnprocessableEntity,
+			map[string]string{"message": err.Error()},
+		)
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func DeleteStoryContentTiming(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	order, err := strconv.Atoi(c.Param("order"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid order"})
+	}
+
+	result, err := models.DeleteStoryContentTiming(storyID, order, c.Param("locale"))
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
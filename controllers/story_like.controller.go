@@ -0,0 +1,62 @@
+// Story Like Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// LikeStory likes a story for the user_id or uid query param
+func LikeStory(c echo.Context) error {
+	return setStoryLike(c, true)
+}
+
+// UnlikeStory takes back the like of the user_id or uid query param on a story
+func UnlikeStory(c echo.Context) error {
+	return setStoryLike(c, false)
+}
+
+func setStoryLike(c echo.Context, liked bool) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	userIDParam := c.QueryParam("user_id")
+	var userID *int
+	if userIDParam != "" {
+		parsedUserID, err := strconv.Atoi(userIDParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+		}
+		userID = &parsedUserID
+	}
+
+	uidParam := c.QueryParam("uid")
+	var uid *string
+	if uidParam != "" {
+		uid = &uidParam
+	}
+
+	if userID == nil && uid == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_id or uid is required"})
+	}
+
+	result, err := models.SetStoryLike(storyID, userID, uid, liked)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,120 @@
+// Story Review Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetStoryReviews lists the written reviews of a story. sort is one of newest
+// (default), oldest, highest or lowest.
+func GetStoryReviews(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	sort := c.QueryParam("sort")
+	if sort == "" {
+		sort = "newest"
+	}
+	if !models.IsValidReviewSort(sort) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid sort"})
+	}
+
+	result, err := models.GetStoryReviews(storyID, page, pageSize, sort)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// SaveStoryReview creates or replaces the rating and review of a user for a story
+func SaveStoryReview(c echo.Context) error {
+	// Parse the request body to populate the review struct
+	var review struct {
+		StoryID int    `json:"story_id"`
+		UserID  int    `json:"user_id"`
+		Rating  int    `json:"rating"`
+		Review  string `json:"review"`
+	}
+	if err := c.Bind(&review); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if review.UserID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	result, err := models.SaveStoryReview(review.StoryID, review.UserID, review.Rating, review.Review)
+	if errors.Is(err, models.ErrInvalidRating) {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Rating must be between 1 and 5"})
+	}
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if err == models.ErrReviewUserNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// DeleteStoryReview removes a review on behalf of its author, given as the user_id query param
+func DeleteStoryReview(c echo.Context) error {
+	reviewID, err := strconv.Atoi(c.Param("review_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid review_id"})
+	}
+
+	userID, err := strconv.Atoi(c.QueryParam("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+	}
+
+	result, err := models.DeleteStoryReview(reviewID, userID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Review not found"})
+	}
+	if err == models.ErrNotReviewOwner {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You can only delete your own review"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,129 @@
+// Story Translation Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetSupportedLocales(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.Response{
+		Data: map[string]interface{}{
+			"locales": models.SupportedLocales,
+		},
+	})
+}
+
+func GetStoryLocales(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	result, err := models.GetStoryLocales(storyID)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func GetStoryTranslation(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	locale := c.Param("locale")
+	if !models.IsSupportedLocale(locale) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported locale"})
+	}
+
+	result, err := models.GetStoryTranslation(storyID, locale)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Translation not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func SaveStoryTranslation(c echo.Context) error {
+	var translation models.StoryTranslationInput
+
+	// Parse the request body to populate the translation struct
+	if err := c.Bind(&translation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if !models.IsSupportedLocale(translation.Locale) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported locale"})
+	}
+
+	result, err := models.SaveStoryTranslation(translation)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if errors.Is(err, models.ErrInvalidTranslation) {
+		return c.JSON(
+			http.StatusUnprocessableEntity,
+			map[string]string{"message": err.Error()},
+		)
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func DeleteStoryTranslation(c echo.Context) error {
+	storyID, err := strconv.Atoi(c.Param("story_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	locale := c.Param("locale")
+	if !models.IsSupportedLocale(locale) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported locale"})
+	}
+	if locale == models.LocaleIndonesian {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "The Indonesian text cannot be deleted"})
+	}
+
+	result, err := models.DeleteStoryTranslation(storyID, locale)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if errors.Is(err, models.ErrInvalidTranslation) {
+		return c.JSON(
+			http.StatusUnprocessableEntity,
+			map[string]string{"message": err.Error()},
+		)
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
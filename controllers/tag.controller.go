@@ -0,0 +1,114 @@
+// Tag Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllTags lists tags with their usage counts, filtered by the optional keyword
+func GetAllTags(c echo.Context) error {
+	tags, err := models.GetAllTags(c.QueryParam("keyword"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, tags)
+}
+
+// AutocompleteTags suggests tags starting with the q query param
+func AutocompleteTags(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	tags, err := models.AutocompleteTags(c.QueryParam("q"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, tags)
+}
+
+// UpdateTag renames a tag identified by tag_id in the body
+func UpdateTag(c echo.Context) error {
+	var tag struct {
+		TagID   int    `json:"tag_id"`
+		TagName string `json:"tag_name"`
+	}
+
+	if err := c.Bind(&tag); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if tag.TagID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tag_id"})
+	}
+	if models.TagSlug(tag.TagName) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "tag_name needs letters or digits"})
+	}
+
+	rowsAffected, err := models.UpdateTag(tag.TagID, tag.TagName)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Tag not found"})
+	}
+	if errors.Is(err, models.ErrDuplicateTag) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteTag(c echo.Context) error {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tag_id"})
+	}
+
+	rowsAffected, err := models.DeleteTag(tagID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Tag not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+// SetStoryTags replaces the tags of a story, creating tags that do not exist yet
+func SetStoryTags(c echo.Context) error {
+	// Parse the request body to populate the story tags struct
+	var storyTags struct {
+		StoryID int      `json:"story_id"`
+		Tags    []string `json:"tags"`
+	}
+	if err := c.Bind(&storyTags); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if storyTags.StoryID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid story_id"})
+	}
+
+	result, err := models.SetStoryTags(storyTags.StoryID, storyTags.Tags)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Story not found"})
+	}
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetTaxonomyTranslations(c echo.Context) error {
+	taxonomy := c.Param("taxonomy")
+	if !models.IsValidTaxonomy(taxonomy) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid taxonomy"})
+	}
+
+	taxonomyID, err := strconv.Atoi(c.Param("taxonomy_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid taxonomy_id"})
+	}
+
+	translations, err := models.GetTaxonomyTranslations(taxonomy, taxonomyID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, translations)
+}
+
+func SaveTaxonomyTranslation(c echo.Context) error {
+	var translation struct {
+		Taxonomy   string `json:"taxonomy"`
+		TaxonomyID int    `json:"taxonomy_id"`
+		Locale     string `json:"locale"`
+		Name       string `json:"name"`
+	}
+
+	if err := c.Bind(&translation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if !models.IsValidTaxonomy(translation.Taxonomy) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid taxonomy"})
+	}
+	if !models.IsSupportedLocale(translation.Locale) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported locale"})
+	}
+
+	rowsAffected, err := models.SaveTaxonomyTranslation(translation.Taxonomy, translation.TaxonomyID, translation.Locale, translation.Name)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteTaxonomyTranslation(c echo.Context) error {
+	taxonomy := c.Param("taxonomy")
+	if !models.IsValidTaxonomy(taxonomy) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid taxonomy"})
+	}
+
+	taxonomyID, err := strconv.Atoi(c.Param("taxonomy_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid taxonomy_id"})
+	}
+
+	rowsAffected, err := models.DeleteTaxonomyTranslation(taxonomy, taxonomyID, c.Param("locale"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
@@ -22,7 +22,7 @@ func GetAllTypes(c echo.Context) error {
 
 	keyword := c.QueryParam("keyword")
 
-	result, err := models.GetAllTypes(page, pageSize, keyword)
+	result, err := models.GetAllTypes(page, pageSize, keyword, requestLocale(c))
 	if err != nil {
 		return c.JSON(
 			http.StatusInternalServerError,
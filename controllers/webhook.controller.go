@@ -0,0 +1,172 @@
+// Webhook Controller
+
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"kisahloka_be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetAllWebhooks(c echo.Context) error {
+	webhooks, err := models.GetAllWebhooks()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+func GetWebhookDetail(c echo.Context) error {
+	webhookID, err := strconv.Atoi(c.Param("webhook_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook_id"})
+	}
+
+	hook, err := models.GetWebhookDetail(webhookID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Webhook not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, hook)
+}
+
+// CreateWebhook adds a subscription. The response is the only place the
+// signing secret is shown.
+func CreateWebhook(c echo.Context) error {
+	// New webhooks are active unless is_active is sent as false
+	hook := models.Webhook{IsActive: true}
+
+	if err := c.Bind(&hook); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	created, err := models.CreateWebhook(hook)
+	if errors.Is(err, models.ErrInvalidWebhook) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, created)
+}
+
+// UpdateWebhook replaces a subscription identified by webhook_id in the body
+func UpdateWebhook(c echo.Context) error {
+	var hook models.Webhook
+
+	if err := c.Bind(&hook); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if hook.WebhookID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook_id"})
+	}
+
+	rowsAffected, err := models.UpdateWebhook(hook)
+	if errors.Is(err, models.ErrInvalidWebhook) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Webhook not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+func DeleteWebhook(c echo.Context) error {
+	webhookID, err := strconv.Atoi(c.Param("webhook_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook_id"})
+	}
+
+	rowsAffected, err := models.DeleteWebhook(webhookID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Webhook not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
+
+// GetWebhookDeliveries lists a webhook's deliveries, optionally filtered by the status query param
+func GetWebhookDeliveries(c echo.Context) error {
+	webhookID, err := strconv.Atoi(c.Param("webhook_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook_id"})
+	}
+
+	// Get query parameters for pagination
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	status := c.QueryParam("status")
+	if status != "" && !models.IsValidWebhookDeliveryStatus(status) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+	}
+
+	result, err := models.GetWebhookDeliveries(webhookID, page, pageSize, status)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			map[string]string{"message": err.Error()},
+		)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetWebhookDeliveryDetail returns a delivery with its payload and the log of its attempts
+func GetWebhookDeliveryDetail(c echo.Context) error {
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery_id"})
+	}
+
+	delivery, err := models.GetWebhookDeliveryDetail(deliveryID)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Delivery not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// RetryWebhookDelivery queues a pending or failed delivery to be sent right away
+func RetryWebhookDelivery(c echo.Context) error {
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery_id"})
+	}
+
+	rowsAffected, err := models.RetryWebhookDelivery(deliveryID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No pending or failed delivery found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"rows_affected": rowsAffected})
+}
@@ -0,0 +1,10 @@
+-- Story publishing workflow
+-- Existing stories were visible as soon as they were inserted, so they are
+-- migrated as published. New stories start as draft.
+
+ALTER TABLE story
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'draft' AFTER is_favorited;
+
+UPDATE story SET status = 'published';
+
+CREATE INDEX idx_story_status_released_date ON story (status, released_date);
@@ -0,0 +1,13 @@
+-- Immutable story revisions
+-- Each row stores the full story and its pages as they were after a change.
+
+CREATE TABLE story_revision (
+    revision_id     INT AUTO_INCREMENT PRIMARY KEY,
+    story_id        INT          NOT NULL,
+    revision_number INT          NOT NULL,
+    snapshot        JSON         NOT NULL,
+    note            VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      DATETIME     NOT NULL,
+    UNIQUE KEY uq_story_revision_number (story_id, revision_number),
+    CONSTRAINT fk_story_revision_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
@@ -0,0 +1,36 @@
+-- Locale-keyed story text
+-- story.title/synopsis mirror the 'id' locale and story_content.content_indo /
+-- content_eng mirror the 'id' and 'en' page text, so existing clients keep working.
+
+CREATE TABLE story_translation (
+    story_id   INT          NOT NULL,
+    locale     VARCHAR(10)  NOT NULL,
+    title      VARCHAR(255) NOT NULL DEFAULT '',
+    synopsis   TEXT         NOT NULL,
+    created_at DATETIME     NOT NULL,
+    updated_at DATETIME     NOT NULL,
+    PRIMARY KEY (story_id, locale),
+    CONSTRAINT fk_story_translation_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
+
+CREATE TABLE story_content_translation (
+    story_id   INT         NOT NULL,
+    `order`    INT         NOT NULL,
+    locale     VARCHAR(10) NOT NULL,
+    content    TEXT        NOT NULL,
+    created_at DATETIME    NOT NULL,
+    updated_at DATETIME    NOT NULL,
+    PRIMARY KEY (story_id, `order`, locale),
+    KEY idx_story_content_translation_locale (story_id, locale),
+    CONSTRAINT fk_story_content_translation_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
+
+-- Existing titles and synopses are Indonesian
+INSERT INTO story_translation (story_id, locale, title, synopsis, created_at, updated_at)
+SELECT story_id, 'id', title, synopsis, created_at, updated_at FROM story;
+
+INSERT INTO story_content_translation (story_id, `order`, locale, content, created_at, updated_at)
+SELECT story_id, `order`, 'id', content_indo, UTC_TIMESTAMP(), UTC_TIMESTAMP() FROM story_content WHERE content_indo <> '';
+
+INSERT INTO story_content_translation (story_id, `order`, locale, content, created_at, updated_at)
+SELECT story_id, `order`, 'en', content_eng, UTC_TIMESTAMP(), UTC_TIMESTAMP() FROM story_content WHERE content_eng <> '';
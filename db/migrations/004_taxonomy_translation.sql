@@ -0,0 +1,11 @@
+-- Translated names for types, origins and genres
+
+CREATE TABLE taxonomy_translation (
+    taxonomy    VARCHAR(20)  NOT NULL,
+    taxonomy_id INT          NOT NULL,
+    locale      VARCHAR(10)  NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    created_at  DATETIME     NOT NULL,
+    updated_at  DATETIME     NOT NULL,
+    PRIMARY KEY (taxonomy, taxonomy_id, locale)
+);
@@ -0,0 +1,16 @@
+-- Read-aloud narration, one audio file per page and locale
+
+CREATE TABLE story_content_audio (
+    audio_id    INT AUTO_INCREMENT PRIMARY KEY,
+    story_id    INT          NOT NULL,
+    `order`     INT          NOT NULL,
+    locale      VARCHAR(10)  NOT NULL,
+    file_path   VARCHAR(512) NOT NULL,
+    mime_type   VARCHAR(100) NOT NULL,
+    file_size   BIGINT       NOT NULL,
+    duration_ms INT          NOT NULL,
+    created_at  DATETIME     NOT NULL,
+    updated_at  DATETIME     NOT NULL,
+    UNIQUE KEY uq_story_content_audio_page (story_id, `order`, locale),
+    CONSTRAINT fk_story_content_audio_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+-- Word-level narration timings for read-along highlighting
+
+CREATE TABLE story_content_timing (
+    story_id   INT         NOT NULL,
+    `order`    INT         NOT NULL,
+    locale     VARCHAR(10) NOT NULL,
+    format     VARCHAR(10) NOT NULL,
+    words      JSON        NOT NULL,
+    created_at DATETIME    NOT NULL,
+    updated_at DATETIME    NOT NULL,
+    PRIMARY KEY (story_id, `order`, locale),
+    CONSTRAINT fk_story_content_timing_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
@@ -0,0 +1,25 @@
+-- Uploaded images with their placeholder data and resized variants. Images are
+-- matched to stories by URL, so thumbnails set as free text simply have no asset.
+
+CREATE TABLE image_asset (
+    image_id       INT AUTO_INCREMENT PRIMARY KEY,
+    sha256         CHAR(64)     NOT NULL,
+    url            VARCHAR(512) NOT NULL,
+    width          INT          NOT NULL,
+    height         INT          NOT NULL,
+    blurhash       VARCHAR(64)  NOT NULL,
+    dominant_color CHAR(7)      NOT NULL,
+    created_at     DATETIME     NOT NULL,
+    UNIQUE KEY uq_image_asset_url (url),
+    KEY idx_image_asset_sha256 (sha256)
+);
+
+CREATE TABLE image_variant (
+    image_id  INT          NOT NULL,
+    width     INT          NOT NULL,
+    format    VARCHAR(10)  NOT NULL,
+    url       VARCHAR(512) NOT NULL,
+    file_size BIGINT       NOT NULL,
+    PRIMARY KEY (image_id, width, format),
+    CONSTRAINT fk_image_variant_image FOREIGN KEY (image_id) REFERENCES image_asset (image_id) ON DELETE CASCADE
+);
@@ -0,0 +1,29 @@
+-- Vocabulary glossary and the word spans of story pages linked to it
+
+CREATE TABLE glossary (
+    glossary_id   INT AUTO_INCREMENT PRIMARY KEY,
+    term          VARCHAR(255) NOT NULL,
+    locale        VARCHAR(10)  NOT NULL,
+    definition    TEXT         NOT NULL,
+    pronunciation VARCHAR(255) NULL,
+    created_at    DATETIME     NOT NULL,
+    updated_at    DATETIME     NOT NULL,
+    UNIQUE KEY uq_glossary_term (term, locale)
+);
+
+-- char_start and char_end are rune offsets into the page text of the locale;
+-- text keeps the annotated span so edits to the page can be detected
+CREATE TABLE story_content_annotation (
+    annotation_id INT AUTO_INCREMENT PRIMARY KEY,
+    story_id      INT          NOT NULL,
+    `order`       INT          NOT NULL,
+    locale        VARCHAR(10)  NOT NULL,
+    glossary_id   INT          NOT NULL,
+    char_start    INT          NOT NULL,
+    char_end      INT          NOT NULL,
+    text          VARCHAR(255) NOT NULL,
+    created_at    DATETIME     NOT NULL,
+    KEY idx_story_content_annotation_page (story_id, `order`, locale),
+    CONSTRAINT fk_story_content_annotation_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE,
+    CONSTRAINT fk_story_content_annotation_glossary FOREIGN KEY (glossary_id) REFERENCES glossary (glossary_id) ON DELETE CASCADE
+);
@@ -0,0 +1,52 @@
+-- Comprehension quizzes: bilingual questions per story and the attempts of readers
+
+CREATE TABLE quiz_question (
+    question_id      INT AUTO_INCREMENT PRIMARY KEY,
+    story_id         INT          NOT NULL,
+    question_type    VARCHAR(20)  NOT NULL,
+    question_indo    TEXT         NOT NULL,
+    question_eng     TEXT         NOT NULL,
+    explanation_indo TEXT         NULL,
+    explanation_eng  TEXT         NULL,
+    position         INT          NOT NULL,
+    created_at       DATETIME     NOT NULL,
+    updated_at       DATETIME     NOT NULL,
+    KEY idx_quiz_question_story (story_id, position),
+    CONSTRAINT fk_quiz_question_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
+
+CREATE TABLE quiz_option (
+    option_id   INT AUTO_INCREMENT PRIMARY KEY,
+    question_id INT          NOT NULL,
+    option_indo VARCHAR(500) NOT NULL,
+    option_eng  VARCHAR(500) NOT NULL,
+    is_correct  TINYINT(1)   NOT NULL DEFAULT 0,
+    position    INT          NOT NULL,
+    CONSTRAINT fk_quiz_option_question FOREIGN KEY (question_id) REFERENCES quiz_question (question_id) ON DELETE CASCADE
+);
+
+-- Attempts belong to a registered user (user_id) or an anonymous device (uid),
+-- like bookmarks
+CREATE TABLE quiz_attempt (
+    attempt_id INT AUTO_INCREMENT PRIMARY KEY,
+    story_id   INT          NOT NULL,
+    user_id    INT          NULL,
+    uid        VARCHAR(255) NULL,
+    score      INT          NOT NULL,
+    total      INT          NOT NULL,
+    created_at DATETIME     NOT NULL,
+    KEY idx_quiz_attempt_user (user_id, story_id),
+    KEY idx_quiz_attempt_uid (uid, story_id),
+    CONSTRAINT fk_quiz_attempt_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
+
+CREATE TABLE quiz_attempt_answer (
+    attempt_id  INT        NOT NULL,
+    question_id INT        NOT NULL,
+    option_id   INT        NULL,
+    is_correct  TINYINT(1) NOT NULL,
+    PRIMARY KEY (attempt_id, question_id),
+    CONSTRAINT fk_quiz_attempt_answer_attempt FOREIGN KEY (attempt_id) REFERENCES quiz_attempt (attempt_id) ON DELETE CASCADE,
+    CONSTRAINT fk_quiz_attempt_answer_question FOREIGN KEY (question_id) REFERENCES quiz_question (question_id) ON DELETE CASCADE,
+    CONSTRAINT fk_quiz_attempt_answer_option FOREIGN KEY (option_id) REFERENCES quiz_option (option_id) ON DELETE SET NULL
+);
@@ -0,0 +1,22 @@
+-- "Pesan moral" of a story in both languages and a managed taxonomy of the
+-- values a story teaches, linked many-to-many like story_genre
+
+ALTER TABLE story
+    ADD COLUMN moral_indo TEXT NULL AFTER synopsis,
+    ADD COLUMN moral_eng  TEXT NULL AFTER moral_indo;
+
+CREATE TABLE moral_value (
+    moral_value_id INT AUTO_INCREMENT PRIMARY KEY,
+    value_name     VARCHAR(255) NOT NULL,
+    created_at     DATETIME     NOT NULL,
+    updated_at     DATETIME     NOT NULL
+);
+
+CREATE TABLE story_moral_value (
+    story_id       INT NOT NULL,
+    moral_value_id INT NOT NULL,
+    PRIMARY KEY (story_id, moral_value_id),
+    KEY idx_story_moral_value_value (moral_value_id),
+    CONSTRAINT fk_story_moral_value_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE,
+    CONSTRAINT fk_story_moral_value_value FOREIGN KEY (moral_value_id) REFERENCES moral_value (moral_value_id) ON DELETE CASCADE
+);
@@ -0,0 +1,21 @@
+-- Characters (tokoh) shared between tales. CHARACTER is a reserved word, so the
+-- table name has to be quoted like `order`.
+
+CREATE TABLE `character` (
+    character_id INT AUTO_INCREMENT PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    description  TEXT         NULL,
+    image        VARCHAR(512) NULL,
+    created_at   DATETIME     NOT NULL,
+    updated_at   DATETIME     NOT NULL,
+    KEY idx_character_name (name)
+);
+
+CREATE TABLE story_character (
+    story_id     INT NOT NULL,
+    character_id INT NOT NULL,
+    PRIMARY KEY (story_id, character_id),
+    KEY idx_story_character_character (character_id),
+    CONSTRAINT fk_story_character_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE,
+    CONSTRAINT fk_story_character_character FOREIGN KEY (character_id) REFERENCES `character` (character_id) ON DELETE CASCADE
+);
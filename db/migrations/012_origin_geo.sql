@@ -0,0 +1,7 @@
+-- Where a tale comes from, so origins can be placed on a map
+
+ALTER TABLE origin
+    ADD COLUMN province  VARCHAR(255)  NULL AFTER origin_name,
+    ADD COLUMN island    VARCHAR(255)  NULL AFTER province,
+    ADD COLUMN latitude  DECIMAL(9, 6) NULL AFTER island,
+    ADD COLUMN longitude DECIMAL(9, 6) NULL AFTER latitude;
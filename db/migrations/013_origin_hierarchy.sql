@@ -0,0 +1,8 @@
+-- Origins form a tree: island -> province -> ethnic group. Removing an origin
+-- turns its children into roots.
+
+ALTER TABLE origin
+    ADD COLUMN parent_id INT         NULL AFTER origin_name,
+    ADD COLUMN level     VARCHAR(20) NULL AFTER parent_id,
+    ADD KEY idx_origin_parent (parent_id),
+    ADD CONSTRAINT fk_origin_parent FOREIGN KEY (parent_id) REFERENCES origin (origin_id) ON DELETE SET NULL;
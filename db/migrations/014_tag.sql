@@ -0,0 +1,20 @@
+-- Free-form tags, finer grained than genres. slug is the normalized name used
+-- in URLs and filters.
+
+CREATE TABLE tag (
+    tag_id     INT AUTO_INCREMENT PRIMARY KEY,
+    tag_name   VARCHAR(100) NOT NULL,
+    slug       VARCHAR(100) NOT NULL,
+    created_at DATETIME     NOT NULL,
+    updated_at DATETIME     NOT NULL,
+    UNIQUE KEY uq_tag_slug (slug)
+);
+
+CREATE TABLE story_tag (
+    story_id INT NOT NULL,
+    tag_id   INT NOT NULL,
+    PRIMARY KEY (story_id, tag_id),
+    KEY idx_story_tag_tag (tag_id),
+    CONSTRAINT fk_story_tag_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE,
+    CONSTRAINT fk_story_tag_tag FOREIGN KEY (tag_id) REFERENCES tag (tag_id) ON DELETE CASCADE
+);
@@ -0,0 +1,23 @@
+-- Editor-curated collections such as "Cerita dari Sumatera". A collection is
+-- public between visible_from and visible_until; NULL leaves that side open.
+
+CREATE TABLE collection (
+    collection_id INT AUTO_INCREMENT PRIMARY KEY,
+    title         VARCHAR(255) NOT NULL,
+    description   TEXT         NULL,
+    cover_image   VARCHAR(255) NULL,
+    visible_from  DATETIME     NULL,
+    visible_until DATETIME     NULL,
+    created_at    DATETIME     NOT NULL,
+    updated_at    DATETIME     NOT NULL
+);
+
+CREATE TABLE collection_story (
+    collection_id INT NOT NULL,
+    story_id      INT NOT NULL,
+    position      INT NOT NULL,
+    PRIMARY KEY (collection_id, story_id),
+    KEY idx_collection_story_position (collection_id, position),
+    CONSTRAINT fk_collection_story_collection FOREIGN KEY (collection_id) REFERENCES collection (collection_id) ON DELETE CASCADE,
+    CONSTRAINT fk_collection_story_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
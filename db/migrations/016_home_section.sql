@@ -0,0 +1,43 @@
+-- Home page sections, rendered in position order while inside their active
+-- window. collection_id and genre_id are only used by the matching section type.
+
+CREATE TABLE home_section (
+    section_id    INT AUTO_INCREMENT PRIMARY KEY,
+    section_type  VARCHAR(30)  NOT NULL,
+    title         VARCHAR(255) NOT NULL,
+    collection_id INT          NULL,
+    genre_id      INT          NULL,
+    item_limit    INT          NOT NULL DEFAULT 10,
+    position      INT          NOT NULL DEFAULT 0,
+    active_from   DATETIME     NULL,
+    active_until  DATETIME     NULL,
+    created_at    DATETIME     NOT NULL,
+    updated_at    DATETIME     NOT NULL,
+    KEY idx_home_section_position (position),
+    CONSTRAINT fk_home_section_collection FOREIGN KEY (collection_id) REFERENCES collection (collection_id) ON DELETE CASCADE,
+    CONSTRAINT fk_home_section_genre FOREIGN KEY (genre_id) REFERENCES genre (genre_id) ON DELETE CASCADE
+);
+
+-- The last page a reader (user_id or device uid) reached in a story, used for
+-- "continue reading" and to find trending stories.
+CREATE TABLE reading_progress (
+    progress_id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id     INT          NULL,
+    uid         VARCHAR(255) NULL,
+    story_id    INT          NOT NULL,
+    last_order  INT          NOT NULL,
+    created_at  DATETIME     NOT NULL,
+    updated_at  DATETIME     NOT NULL,
+    UNIQUE KEY uq_reading_progress_user (user_id, story_id),
+    UNIQUE KEY uq_reading_progress_uid (uid, story_id),
+    KEY idx_reading_progress_story (story_id, updated_at),
+    CONSTRAINT fk_reading_progress_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
+
+-- Start with the blocks the home page had before sections were configurable
+INSERT INTO home_section (section_type, title, item_limit, position, created_at, updated_at) VALUES
+    ('continue_reading', 'Lanjutkan Membaca', 10, 1, UTC_TIMESTAMP(), UTC_TIMESTAMP()),
+    ('highlight', 'Cerita Pilihan', 10, 2, UTC_TIMESTAMP(), UTC_TIMESTAMP()),
+    ('favorite', 'Cerita Favorit', 10, 3, UTC_TIMESTAMP(), UTC_TIMESTAMP()),
+    ('new_releases', 'Cerita Terbaru', 10, 4, UTC_TIMESTAMP(), UTC_TIMESTAMP()),
+    ('trending', 'Sedang Populer', 10, 5, UTC_TIMESTAMP(), UTC_TIMESTAMP());
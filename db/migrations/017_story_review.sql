@@ -0,0 +1,15 @@
+-- One star rating per user per story, optionally with a written review
+
+CREATE TABLE story_review (
+    review_id  INT AUTO_INCREMENT PRIMARY KEY,
+    story_id   INT      NOT NULL,
+    user_id    INT      NOT NULL,
+    rating     TINYINT  NOT NULL,
+    review     TEXT     NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY uq_story_review_user (story_id, user_id),
+    KEY idx_story_review_user (user_id),
+    CONSTRAINT fk_story_review_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE,
+    CONSTRAINT fk_story_review_user FOREIGN KEY (user_id) REFERENCES user (user_id) ON DELETE CASCADE
+);
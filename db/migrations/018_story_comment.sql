@@ -0,0 +1,22 @@
+-- Threaded story comments. Comments start as pending unless the author is
+-- trusted and nothing was flagged; moderators approve, reject or hide them.
+
+CREATE TABLE story_comment (
+    comment_id    INT AUTO_INCREMENT PRIMARY KEY,
+    story_id      INT          NOT NULL,
+    user_id       INT          NOT NULL,
+    parent_id     INT          NULL,
+    content       TEXT         NOT NULL,
+    status        VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    flagged_words TEXT         NULL,
+    moderated_by  INT          NULL,
+    moderated_at  DATETIME     NULL,
+    created_at    DATETIME     NOT NULL,
+    updated_at    DATETIME     NOT NULL,
+    KEY idx_story_comment_story (story_id, status, created_at),
+    KEY idx_story_comment_status (status, created_at),
+    KEY idx_story_comment_user (user_id, status),
+    CONSTRAINT fk_story_comment_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE,
+    CONSTRAINT fk_story_comment_user FOREIGN KEY (user_id) REFERENCES user (user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_story_comment_parent FOREIGN KEY (parent_id) REFERENCES story_comment (comment_id) ON DELETE CASCADE
+);
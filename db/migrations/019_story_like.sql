@@ -0,0 +1,18 @@
+-- Per-reader likes. story.is_favorited stays the editorial flag; like_count
+-- caches the number of story_like rows so listings can sort by it.
+
+CREATE TABLE story_like (
+    like_id    INT AUTO_INCREMENT PRIMARY KEY,
+    story_id   INT          NOT NULL,
+    user_id    INT          NULL,
+    uid        VARCHAR(255) NULL,
+    created_at DATETIME     NOT NULL,
+    UNIQUE KEY uq_story_like_user (user_id, story_id),
+    UNIQUE KEY uq_story_like_uid (uid, story_id),
+    KEY idx_story_like_story (story_id),
+    CONSTRAINT fk_story_like_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
+
+ALTER TABLE story
+    ADD COLUMN like_count INT NOT NULL DEFAULT 0 AFTER read_count,
+    ADD KEY idx_story_like_count (like_count);
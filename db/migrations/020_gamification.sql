@@ -0,0 +1,49 @@
+-- Reading activity of signed-in users. Any event counts towards the daily
+-- streak; finished stories count towards badges.
+
+CREATE TABLE read_event (
+    event_id   INT AUTO_INCREMENT PRIMARY KEY,
+    user_id    INT         NOT NULL,
+    story_id   INT         NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    created_at DATETIME    NOT NULL,
+    KEY idx_read_event_user (user_id, created_at),
+    KEY idx_read_event_finish (user_id, event_type, story_id),
+    CONSTRAINT fk_read_event_user FOREIGN KEY (user_id) REFERENCES user (user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_read_event_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
+
+-- Badge definitions. rule_type picks the rule; threshold, origin_id, type_id
+-- and genre_id are its parameters.
+CREATE TABLE badge (
+    badge_id    INT AUTO_INCREMENT PRIMARY KEY,
+    code        VARCHAR(50)  NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NULL,
+    icon        VARCHAR(255) NULL,
+    rule_type   VARCHAR(30)  NOT NULL,
+    threshold   INT          NOT NULL DEFAULT 0,
+    origin_id   INT          NULL,
+    type_id     INT          NULL,
+    genre_id    INT          NULL,
+    created_at  DATETIME     NOT NULL,
+    updated_at  DATETIME     NOT NULL,
+    UNIQUE KEY uq_badge_code (code),
+    CONSTRAINT fk_badge_origin FOREIGN KEY (origin_id) REFERENCES origin (origin_id) ON DELETE CASCADE,
+    CONSTRAINT fk_badge_type FOREIGN KEY (type_id) REFERENCES type (type_id) ON DELETE CASCADE,
+    CONSTRAINT fk_badge_genre FOREIGN KEY (genre_id) REFERENCES genre (genre_id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_badge (
+    user_id    INT      NOT NULL,
+    badge_id   INT      NOT NULL,
+    awarded_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, badge_id),
+    CONSTRAINT fk_user_badge_user FOREIGN KEY (user_id) REFERENCES user (user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_badge_badge FOREIGN KEY (badge_id) REFERENCES badge (badge_id) ON DELETE CASCADE
+);
+
+INSERT INTO badge (code, name, description, rule_type, threshold, created_at, updated_at) VALUES
+    ('first_story', 'Cerita Pertama', 'Selesai membaca cerita pertamamu', 'stories_read', 1, UTC_TIMESTAMP(), UTC_TIMESTAMP()),
+    ('bookworm', 'Kutu Buku', 'Selesai membaca 10 cerita', 'stories_read', 10, UTC_TIMESTAMP(), UTC_TIMESTAMP()),
+    ('streak_7', 'Rajin Membaca', 'Membaca 7 hari berturut-turut', 'streak_days', 7, UTC_TIMESTAMP(), UTC_TIMESTAMP());
@@ -0,0 +1,43 @@
+-- Users follow genres and origins to hear about new stories. Following an
+-- origin also covers the origins below it.
+
+CREATE TABLE user_follow (
+    follow_id   INT AUTO_INCREMENT PRIMARY KEY,
+    user_id     INT         NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id   INT         NOT NULL,
+    created_at  DATETIME    NOT NULL,
+    UNIQUE KEY uq_user_follow (user_id, target_type, target_id),
+    KEY idx_user_follow_target (target_type, target_id),
+    CONSTRAINT fk_user_follow_user FOREIGN KEY (user_id) REFERENCES user (user_id) ON DELETE CASCADE
+);
+
+-- In-app inbox. One notification per user, story and kind, so publishing a
+-- story twice does not notify twice.
+CREATE TABLE notification (
+    notification_id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id         INT          NOT NULL,
+    story_id        INT          NULL,
+    kind            VARCHAR(30)  NOT NULL,
+    title           VARCHAR(255) NOT NULL,
+    body            TEXT         NOT NULL,
+    read_at         DATETIME     NULL,
+    created_at      DATETIME     NOT NULL,
+    UNIQUE KEY uq_notification_story (user_id, story_id, kind),
+    KEY idx_notification_inbox (user_id, created_at),
+    CONSTRAINT fk_notification_user FOREIGN KEY (user_id) REFERENCES user (user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_notification_story FOREIGN KEY (story_id) REFERENCES story (story_id) ON DELETE CASCADE
+);
+
+-- Device tokens of the push provider
+CREATE TABLE push_token (
+    token_id   INT AUTO_INCREMENT PRIMARY KEY,
+    user_id    INT          NOT NULL,
+    token      VARCHAR(255) NOT NULL,
+    platform   VARCHAR(20)  NULL,
+    created_at DATETIME     NOT NULL,
+    updated_at DATETIME     NOT NULL,
+    UNIQUE KEY uq_push_token (token),
+    KEY idx_push_token_user (user_id),
+    CONSTRAINT fk_push_token_user FOREIGN KEY (user_id) REFERENCES user (user_id) ON DELETE CASCADE
+);
@@ -0,0 +1,44 @@
+-- Outbound webhooks. Subscriptions list the story events they receive as a
+-- comma-separated list, e.g. "story.created,story.published".
+
+CREATE TABLE webhook (
+    webhook_id  INT AUTO_INCREMENT PRIMARY KEY,
+    url         VARCHAR(500) NOT NULL,
+    secret      VARCHAR(128) NOT NULL,
+    event_types VARCHAR(255) NOT NULL,
+    is_active   TINYINT(1)   NOT NULL DEFAULT 1,
+    created_at  DATETIME     NOT NULL,
+    updated_at  DATETIME     NOT NULL
+);
+
+-- Delivery queue. A delivery is created in the same transaction as the story
+-- change and stays pending until it is delivered or runs out of attempts.
+CREATE TABLE webhook_delivery (
+    delivery_id      INT AUTO_INCREMENT PRIMARY KEY,
+    webhook_id       INT          NOT NULL,
+    event_type       VARCHAR(50)  NOT NULL,
+    payload          TEXT         NOT NULL,
+    status           VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    attempts         INT          NOT NULL DEFAULT 0,
+    next_attempt_at  DATETIME     NOT NULL,
+    last_status_code INT          NULL,
+    last_error       TEXT         NULL,
+    delivered_at     DATETIME     NULL,
+    created_at       DATETIME     NOT NULL,
+    KEY idx_webhook_delivery_due (status, next_attempt_at),
+    KEY idx_webhook_delivery_webhook (webhook_id, created_at),
+    CONSTRAINT fk_webhook_delivery_webhook FOREIGN KEY (webhook_id) REFERENCES webhook (webhook_id) ON DELETE CASCADE
+);
+
+-- Delivery log, one row per attempt
+CREATE TABLE webhook_delivery_attempt (
+    attempt_id   INT AUTO_INCREMENT PRIMARY KEY,
+    delivery_id  INT      NOT NULL,
+    attempt      INT      NOT NULL,
+    status_code  INT      NULL,
+    error        TEXT     NULL,
+    duration_ms  INT      NOT NULL,
+    attempted_at DATETIME NOT NULL,
+    KEY idx_webhook_delivery_attempt (delivery_id, attempt),
+    CONSTRAINT fk_webhook_delivery_attempt_delivery FOREIGN KEY (delivery_id) REFERENCES webhook_delivery (delivery_id) ON DELETE CASCADE
+);
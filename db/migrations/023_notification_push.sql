@@ -0,0 +1,9 @@
+-- Push notifications are sent in the background. A notification waits for the
+-- push dispatcher until pushed_at is set; those already in the inbox count as
+-- pushed so they are not sent again.
+
+ALTER TABLE notification
+    ADD COLUMN pushed_at DATETIME NULL AFTER read_at,
+    ADD KEY idx_notification_push (pushed_at);
+
+UPDATE notification SET pushed_at = created_at;
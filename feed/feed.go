@@ -0,0 +1,258 @@
+package feed
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"kisahloka_be/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var siteURL = "http://localhost:3000"
+
+// FeedInit loads the reader site address from the config
+func FeedInit() {
+	siteURL = strings.TrimRight(config.GetFeedConfig().SITE_URL, "/")
+}
+
+// SiteURL is the address of the reader site, without a trailing slash
+func SiteURL() string {
+	return siteURL
+}
+
+// StoryURL is where readers open a story on the site
+func StoryURL(storyID int) string {
+	return siteURL + "/story/" + strconv.Itoa(storyID)
+}
+
+// Feed is a list of stories rendered as Atom or RSS
+type Feed struct {
+	Title    string
+	Subtitle string
+	Language string
+	// SelfURL is the address the feed was requested from
+	SelfURL string
+	Entries []Entry
+}
+
+// Entry is one story of a feed
+type Entry struct {
+	ID         int
+	Title      string
+	Summary    string
+	ImageURL   string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// Updated is the latest change of any entry, or the zero time for an empty feed
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, entry := range f.Entries {
+		if changed := entry.changed(); changed.After(updated) {
+			updated = changed
+		}
+	}
+	return updated
+}
+
+// ETag identifies the rendered feed by its format, address and entries, so it
+// changes when a story is added, removed or updated
+func (f Feed) ETag(format string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", format, f.SelfURL, f.Language)
+	for _, entry := range f.Entries {
+		fmt.Fprintf(h, "%d:%d:%d\n", entry.ID, entry.Published.UnixNano(), entry.Updated.UnixNano())
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)) + `"`
+}
+
+// changed is the later of the entry's release and update dates. A story
+// released after its last edit, such as a scheduled one, changes the feed when
+// it is released.
+func (e Entry) changed() time.Time {
+	if e.Published.After(e.Updated) {
+		return e.Published
+	}
+	return e.Updated
+}
+
+// content is the HTML shown by feed readers: the thumbnail followed by the synopsis
+func (e Entry) content() string {
+	var b strings.Builder
+	if e.ImageURL != "" {
+		b.WriteString(`<p><img src="`)
+		xml.EscapeText(&b, []byte(e.ImageURL))
+		b.WriteString(`" alt="`)
+		xml.EscapeText(&b, []byte(e.Title))
+		b.WriteString(`"/></p>`)
+	}
+	b.WriteString("<p>")
+	xml.EscapeText(&b, []byte(e.Summary))
+	b.WriteString("</p>")
+	return b.String()
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+}
+
+// Atom renders the feed as an Atom 1.0 document
+func Atom(f Feed) ([]byte, error) {
+	doc := atomFeed{
+		Lang:     f.Language,
+		ID:       f.SelfURL,
+		Title:    f.Title,
+		Subtitle: f.Subtitle,
+		Updated:  f.Updated().UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.SelfURL},
+			{Rel: "alternate", Type: "text/html", Href: siteURL},
+		},
+	}
+
+	for _, entry := range f.Entries {
+		item := atomEntry{
+			ID:        StoryURL(entry.ID),
+			Title:     entry.Title,
+			Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: StoryURL(entry.ID)}},
+			Published: entry.Published.UTC().Format(time.RFC3339),
+			Updated:   entry.changed().UTC().Format(time.RFC3339),
+			Summary:   atomText{Type: "text", Body: entry.Summary},
+			Content:   atomText{Type: "html", Body: entry.content()},
+		}
+		if entry.ImageURL != "" {
+			item.Links = append(item.Links, atomLink{Rel: "enclosure", Href: entry.ImageURL})
+		}
+		for _, category := range entry.Categories {
+			item.Categories = append(item.Categories, atomCategory{Term: category})
+		}
+		doc.Entries = append(doc.Entries, item)
+	}
+
+	return marshal(doc)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	MediaNS string     `xml:"xmlns:media,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	Description string        `xml:"description"`
+	PubDate     string        `xml:"pubDate"`
+	Updated     string        `xml:"atom:updated"`
+	Categories  []string      `xml:"category"`
+	Thumbnail   *rssThumbnail `xml:"media:thumbnail"`
+}
+
+// RSS renders the feed as an RSS 2.0 document. RSS has no update date per item,
+// so it is added as atom:updated.
+func RSS(f Feed) ([]byte, error) {
+	description := f.Subtitle
+	if description == "" {
+		description = f.Title
+	}
+
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		MediaNS: "http://search.yahoo.com/mrss/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          siteURL,
+			Description:   description,
+			Language:      f.Language,
+			LastBuildDate: f.Updated().UTC().Format(time.RFC1123Z),
+			AtomLink:      atomLink{Rel: "self", Type: "application/rss+xml", Href: f.SelfURL},
+		},
+	}
+
+	for _, entry := range f.Entries {
+		item := rssItem{
+			Title:       entry.Title,
+			Link:        StoryURL(entry.ID),
+			GUID:        rssGUID{IsPermaLink: true, Value: StoryURL(entry.ID)},
+			Description: entry.content(),
+			PubDate:     entry.Published.UTC().Format(time.RFC1123Z),
+			Updated:     entry.changed().UTC().Format(time.RFC3339),
+			Categories:  entry.Categories,
+		}
+		if entry.ImageURL != "" {
+			item.Thumbnail = &rssThumbnail{URL: entry.ImageURL}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	return marshal(doc)
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
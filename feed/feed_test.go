@@ -0,0 +1,173 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	return Feed{
+		Title:    "Kisahloka",
+		Subtitle: "Cerita rakyat Nusantara terbaru",
+		Language: "id",
+		SelfURL:  "http://api.test/feed/stories.atom",
+		Entries: []Entry{
+			{
+				ID:         2,
+				Title:      "Malin Kundang",
+				Summary:    "Anak & ibunya",
+				ImageURL:   "http://api.test/media/2.jpg",
+				Categories: []string{"Legenda", "Sumatera Barat"},
+				Published:  published,
+				Updated:    published.Add(-time.Hour),
+			},
+			{
+				ID:        1,
+				Title:     "Timun Mas",
+				Summary:   "Raksasa",
+				Published: published.Add(-48 * time.Hour),
+				Updated:   published.Add(-24 * time.Hour),
+			},
+		},
+	}
+}
+
+func TestFeedUpdated(t *testing.T) {
+	f := testFeed()
+
+	// The first story was released after its last edit
+	if got, want := f.Updated(), f.Entries[0].Published; !got.Equal(want) {
+		t.Errorf("Updated() = %v, want %v", got, want)
+	}
+
+	if got := (Feed{}).Updated(); !got.IsZero() {
+		t.Errorf("Updated() of an empty feed = %v, want zero", got)
+	}
+}
+
+func TestFeedETag(t *testing.T) {
+	f := testFeed()
+	etag := f.ETag("atom")
+
+	if !strings.HasPrefix(etag, `W/"`) || !strings.HasSuffix(etag, `"`) {
+		t.Fatalf("ETag() = %s, want a weak quoted tag", etag)
+	}
+	if got := testFeed().ETag("atom"); got != etag {
+		t.Errorf("ETag() of the same feed = %s, want %s", got, etag)
+	}
+
+	changes := map[string]func(*Feed){
+		"format":    func(f *Feed) {},
+		"language":  func(f *Feed) { f.Language = "en" },
+		"self url":  func(f *Feed) { f.SelfURL += "?limit=5" },
+		"updated":   func(f *Feed) { f.Entries[1].Updated = f.Entries[1].Updated.Add(time.Second) },
+		"published": func(f *Feed) { f.Entries[1].Published = f.Entries[1].Published.Add(time.Second) },
+		"removed":   func(f *Feed) { f.Entries = f.Entries[:1] },
+	}
+	for name, change := range changes {
+		changed := testFeed()
+		change(&changed)
+		format := "atom"
+		if name == "format" {
+			format = "rss"
+		}
+		if changed.ETag(format) == etag {
+			t.Errorf("ETag() did not change with the %s", name)
+		}
+	}
+}
+
+func TestAtom(t *testing.T) {
+	siteURL = "http://site.test"
+
+	body, err := Atom(testFeed())
+	if err != nil {
+		t.Fatalf("Atom() error = %v", err)
+	}
+
+	var doc atomFeed
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("Atom() is not valid XML: %v", err)
+	}
+
+	if doc.Updated != "2026-03-01T08:00:00Z" {
+		t.Errorf("updated = %q, want the latest release", doc.Updated)
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(doc.Entries))
+	}
+
+	entry := doc.Entries[0]
+	if entry.ID != "http://site.test/story/2" {
+		t.Errorf("entry id = %q", entry.ID)
+	}
+	if entry.Updated != "2026-03-01T08:00:00Z" {
+		t.Errorf("entry updated = %q, want the release date", entry.Updated)
+	}
+	if len(entry.Categories) != 2 || entry.Categories[1].Term != "Sumatera Barat" {
+		t.Errorf("entry categories = %v", entry.Categories)
+	}
+	if want := `<p><img src="http://api.test/media/2.jpg" alt="Malin Kundang"/></p><p>Anak &amp; ibunya</p>`; entry.Content.Body != want {
+		t.Errorf("entry content = %q, want %q", entry.Content.Body, want)
+	}
+	if len(entry.Links) != 2 || entry.Links[1].Rel != "enclosure" {
+		t.Errorf("entry links = %v, want alternate and enclosure", entry.Links)
+	}
+	if len(doc.Entries[1].Links) != 1 {
+		t.Errorf("entry without image links = %v, want only alternate", doc.Entries[1].Links)
+	}
+}
+
+func TestRSS(t *testing.T) {
+	siteURL = "http://site.test"
+
+	body, err := RSS(testFeed())
+	if err != nil {
+		t.Fatalf("RSS() error = %v", err)
+	}
+
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Language      string `xml:"language"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				GUID      string `xml:"guid"`
+				PubDate   string `xml:"pubDate"`
+				Thumbnail *struct {
+					URL string `xml:"url,attr"`
+				} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("RSS() is not valid XML: %v", err)
+	}
+
+	if doc.Version != "2.0" || doc.Channel.Language != "id" {
+		t.Errorf("channel = %+v", doc.Channel)
+	}
+	if !strings.Contains(string(body), "<link>http://site.test</link>") {
+		t.Errorf("channel link to the site is missing:\n%s", body)
+	}
+	if doc.Channel.LastBuildDate != "Sun, 01 Mar 2026 08:00:00 +0000" {
+		t.Errorf("lastBuildDate = %q", doc.Channel.LastBuildDate)
+	}
+	if len(doc.Channel.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(doc.Channel.Items))
+	}
+
+	item := doc.Channel.Items[0]
+	if item.GUID != "http://site.test/story/2" {
+		t.Errorf("guid = %q", item.GUID)
+	}
+	if item.Thumbnail == nil || item.Thumbnail.URL != "http://api.test/media/2.jpg" {
+		t.Errorf("thumbnail = %v", item.Thumbnail)
+	}
+	if doc.Channel.Items[1].Thumbnail != nil {
+		t.Errorf("item without image has thumbnail %v", doc.Channel.Items[1].Thumbnail)
+	}
+}
@@ -2,20 +2,25 @@ module kisahloka_be
 
 go 1.22.3
 
+require (
+	github.com/HugoSmits86/nativewebp v1.2.1
+	github.com/go-sql-driver/mysql v1.8.1
+	github.com/labstack/echo/v4 v4.12.0
+	github.com/tkanos/gonfig v0.0.0-20210106201359-53e13348de2f
+	golang.org/x/image v0.24.0
+)
+
 require (
 	filippo.io/edwards25519 v1.1.0 // indirect
 	github.com/ghodss/yaml v1.0.0 // indirect
-	github.com/go-sql-driver/mysql v1.8.1 // indirect
-	github.com/labstack/echo/v4 v4.12.0 // indirect
 	github.com/labstack/gommon v0.4.2 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/tkanos/gonfig v0.0.0-20210106201359-53e13348de2f // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	golang.org/x/crypto v0.22.0 // indirect
 	golang.org/x/net v0.24.0 // indirect
 	golang.org/x/sys v0.19.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -0,0 +1,131 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func solid(width, height int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func decodeBase83(s string) int {
+	value := 0
+	for _, c := range s {
+		value = value*83 + strings.IndexRune(base83Chars, c)
+	}
+	return value
+}
+
+func TestEncodeBase83(t *testing.T) {
+	tests := []struct {
+		value, length int
+		want          string
+	}{
+		{0, 1, "0"},
+		{21, 1, "L"},
+		{82, 1, "~"},
+		{83, 2, "10"},
+		{3429, 2, "fQ"},
+		{0xffffff, 4, "TSUA"},
+	}
+	for _, tt := range tests {
+		if got := encodeBase83(tt.value, tt.length); got != tt.want {
+			t.Errorf("encodeBase83(%d, %d) = %q, want %q", tt.value, tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestBlurhash(t *testing.T) {
+	tests := []struct {
+		name       string
+		img        image.Image
+		x, y       int
+		wantLength int
+		wantDC     int
+	}{
+		{"white 4x3", solid(40, 30, color.White), 4, 3, 28, 0xffffff},
+		{"black 4x3", solid(40, 30, color.Black), 4, 3, 28, 0x000000},
+		{"teal 1x1", solid(10, 10, color.RGBA{0x00, 0x80, 0x80, 0xff}), 1, 1, 6, 0x008080},
+		{"orange 9x9 wide", solid(300, 20, color.RGBA{0xff, 0xa5, 0x00, 0xff}), 9, 9, 166, 0xffa500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := Blurhash(tt.img, tt.x, tt.y)
+			if len(hash) != tt.wantLength {
+				t.Fatalf("len(%q) = %d, want %d", hash, len(hash), tt.wantLength)
+			}
+			if got := decodeBase83(hash[:1]); got != (tt.x-1)+(tt.y-1)*9 {
+				t.Errorf("size flag = %d, want %d", got, (tt.x-1)+(tt.y-1)*9)
+			}
+			// The DC component is the average color, which survives the round trip
+			if got := decodeBase83(hash[2:6]); got != tt.wantDC {
+				t.Errorf("DC = %06x, want %06x", got, tt.wantDC)
+			}
+		})
+	}
+}
+
+func TestBlurhashHorizontalGradient(t *testing.T) {
+	// Black on the left and white on the right gives a negative first
+	// horizontal component, quantised below the neutral value 9
+	img := image.NewRGBA(image.Rect(0, 0, 32, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 32; x++ {
+			if x >= 16 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	hash := Blurhash(img, 2, 1)
+	ac := decodeBase83(hash[6:8])
+	r, g, b := ac/(19*19), ac/19%19, ac%19
+	if r != g || g != b {
+		t.Fatalf("gray image has uneven channels %d, %d, %d", r, g, b)
+	}
+	if r >= 9 {
+		t.Fatalf("first horizontal component = %d, want below 9", r)
+	}
+}
+
+func TestDominantColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			switch {
+			case y < 7:
+				img.Set(x, y, color.RGBA{0x20, 0x60, 0xa0, 0xff})
+			case y < 9:
+				img.Set(x, y, color.RGBA{0xf0, 0x10, 0x10, 0xff})
+			}
+			// The last row stays transparent and is ignored
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want string
+	}{
+		{"largest bucket wins", img, "#2060a0"},
+		{"fully transparent", image.NewRGBA(image.Rect(0, 0, 4, 4)), "#ffffff"},
+		{"solid", solid(100, 50, color.RGBA{0x12, 0x34, 0x56, 0xff}), "#123456"},
+	}
+	for _, tt := range tests {
+		if got := DominantColor(tt.img); got != tt.want {
+			t.Errorf("%s: DominantColor() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
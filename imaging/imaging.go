@@ -0,0 +1,141 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+)
+
+// VariantWidths are the widths, in pixels, uploaded images are resized to. Widths
+// not smaller than the original are skipped.
+var VariantWidths = []int{320, 640, 1024}
+
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+// VariantFormats are the formats every width is encoded in
+var VariantFormats = []string{FormatWebP, FormatJPEG}
+
+// FormatMimeTypes maps the variant formats to their MIME type
+var FormatMimeTypes = map[string]string{
+	FormatJPEG: "image/jpeg",
+	FormatWebP: "image/webp",
+}
+
+// FormatExtensions maps the variant formats to a file extension
+var FormatExtensions = map[string]string{
+	FormatJPEG: ".jpg",
+	FormatWebP: ".webp",
+}
+
+const jpegQuality = 82
+
+// Decode reads a JPEG, PNG or WebP image
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// DecodeConfig reads the format and dimensions of a JPEG, PNG or WebP image
+// without decoding its pixels
+func DecodeConfig(data []byte) (image.Config, string, error) {
+	return image.DecodeConfig(bytes.NewReader(data))
+}
+
+// Resize scales img to the given width, keeping its aspect ratio
+func Resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// Encode writes img in one of the variant formats
+func Encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case FormatJPEG:
+		if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+	case FormatWebP:
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown image format %q", format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// flatten draws img over a white background, since JPEG has no transparency
+func flatten(img image.Image) image.Image {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(bounds)
+	draw.Draw(dst, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, bounds, img, bounds.Min, draw.Over)
+	return dst
+}
+
+// DominantColor returns the most common color of img as "#rrggbb". Colors are
+// grouped into buckets of similar shades and the average of the largest bucket
+// is returned.
+func DominantColor(img image.Image) string {
+	small := img
+	if img.Bounds().Dx() > 64 {
+		small = Resize(img, 64)
+	}
+
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[int]*bucket)
+
+	bounds := small.Bounds()
+	var best *bucket
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(small.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			b := buckets[key]
+			if b == nil {
+				b = &bucket{}
+				buckets[key] = b
+			}
+			b.count++
+			b.r += int(c.R)
+			b.g += int(c.G)
+			b.b += int(c.B)
+
+			if best == nil || b.count > best.count {
+				best = b
+			}
+		}
+	}
+
+	if best == nil {
+		return "#ffffff"
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"kisahloka_be/models"
+	"log"
+	"time"
+)
+
+// pushBatchSize is the most notifications pushed per tick
+const pushBatchSize = 100
+
+// StartPushDispatcher pushes new inbox notifications to the devices of their
+// users, checking every interval. It blocks, so run it in a goroutine.
+func StartPushDispatcher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := models.DeliverPendingPushes(pushBatchSize); err != nil {
+			log.Printf("push dispatcher: %v", err)
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"kisahloka_be/models"
+	"log"
+	"time"
+)
+
+// StartStoryScheduler publishes scheduled stories once their released_date
+// has passed, checking every interval. It blocks, so run it in a goroutine.
+func StartStoryScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := models.PublishScheduledStories()
+		if err != nil {
+			log.Printf("story scheduler: %v", err)
+		} else if len(published) > 0 {
+			log.Printf("story scheduler: published stories %v", published)
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"kisahloka_be/models"
+	"log"
+	"time"
+)
+
+// webhookBatchSize is the most deliveries sent per tick
+const webhookBatchSize = 50
+
+// StartWebhookDispatcher sends queued webhook deliveries that are due, checking
+// every interval. It blocks, so run it in a goroutine.
+func StartWebhookDispatcher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, failed, err := models.DeliverDueWebhooks(webhookBatchSize)
+		if err != nil {
+			log.Printf("webhook dispatcher: %v", err)
+		}
+		if failed > 0 {
+			log.Printf("webhook dispatcher: delivered %d, gave up on %d", delivered, failed)
+		}
+
+		<-ticker.C
+	}
+}
@@ -2,11 +2,27 @@ package main
 
 import (
 	"kisahloka_be/db"
+	"kisahloka_be/feed"
+	"kisahloka_be/jobs"
+	"kisahloka_be/moderation"
+	"kisahloka_be/notify"
 	"kisahloka_be/routes"
+	"kisahloka_be/storage"
+	"kisahloka_be/webhook"
+	"time"
 )
 
 func main() {
 	db.DBInit()
+	storage.StorageInit()
+	moderation.ModerationInit()
+	notify.NotifyInit()
+	webhook.WebhookInit()
+	feed.FeedInit()
+
+	go jobs.StartStoryScheduler(time.Minute)
+	go jobs.StartWebhookDispatcher(15 * time.Second)
+	go jobs.StartPushDispatcher(15 * time.Second)
 
 	e := routes.Init()
 
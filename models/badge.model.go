@@ -0,0 +1,453 @@
+// Badge Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Badge rule types. The stories_* rules count distinct finished stories; the
+// all_of_type rule needs every published story of the type finished.
+const (
+	BadgeRuleStoriesRead       = "stories_read"
+	BadgeRuleStoriesFromOrigin = "stories_from_origin"
+	BadgeRuleStoriesOfType     = "stories_of_type"
+	BadgeRuleStoriesOfGenre    = "stories_of_genre"
+	BadgeRuleAllOfType         = "all_of_type"
+	BadgeRuleStreakDays        = "streak_days"
+)
+
+// ErrInvalidBadge is returned when a badge is missing a field or a parameter its rule needs
+var ErrInvalidBadge = errors.New("invalid badge")
+
+// ErrDuplicateBadge is returned when a badge would take the code of another badge
+var ErrDuplicateBadge = errors.New("another badge has the same code")
+
+// IsValidBadgeRule reports whether ruleType is one the badge evaluator knows
+func IsValidBadgeRule(ruleType string) bool {
+	switch ruleType {
+	case BadgeRuleStoriesRead, BadgeRuleStoriesFromOrigin, BadgeRuleStoriesOfType,
+		BadgeRuleStoriesOfGenre, BadgeRuleAllOfType, BadgeRuleStreakDays:
+		return true
+	}
+	return false
+}
+
+// Badge is an achievement awarded when its rule is met, e.g. rule_type
+// stories_from_origin with origin_id Jawa and threshold 5 for "read 5 stories from Jawa"
+type Badge struct {
+	BadgeID     int       `json:"badge_id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Icon        string    `json:"icon"`
+	RuleType    string    `json:"rule_type"`
+	Threshold   int       `json:"threshold"`
+	OriginID    *int      `json:"origin_id"`
+	TypeID      *int      `json:"type_id"`
+	GenreID     *int      `json:"genre_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BadgeProgress is how far a user is towards a badge
+type BadgeProgress struct {
+	Current int `json:"current"`
+	Target  int `json:"target"`
+}
+
+// UserBadge is a badge as shown to a user, earned or still locked
+type UserBadge struct {
+	Badge
+	AwardedAt *time.Time    `json:"awarded_at"`
+	Progress  BadgeProgress `json:"progress"`
+}
+
+const badgeColumns = "b.badge_id, b.code, b.name, COALESCE(b.description, ''), COALESCE(b.icon, ''), b.rule_type, b.threshold, b.origin_id, b.type_id, b.genre_id, b.created_at, b.updated_at"
+
+func scanBadge(row interface{ Scan(...interface{}) error }) (Badge, error) {
+	var badge Badge
+	var originID, typeID, genreID sql.NullInt64
+	err := row.Scan(&badge.BadgeID, &badge.Code, &badge.Name, &badge.Description, &badge.Icon, &badge.RuleType, &badge.Threshold, &originID, &typeID, &genreID, &badge.CreatedAt, &badge.UpdatedAt)
+	if err != nil {
+		return badge, err
+	}
+
+	if originID.Valid {
+		id := int(originID.Int64)
+		badge.OriginID = &id
+	}
+	if typeID.Valid {
+		id := int(typeID.Int64)
+		badge.TypeID = &id
+	}
+	if genreID.Valid {
+		id := int(genreID.Int64)
+		badge.GenreID = &id
+	}
+
+	return badge, nil
+}
+
+func getAllBadges(ex dbExecutor) ([]Badge, error) {
+	badges := []Badge{}
+
+	rows, err := ex.Query("SELECT " + badgeColumns + " FROM badge b ORDER BY b.badge_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		badge, err := scanBadge(rows)
+		if err != nil {
+			return nil, err
+		}
+		badges = append(badges, badge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return badges, nil
+}
+
+func GetAllBadges() ([]Badge, error) {
+	return getAllBadges(db.CreateCon())
+}
+
+func GetBadgeDetail(badgeID int) (Badge, error) {
+	db := db.CreateCon()
+
+	return scanBadge(db.QueryRow("SELECT "+badgeColumns+" FROM badge b WHERE b.badge_id = ?", badgeID))
+}
+
+// validateBadge checks that a badge has the parameters its rule needs and drops the others
+func validateBadge(badge *Badge) error {
+	if strings.TrimSpace(badge.Code) == "" || strings.TrimSpace(badge.Name) == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalidBadge)
+	}
+	if !IsValidBadgeRule(badge.RuleType) {
+		return fmt.Errorf("%w: unknown rule_type %q", ErrInvalidBadge, badge.RuleType)
+	}
+	if badge.RuleType != BadgeRuleAllOfType && badge.Threshold < 1 {
+		return fmt.Errorf("%w: %s badges need a threshold of at least 1", ErrInvalidBadge, badge.RuleType)
+	}
+
+	switch badge.RuleType {
+	case BadgeRuleStoriesFromOrigin:
+		if badge.OriginID == nil {
+			return fmt.Errorf("%w: %s badges need an origin_id", ErrInvalidBadge, badge.RuleType)
+		}
+	case BadgeRuleStoriesOfType, BadgeRuleAllOfType:
+		if badge.TypeID == nil {
+			return fmt.Errorf("%w: %s badges need a type_id", ErrInvalidBadge, badge.RuleType)
+		}
+	case BadgeRuleStoriesOfGenre:
+		if badge.GenreID == nil {
+			return fmt.Errorf("%w: %s badges need a genre_id", ErrInvalidBadge, badge.RuleType)
+		}
+	}
+
+	if badge.RuleType != BadgeRuleStoriesFromOrigin {
+		badge.OriginID = nil
+	}
+	if badge.RuleType != BadgeRuleStoriesOfType && badge.RuleType != BadgeRuleAllOfType {
+		badge.TypeID = nil
+	}
+	if badge.RuleType != BadgeRuleStoriesOfGenre {
+		badge.GenreID = nil
+	}
+	if badge.RuleType == BadgeRuleAllOfType {
+		badge.Threshold = 0
+	}
+
+	return nil
+}
+
+// checkBadgeCode returns ErrDuplicateBadge when a badge other than badgeID has
+// the code. The locking read also keeps a concurrent insert from taking it.
+func checkBadgeCode(tx *sql.Tx, code string, badgeID int) error {
+	var otherID int
+	err := tx.QueryRow("SELECT badge_id FROM badge WHERE code = ? AND badge_id <> ? FOR UPDATE", code, badgeID).Scan(&otherID)
+	if err == nil {
+		return fmt.Errorf("%w: %q is used by badge %d", ErrDuplicateBadge, code, otherID)
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	return nil
+}
+
+// CreateBadge adds a badge. A code already in use returns ErrDuplicateBadge.
+func CreateBadge(badge Badge) (int64, error) {
+	if err := validateBadge(&badge); err != nil {
+		return 0, err
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkBadgeCode(tx, strings.TrimSpace(badge.Code), 0); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec("INSERT INTO badge (code, name, description, icon, rule_type, threshold, origin_id, type_id, genre_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		strings.TrimSpace(badge.Code), strings.TrimSpace(badge.Name), badge.Description, badge.Icon, badge.RuleType, badge.Threshold,
+		badge.OriginID, badge.TypeID, badge.GenreID, time.Now(), time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateBadge replaces a badge definition. Badges already awarded are kept even
+// when the new rule would not award them.
+func UpdateBadge(badge Badge) (int64, error) {
+	if err := validateBadge(&badge); err != nil {
+		return 0, err
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRow("SELECT badge_id FROM badge WHERE badge_id = ? FOR UPDATE", badge.BadgeID).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if err := checkBadgeCode(tx, strings.TrimSpace(badge.Code), badge.BadgeID); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec("UPDATE badge SET code = ?, name = ?, description = ?, icon = ?, rule_type = ?, threshold = ?, origin_id = ?, type_id = ?, genre_id = ?, updated_at = ? WHERE badge_id = ?",
+		strings.TrimSpace(badge.Code), strings.TrimSpace(badge.Name), badge.Description, badge.Icon, badge.RuleType, badge.Threshold,
+		badge.OriginID, badge.TypeID, badge.GenreID, time.Now(), badge.BadgeID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func DeleteBadge(badgeID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM badge WHERE badge_id = ?", badgeID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// finishedStoriesQuery counts the distinct published stories a user finished;
+// conditions on the story aliased as s are appended by the rules
+const finishedStoriesQuery = "SELECT COUNT(DISTINCT e.story_id) FROM read_event e JOIN story s ON e.story_id = s.story_id WHERE e.user_id = ? AND e.event_type = '" + ReadEventFinish + "' AND " + publishedStoryCondition
+
+// evaluateBadge measures a user's progress towards a badge
+func evaluateBadge(ex dbExecutor, userID int, badge Badge, streak ReadingStreak) (BadgeProgress, error) {
+	progress := BadgeProgress{Target: badge.Threshold}
+
+	var err error
+	switch badge.RuleType {
+	case BadgeRuleStoriesRead:
+		err = ex.QueryRow(finishedStoriesQuery, userID).Scan(&progress.Current)
+	case BadgeRuleStoriesFromOrigin:
+		err = ex.QueryRow(finishedStoriesQuery+" AND s.origin_id IN ("+originDescendantsQuery+")", userID, *badge.OriginID).Scan(&progress.Current)
+	case BadgeRuleStoriesOfType:
+		err = ex.QueryRow(finishedStoriesQuery+" AND s.type_id = ?", userID, *badge.TypeID).Scan(&progress.Current)
+	case BadgeRuleStoriesOfGenre:
+		err = ex.QueryRow(finishedStoriesQuery+" AND EXISTS (SELECT 1 FROM story_genre sg WHERE sg.story_id = s.story_id AND sg.genre_id = ?)", userID, *badge.GenreID).Scan(&progress.Current)
+	case BadgeRuleAllOfType:
+		if err = ex.QueryRow("SELECT COUNT(*) FROM story s WHERE s.type_id = ? AND "+publishedStoryCondition, *badge.TypeID).Scan(&progress.Target); err == nil {
+			err = ex.QueryRow(finishedStoriesQuery+" AND s.type_id = ?", userID, *badge.TypeID).Scan(&progress.Current)
+		}
+	case BadgeRuleStreakDays:
+		progress.Current = streak.LongestStreak
+	default:
+		err = fmt.Errorf("unknown rule_type %q", badge.RuleType)
+	}
+
+	return progress, err
+}
+
+// isBadgeEarned reports whether progress meets the badge. A type without any
+// published story cannot be finished.
+func isBadgeEarned(progress BadgeProgress) bool {
+	return progress.Target > 0 && progress.Current >= progress.Target
+}
+
+// awardBadges evaluates the badges a user does not have yet and awards the ones
+// now met, returning them
+func awardBadges(ex dbExecutor, userID int, streak ReadingStreak) ([]UserBadge, error) {
+	awarded := []UserBadge{}
+
+	rows, err := ex.Query("SELECT "+badgeColumns+" FROM badge b WHERE NOT EXISTS (SELECT 1 FROM user_badge ub WHERE ub.badge_id = b.badge_id AND ub.user_id = ?) ORDER BY b.badge_id", userID)
+	if err != nil {
+		return nil, err
+	}
+	var badges []Badge
+	for rows.Next() {
+		badge, err := scanBadge(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		badges = append(badges, badge)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, badge := range badges {
+		progress, err := evaluateBadge(ex, userID, badge, streak)
+		if err != nil {
+			return nil, err
+		}
+		if !isBadgeEarned(progress) {
+			continue
+		}
+
+		now := time.Now()
+		result, err := ex.Exec("INSERT IGNORE INTO user_badge (user_id, badge_id, awarded_at) VALUES (?, ?, ?)", userID, badge.BadgeID, now)
+		if err != nil {
+			return nil, err
+		}
+
+		// A concurrent event may have awarded the badge since it was listed
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if rowsAffected == 0 {
+			continue
+		}
+
+		awardedAt := now.In(loc)
+		awarded = append(awarded, UserBadge{Badge: badge, AwardedAt: &awardedAt, Progress: progress})
+	}
+
+	return awarded, nil
+}
+
+// GetUserBadges lists the badges a user earned, newest first, and the ones still
+// locked with the user's progress towards them
+func GetUserBadges(userID int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	awardedAt := make(map[int]time.Time)
+	rows, err := con.Query("SELECT badge_id, awarded_at FROM user_badge WHERE user_id = ?", userID)
+	if err != nil {
+		return res, err
+	}
+	for rows.Next() {
+		var badgeID int
+		var at time.Time
+		if err := rows.Scan(&badgeID, &at); err != nil {
+			rows.Close()
+			return res, err
+		}
+		awardedAt[badgeID] = at.In(loc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	badges, err := getAllBadges(con)
+	if err != nil {
+		return res, err
+	}
+
+	streak, err := getReadingStreak(con, userID)
+	if err != nil {
+		return res, err
+	}
+
+	earned := []UserBadge{}
+	locked := []UserBadge{}
+	for _, badge := range badges {
+		progress, err := evaluateBadge(con, userID, badge, streak)
+		if err != nil {
+			return res, err
+		}
+
+		if at, ok := awardedAt[badge.BadgeID]; ok {
+			earned = append(earned, UserBadge{Badge: badge, AwardedAt: &at, Progress: progress})
+		} else {
+			locked = append(locked, UserBadge{Badge: badge, Progress: progress})
+		}
+	}
+
+	// Newest awards first
+	sort.SliceStable(earned, func(i, j int) bool {
+		return earned[i].AwardedAt.After(*earned[j].AwardedAt)
+	})
+
+	res.Data = map[string]interface{}{
+		"earned": earned,
+		"locked": locked,
+		"streak": streak,
+	}
+
+	return res, nil
+}
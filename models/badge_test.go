@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateBadge(t *testing.T) {
+	originID, typeID, genreID := 1, 2, 3
+
+	tests := []struct {
+		name          string
+		badge         Badge
+		valid         bool
+		wantThreshold int
+		wantOrigin    bool
+		wantType      bool
+		wantGenre     bool
+	}{
+		{"stories read", Badge{Code: "reader", Name: "Reader", RuleType: BadgeRuleStoriesRead, Threshold: 10, OriginID: &originID}, true, 10, false, false, false},
+		{"stories from origin", Badge{Code: "jawa", Name: "Jawa", RuleType: BadgeRuleStoriesFromOrigin, Threshold: 5, OriginID: &originID, GenreID: &genreID}, true, 5, true, false, false},
+		{"stories of genre", Badge{Code: "fabel", Name: "Fabel", RuleType: BadgeRuleStoriesOfGenre, Threshold: 3, GenreID: &genreID}, true, 3, false, false, true},
+		{"all of type drops threshold", Badge{Code: "legend", Name: "Legend", RuleType: BadgeRuleAllOfType, Threshold: 4, TypeID: &typeID}, true, 0, false, true, false},
+		{"missing code", Badge{Name: "Reader", RuleType: BadgeRuleStoriesRead, Threshold: 1}, false, 0, false, false, false},
+		{"blank name", Badge{Code: "reader", Name: "  ", RuleType: BadgeRuleStoriesRead, Threshold: 1}, false, 0, false, false, false},
+		{"unknown rule", Badge{Code: "reader", Name: "Reader", RuleType: "stories_liked", Threshold: 1}, false, 0, false, false, false},
+		{"zero threshold", Badge{Code: "streak", Name: "Streak", RuleType: BadgeRuleStreakDays}, false, 0, false, false, false},
+		{"origin rule without origin_id", Badge{Code: "jawa", Name: "Jawa", RuleType: BadgeRuleStoriesFromOrigin, Threshold: 5}, false, 0, false, false, false},
+		{"type rule without type_id", Badge{Code: "legend", Name: "Legend", RuleType: BadgeRuleStoriesOfType, Threshold: 5}, false, 0, false, false, false},
+		{"genre rule without genre_id", Badge{Code: "fabel", Name: "Fabel", RuleType: BadgeRuleStoriesOfGenre, Threshold: 5}, false, 0, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			badge := tt.badge
+			err := validateBadge(&badge)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidBadge) {
+					t.Fatalf("validateBadge() = %v, want ErrInvalidBadge", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateBadge() = %v, want nil", err)
+			}
+			if badge.Threshold != tt.wantThreshold {
+				t.Errorf("Threshold = %d, want %d", badge.Threshold, tt.wantThreshold)
+			}
+			if (badge.OriginID != nil) != tt.wantOrigin || (badge.TypeID != nil) != tt.wantType || (badge.GenreID != nil) != tt.wantGenre {
+				t.Errorf("rule params = origin %v, type %v, genre %v", badge.OriginID, badge.TypeID, badge.GenreID)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+// Character Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCharacter is returned for a character without a name and for
+// character IDs that do not exist
+var ErrInvalidCharacter = errors.New("invalid character")
+
+// Character is a figure appearing in one or more tales, such as Kancil
+type Character struct {
+	CharacterID int       `json:"character_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Image       string    `json:"image"`
+	TotalStory  int       `json:"total_story"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StoryCharacter is a character as shown on a story
+type StoryCharacter struct {
+	CharacterID int    `json:"character_id"`
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+}
+
+// characterColumns selects a character with the number of published stories it appears in
+const characterColumns = "c.character_id, c.name, COALESCE(c.description, ''), COALESCE(c.image, ''), (SELECT COUNT(*) FROM story_character sc JOIN story s ON sc.story_id = s.story_id WHERE sc.character_id = c.character_id AND " + publishedStoryCondition + "), c.created_at, c.updated_at"
+
+func scanCharacter(row interface{ Scan(...interface{}) error }) (Character, error) {
+	var character Character
+	err := row.Scan(&character.CharacterID, &character.Name, &character.Description, &character.Image, &character.TotalStory, &character.CreatedAt, &character.UpdatedAt)
+	return character, err
+}
+
+// GetAllCharacters lists characters, optionally only those whose name contains keyword
+func GetAllCharacters(keyword string) ([]Character, error) {
+	characters := []Character{}
+
+	db := db.CreateCon()
+
+	sqlStatement := "SELECT " + characterColumns + " FROM `character` c"
+	args := []interface{}{}
+	if keyword != "" {
+		sqlStatement += " WHERE c.name LIKE ?"
+		args = append(args, "%"+keyword+"%")
+	}
+	sqlStatement += " ORDER BY c.name"
+
+	rows, err := db.Query(sqlStatement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		character, err := scanCharacter(rows)
+		if err != nil {
+			return nil, err
+		}
+		characters = append(characters, character)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return characters, nil
+}
+
+func GetCharacterDetail(characterID int) (Character, error) {
+	db := db.CreateCon()
+
+	character, err := scanCharacter(db.QueryRow("SELECT "+characterColumns+" FROM `character` c WHERE c.character_id = ?", characterID))
+	if err != nil {
+		return Character{}, err
+	}
+
+	return character, nil
+}
+
+func validateCharacter(character Character) error {
+	if strings.TrimSpace(character.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCharacter)
+	}
+	return nil
+}
+
+func CreateCharacter(character Character) (int64, error) {
+	if err := validateCharacter(character); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	result, err := db.Exec("INSERT INTO `character` (name, description, image, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		strings.TrimSpace(character.Name), character.Description, character.Image, time.Now(), time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func UpdateCharacter(character Character) (int64, error) {
+	if err := validateCharacter(character); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	result, err := db.Exec("UPDATE `character` SET name = ?, description = ?, image = ?, updated_at = ? WHERE character_id = ?",
+		strings.TrimSpace(character.Name), character.Description, character.Image, time.Now(), character.CharacterID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func DeleteCharacter(characterID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM `character` WHERE character_id = ?", characterID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// SaveCharacterImage uploads a picture of a character and sets it as its image
+func SaveCharacterImage(characterID int, file io.Reader) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	var exists int
+	if err := con.QueryRow("SELECT COUNT(*) FROM `character` WHERE character_id = ?", characterID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	media, err := storeImage("images/characters", file)
+	if err != nil {
+		return res, err
+	}
+
+	if _, err := con.Exec("UPDATE `character` SET image = ?, updated_at = ? WHERE character_id = ?", media.URL, time.Now(), characterID); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"character_id": characterID,
+		"media":        media,
+	}
+
+	return res, nil
+}
+
+// SetStoryCharacters replaces the characters appearing in a story
+func SetStoryCharacters(storyID int, characterIDs []int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM story WHERE story_id = ?", storyID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	uniqueIDs := []int{}
+	seen := make(map[int]bool)
+	for _, characterID := range characterIDs {
+		if characterID <= 0 {
+			return res, fmt.Errorf("%w: character_id %d", ErrInvalidCharacter, characterID)
+		}
+		if !seen[characterID] {
+			seen[characterID] = true
+			uniqueIDs = append(uniqueIDs, characterID)
+		}
+	}
+
+	if len(uniqueIDs) > 0 {
+		placeholders := make([]string, len(uniqueIDs))
+		args := make([]interface{}, len(uniqueIDs))
+		for i, characterID := range uniqueIDs {
+			placeholders[i] = "?"
+			args[i] = characterID
+		}
+
+		var found int
+		err := tx.QueryRow("SELECT COUNT(*) FROM `character` WHERE character_id IN ("+strings.Join(placeholders, ", ")+")", args...).Scan(&found)
+		if err != nil {
+			return res, err
+		}
+		if found != len(uniqueIDs) {
+			return res, fmt.Errorf("%w: character_ids %v include one that does not exist", ErrInvalidCharacter, uniqueIDs)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM story_character WHERE story_id = ?", storyID); err != nil {
+		return res, err
+	}
+
+	for _, characterID := range uniqueIDs {
+		if _, err := tx.Exec("INSERT INTO story_character (story_id, character_id) VALUES (?, ?)", storyID, characterID); err != nil {
+			return res, err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE story SET updated_at = ? WHERE story_id = ?", time.Now(), storyID); err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+		return res, err
+	}
+
+	characters, err := getStoryCharacters(tx, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id":   storyID,
+		"characters": characters,
+	}
+
+	return res, nil
+}
+
+// GetCharacterStories lists the published stories a character appears in
+func GetCharacterStories(characterID, page, pageSize int, locale string) (Response, error) {
+	if _, err := GetCharacterDetail(characterID); err != nil {
+		return Response{}, err
+	}
+
+	return GetAllStoriesPreview(page, pageSize, StoryPreviewFilter{CharacterID: characterID}, locale)
+}
+
+// getStoryCharacters lists the characters appearing in a story
+func getStoryCharacters(ex dbExecutor, storyID int) ([]StoryCharacter, error) {
+	characters := []StoryCharacter{}
+
+	rows, err := ex.Query("SELECT c.character_id, c.name, COALESCE(c.image, '') FROM story_character sc JOIN `character` c ON sc.character_id = c.character_id WHERE sc.story_id = ? ORDER BY c.name", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var character StoryCharacter
+		if err := rows.Scan(&character.CharacterID, &character.Name, &character.Image); err != nil {
+			return nil, err
+		}
+		characters = append(characters, character)
+	}
+
+	return characters, rows.Err()
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateCharacter(t *testing.T) {
+	tests := []struct {
+		name      string
+		character Character
+		valid     bool
+	}{
+		{"named", Character{Name: "Kancil"}, true},
+		{"empty name", Character{}, false},
+		{"blank name", Character{Name: "  "}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCharacter(tt.character)
+			if tt.valid && err != nil {
+				t.Fatalf("validateCharacter() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidCharacter) {
+				t.Fatalf("validateCharacter() = %v, want ErrInvalidCharacter", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+// Collection Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCollection is returned for a collection without a title, with an
+// empty visibility window or with story IDs that do not exist
+var ErrInvalidCollection = errors.New("invalid collection")
+
+// Collection is an editor-curated, ordered list of stories. It is public only
+// between VisibleFrom and VisibleUntil; a nil date leaves that side open.
+type Collection struct {
+	CollectionID int        `json:"collection_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	CoverImage   string     `json:"cover_image"`
+	VisibleFrom  *time.Time `json:"visible_from"`
+	VisibleUntil *time.Time `json:"visible_until"`
+	IsVisible    bool       `json:"is_visible"`
+	TotalStory   int        `json:"total_story"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// collectionVisibleCondition matches collections inside their visibility window
+const collectionVisibleCondition = "(c.visible_from IS NULL OR c.visible_from <= UTC_TIMESTAMP()) AND (c.visible_until IS NULL OR c.visible_until > UTC_TIMESTAMP())"
+
+// collectionColumns selects a collection with its visibility and the number of
+// published stories in it
+const collectionColumns = "c.collection_id, c.title, COALESCE(c.description, ''), COALESCE(c.cover_image, ''), c.visible_from, c.visible_until, " + collectionVisibleCondition + ", (SELECT COUNT(*) FROM collection_story cs JOIN story s ON cs.story_id = s.story_id WHERE cs.collection_id = c.collection_id AND " + publishedStoryCondition + "), c.created_at, c.updated_at"
+
+func scanCollection(row interface{ Scan(...interface{}) error }, loc *time.Location) (Collection, error) {
+	var collection Collection
+	var visibleFrom, visibleUntil sql.NullTime
+	err := row.Scan(
+		&collection.CollectionID,
+		&collection.Title,
+		&collection.Description,
+		&collection.CoverImage,
+		&visibleFrom,
+		&visibleUntil,
+		&collection.IsVisible,
+		&collection.TotalStory,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+	)
+	if err != nil {
+		return collection, err
+	}
+
+	// Convert time fields to UTC+8 (Asia/Shanghai) before including them in the response
+	if visibleFrom.Valid {
+		t := visibleFrom.Time.In(loc)
+		collection.VisibleFrom = &t
+	}
+	if visibleUntil.Valid {
+		t := visibleUntil.Time.In(loc)
+		collection.VisibleUntil = &t
+	}
+	collection.CreatedAt = collection.CreatedAt.In(loc)
+	collection.UpdatedAt = collection.UpdatedAt.In(loc)
+
+	return collection, nil
+}
+
+// GetAllCollections lists collections newest first. Hidden collections, those
+// outside their visibility window, are only included when includeHidden is set.
+func GetAllCollections(page, pageSize int, includeHidden bool) (Response, error) {
+	var res Response
+	var meta Meta
+	collections := []Collection{}
+
+	con := db.CreateCon()
+
+	whereClause := ""
+	if !includeHidden {
+		whereClause = " WHERE " + collectionVisibleCondition
+	}
+
+	// Count total items in the database
+	var totalItems int
+	err := con.QueryRow("SELECT COUNT(*) FROM collection c" + whereClause).Scan(&totalItems)
+	if err != nil {
+		return res, err
+	}
+
+	meta.Limit = pageSize
+	meta.Page = page
+	meta.TotalPages = calculateTotalPages(totalItems, pageSize)
+	meta.TotalItems = totalItems
+
+	// If no items are found, return an empty response data object
+	if totalItems == 0 {
+		res.Data = map[string]interface{}{
+			"collections": collections,
+			"meta":        meta,
+		}
+
+		return res, nil
+	}
+
+	// Check if the requested page is greater than the total number of pages
+	if page > meta.TotalPages {
+		return res, fmt.Errorf("requested page (%d) exceeds total number of pages (%d)", page, meta.TotalPages)
+	}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	// Calculate the offset based on the page number and page size
+	offset := (page - 1) * pageSize
+
+	rows, err := con.Query("SELECT "+collectionColumns+" FROM collection c"+whereClause+" ORDER BY c.created_at DESC, c.collection_id DESC LIMIT ? OFFSET ?", pageSize, offset)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		collection, err := scanCollection(rows, loc)
+		if err != nil {
+			return res, err
+		}
+		collections = append(collections, collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"collections": collections,
+		"meta":        meta,
+	}
+
+	return res, nil
+}
+
+// GetCollectionDetail returns a collection with a page of its published stories
+// in collection order. A hidden collection is reported as sql.ErrNoRows unless
+// includeHidden is set.
+func GetCollectionDetail(collectionID, page, pageSize int, includeHidden bool, locale string) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	collection, err := scanCollection(con.QueryRow("SELECT "+collectionColumns+" FROM collection c WHERE c.collection_id = ?", collectionID), loc)
+	if err != nil {
+		return res, err
+	}
+	if !collection.IsVisible && !includeHidden {
+		return res, sql.ErrNoRows
+	}
+
+	// Reuse the story listing so collection entries look like any other preview
+	stories, err := GetAllStoriesPreview(page, pageSize, StoryPreviewFilter{CollectionID: collectionID}, locale)
+	if err != nil {
+		return res, err
+	}
+	listing := stories.Data.(map[string]interface{})
+
+	res.Data = map[string]interface{}{
+		"collection": collection,
+		"stories":    listing["stories"],
+		"meta":       listing["meta"],
+	}
+
+	return res, nil
+}
+
+func validateCollection(collection Collection) error {
+	if strings.TrimSpace(collection.Title) == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidCollection)
+	}
+	if collection.VisibleFrom != nil && collection.VisibleUntil != nil && !collection.VisibleUntil.After(*collection.VisibleFrom) {
+		return fmt.Errorf("%w: visible_until must be after visible_from", ErrInvalidCollection)
+	}
+	return nil
+}
+
+// nullableTime stores a nil time as NULL and everything else in UTC
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func CreateCollection(collection Collection) (int64, error) {
+	if err := validateCollection(collection); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	result, err := db.Exec("INSERT INTO collection (title, description, cover_image, visible_from, visible_until, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		strings.TrimSpace(collection.Title), collection.Description, collection.CoverImage, nullableTime(collection.VisibleFrom), nullableTime(collection.VisibleUntil), time.Now(), time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateCollection replaces the fields of a collection; its stories are set
+// with SetCollectionStories
+func UpdateCollection(collection Collection) (int64, error) {
+	if err := validateCollection(collection); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	result, err := db.Exec("UPDATE collection SET title = ?, description = ?, cover_image = ?, visible_from = ?, visible_until = ?, updated_at = ? WHERE collection_id = ?",
+		strings.TrimSpace(collection.Title), collection.Description, collection.CoverImage, nullableTime(collection.VisibleFrom), nullableTime(collection.VisibleUntil), time.Now(), collection.CollectionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// MySQL reports no affected rows for an update that changes nothing, so
+	// tell an unknown collection apart from an unchanged one
+	if rowsAffected == 0 {
+		var exists int
+		if err := db.QueryRow("SELECT 1 FROM collection WHERE collection_id = ?", collection.CollectionID).Scan(&exists); err != nil {
+			return 0, err
+		}
+	}
+
+	return rowsAffected, nil
+}
+
+func DeleteCollection(collectionID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM collection WHERE collection_id = ?", collectionID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// SaveCollectionCover uploads a cover picture and sets it on the collection
+func SaveCollectionCover(collectionID int, file io.Reader) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	var exists int
+	if err := con.QueryRow("SELECT COUNT(*) FROM collection WHERE collection_id = ?", collectionID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	media, err := storeImage("images/collections", file)
+	if err != nil {
+		return res, err
+	}
+
+	if _, err := con.Exec("UPDATE collection SET cover_image = ?, updated_at = ? WHERE collection_id = ?", media.URL, time.Now(), collectionID); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"collection_id": collectionID,
+		"media":         media,
+	}
+
+	return res, nil
+}
+
+// SetCollectionStories replaces the stories of a collection. The order of
+// storyIDs is the order readers see them in.
+func SetCollectionStories(collectionID int, storyIDs []int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM collection WHERE collection_id = ?", collectionID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	ordered := []int{}
+	seen := make(map[int]bool)
+	for _, storyID := range storyIDs {
+		if storyID <= 0 {
+			return res, fmt.Errorf("%w: story_id %d", ErrInvalidCollection, storyID)
+		}
+		if !seen[storyID] {
+			seen[storyID] = true
+			ordered = append(ordered, storyID)
+		}
+	}
+
+	if len(ordered) > 0 {
+		placeholders := make([]string, len(ordered))
+		args := make([]interface{}, len(ordered))
+		for i, storyID := range ordered {
+			placeholders[i] = "?"
+			args[i] = storyID
+		}
+
+		var found int
+		err := tx.QueryRow("SELECT COUNT(*) FROM story WHERE story_id IN ("+strings.Join(placeholders, ", ")+")", args...).Scan(&found)
+		if err != nil {
+			return res, err
+		}
+		if found != len(ordered) {
+			return res, fmt.Errorf("%w: story_ids %v include one that does not exist", ErrInvalidCollection, ordered)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM collection_story WHERE collection_id = ?", collectionID); err != nil {
+		return res, err
+	}
+
+	for i, storyID := range ordered {
+		if _, err := tx.Exec("INSERT INTO collection_story (collection_id, story_id, position) VALUES (?, ?, ?)", collectionID, storyID, i+1); err != nil {
+			return res, err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE collection SET updated_at = ? WHERE collection_id = ?", time.Now(), collectionID); err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"collection_id": collectionID,
+		"story_ids":     ordered,
+	}
+
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateCollection(t *testing.T) {
+	from := time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(30 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		collection Collection
+		valid      bool
+	}{
+		{"open window", Collection{Title: "Kisah Kemerdekaan"}, true},
+		{"only from", Collection{Title: "Kisah Kemerdekaan", VisibleFrom: &from}, true},
+		{"only until", Collection{Title: "Kisah Kemerdekaan", VisibleUntil: &until}, true},
+		{"closed window", Collection{Title: "Kisah Kemerdekaan", VisibleFrom: &from, VisibleUntil: &until}, true},
+		{"missing title", Collection{Title: " "}, false},
+		{"until before from", Collection{Title: "Kisah Kemerdekaan", VisibleFrom: &until, VisibleUntil: &from}, false},
+		{"empty window", Collection{Title: "Kisah Kemerdekaan", VisibleFrom: &from, VisibleUntil: &from}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCollection(tt.collection)
+			if tt.valid && err != nil {
+				t.Fatalf("validateCollection() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidCollection) {
+				t.Fatalf("validateCollection() = %v, want ErrInvalidCollection", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Feed Model
+
+package models
+
+import (
+	"kisahloka_be/db"
+	"time"
+)
+
+// FeedStory is a published story as listed in the RSS and Atom feeds
+type FeedStory struct {
+	StoryID        int
+	Title          string
+	Synopsis       string
+	ThumbnailImage string
+	TypeID         int
+	TypeName       string
+	OriginID       int
+	OriginName     string
+	ReleasedDate   time.Time
+	UpdatedAt      time.Time
+}
+
+// FeedScope limits a feed to the stories of a genre or of an origin and the
+// origins below it. The zero scope lists all stories.
+type FeedScope struct {
+	GenreID  int
+	OriginID int
+}
+
+// GetFeedScopeName returns the name of the genre or origin a feed is limited
+// to. A missing genre or origin gives sql.ErrNoRows.
+func GetFeedScopeName(scope FeedScope, locale string) (string, error) {
+	db := db.CreateCon()
+
+	var taxonomy, name string
+	var taxonomyID int
+	var err error
+	switch {
+	case scope.GenreID > 0:
+		taxonomy, taxonomyID = TaxonomyGenre, scope.GenreID
+		err = db.QueryRow("SELECT genre_name FROM genre WHERE genre_id = ?", scope.GenreID).Scan(&name)
+	case scope.OriginID > 0:
+		taxonomy, taxonomyID = TaxonomyOrigin, scope.OriginID
+		err = db.QueryRow("SELECT origin_name FROM origin WHERE origin_id = ?", scope.OriginID).Scan(&name)
+	default:
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if l := newLocalizer(locale); l != nil {
+		return l.taxonomyName(taxonomy, taxonomyID, name)
+	}
+
+	return name, nil
+}
+
+// GetFeedStories lists the most recently released stories visible to readers
+func GetFeedStories(scope FeedScope, limit int, locale string) ([]FeedStory, error) {
+	stories := []FeedStory{}
+
+	con := db.CreateCon()
+
+	condition := publishedStoryCondition
+	var args []interface{}
+	if scope.GenreID > 0 {
+		condition += " AND EXISTS (SELECT 1 FROM story_genre sg WHERE sg.story_id = s.story_id AND sg.genre_id = ?)"
+		args = append(args, scope.GenreID)
+	}
+	if scope.OriginID > 0 {
+		condition += " AND s.origin_id IN (" + originDescendantsQuery + ")"
+		args = append(args, scope.OriginID)
+	}
+	args = append(args, limit)
+
+	rows, err := con.Query(`
+		SELECT s.story_id, s.title, COALESCE(s.synopsis, ''), COALESCE(s.thumbnail_image, ''), s.type_id, t.type_name, s.origin_id, o.origin_name, s.released_date, s.updated_at
+		FROM story s
+		JOIN type t ON s.type_id = t.type_id
+		JOIN origin o ON s.origin_id = o.origin_id
+		WHERE `+condition+`
+		ORDER BY s.released_date DESC, s.story_id DESC
+		LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var story FeedStory
+		if err := rows.Scan(&story.StoryID, &story.Title, &story.Synopsis, &story.ThumbnailImage, &story.TypeID, &story.TypeName, &story.OriginID, &story.OriginName, &story.ReleasedDate, &story.UpdatedAt); err != nil {
+			return nil, err
+		}
+		stories = append(stories, story)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	l := newLocalizer(locale)
+	if l == nil || len(stories) == 0 {
+		return stories, nil
+	}
+
+	storyIDs := make([]int, len(stories))
+	for i, story := range stories {
+		storyIDs[i] = story.StoryID
+	}
+	titles, err := l.storyTitles(storyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stories {
+		if translation, ok := titles[stories[i].StoryID]; ok {
+			stories[i].Title = translation.Title
+			if translation.Synopsis != "" {
+				stories[i].Synopsis = translation.Synopsis
+			}
+		}
+		if stories[i].TypeName, err = l.taxonomyName(TaxonomyType, stories[i].TypeID, stories[i].TypeName); err != nil {
+			return nil, err
+		}
+		if stories[i].OriginName, err = l.taxonomyName(TaxonomyOrigin, stories[i].OriginID, stories[i].OriginName); err != nil {
+			return nil, err
+		}
+	}
+
+	return stories, nil
+}
@@ -0,0 +1,119 @@
+// Follow Model
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"kisahloka_be/db"
+	"time"
+)
+
+const (
+	FollowTargetGenre  = "genre"
+	FollowTargetOrigin = "origin"
+)
+
+// IsValidFollowTarget reports whether targetType is something users can follow
+func IsValidFollowTarget(targetType string) bool {
+	return targetType == FollowTargetGenre || targetType == FollowTargetOrigin
+}
+
+// Follow is a user following a genre or an origin
+type Follow struct {
+	FollowID   int       `json:"follow_id"`
+	UserID     int       `json:"user_id"`
+	TargetType string    `json:"target_type"`
+	TargetID   int       `json:"target_id"`
+	TargetName string    `json:"target_name"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// GetUserFollows lists what a user follows, genres first
+func GetUserFollows(userID int) ([]Follow, error) {
+	follows := []Follow{}
+
+	db := db.CreateCon()
+
+	rows, err := db.Query(`
+		SELECT f.follow_id, f.user_id, f.target_type, f.target_id, COALESCE(g.genre_name, o.origin_name, ''), f.created_at
+		FROM user_follow f
+		LEFT JOIN genre g ON f.target_type = ? AND f.target_id = g.genre_id
+		LEFT JOIN origin o ON f.target_type = ? AND f.target_id = o.origin_id
+		WHERE f.user_id = ?
+		ORDER BY f.target_type DESC, f.created_at`,
+		FollowTargetGenre, FollowTargetOrigin, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var follow Follow
+		if err := rows.Scan(&follow.FollowID, &follow.UserID, &follow.TargetType, &follow.TargetID, &follow.TargetName, &follow.CreatedAt); err != nil {
+			return nil, err
+		}
+		follow.CreatedAt = follow.CreatedAt.In(loc)
+		follows = append(follows, follow)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return follows, nil
+}
+
+// CreateFollow makes a user follow a genre or origin, returning sql.ErrNoRows
+// when the target does not exist. Following twice is not an error.
+func CreateFollow(userID int, targetType string, targetID int) (int64, error) {
+	if !IsValidFollowTarget(targetType) {
+		return 0, fmt.Errorf("invalid target_type %q", targetType)
+	}
+
+	db := db.CreateCon()
+
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM "+targetType+" WHERE "+targetType+"_id = ?", targetID).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	_, err := db.Exec("INSERT IGNORE INTO user_follow (user_id, target_type, target_id, created_at) VALUES (?, ?, ?, ?)", userID, targetType, targetID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	var followID int64
+	if err := db.QueryRow("SELECT follow_id FROM user_follow WHERE user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).Scan(&followID); err != nil {
+		return 0, err
+	}
+
+	return followID, nil
+}
+
+// DeleteFollow unfollows on behalf of a user; follows of other users are left alone
+func DeleteFollow(followID, userID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM user_follow WHERE follow_id = ? AND user_id = ?", followID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
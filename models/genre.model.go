@@ -12,7 +12,8 @@ type Genre struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-func GetAllGenres() ([]Genre, error) {
+// GetAllGenres lists the genres with their names in the given locale, if any
+func GetAllGenres(locale string) ([]Genre, error) {
 	var genres []Genre
 
 	db := db.CreateCon()
@@ -23,12 +24,19 @@ func GetAllGenres() ([]Genre, error) {
 	}
 	defer rows.Close()
 
+	l := newLocalizer(locale)
+
 	for rows.Next() {
 		var genre Genre
 		err := rows.Scan(&genre.GenreID, &genre.GenreName, &genre.CreatedAt, &genre.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
+		if l != nil {
+			if genre.GenreName, err = l.taxonomyName(TaxonomyGenre, genre.GenreID, genre.GenreName); err != nil {
+				return nil, err
+			}
+		}
 		genres = append(genres, genre)
 	}
 
@@ -0,0 +1,396 @@
+// Glossary Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+)
+
+// ErrInvalidGlossary is returned for a glossary term with missing fields or an
+// unsupported locale
+var ErrInvalidGlossary = errors.New("invalid glossary term")
+
+// ErrInvalidAnnotation is returned for an annotation whose term or span does not
+// fit the page text
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+// Glossary is a vocabulary term in one language with its definition
+type Glossary struct {
+	GlossaryID    int       `json:"glossary_id"`
+	Term          string    `json:"term"`
+	Locale        string    `json:"locale"`
+	Definition    string    `json:"definition"`
+	Pronunciation *string   `json:"pronunciation"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// StoryContentAnnotation links a span of a page's text to a glossary term.
+// CharStart and CharEnd are rune offsets into the page text of the locale.
+// IsStale is set when the page text was edited and the span no longer holds Text.
+type StoryContentAnnotation struct {
+	AnnotationID  int     `json:"annotation_id"`
+	StoryID       int     `json:"story_id"`
+	Order         int     `json:"order"`
+	Locale        string  `json:"locale"`
+	GlossaryID    int     `json:"glossary_id"`
+	CharStart     int     `json:"char_start"`
+	CharEnd       int     `json:"char_end"`
+	Text          string  `json:"text"`
+	Term          string  `json:"term"`
+	Definition    string  `json:"definition"`
+	Pronunciation *string `json:"pronunciation"`
+	IsStale       bool    `json:"is_stale"`
+}
+
+// StoryGlossaryEntry is a glossary term used in a story with the pages it appears on
+type StoryGlossaryEntry struct {
+	Glossary
+	Pages []int `json:"pages"`
+}
+
+func validateGlossary(glossary Glossary) error {
+	if strings.TrimSpace(glossary.Term) == "" {
+		return fmt.Errorf("%w: term is required", ErrInvalidGlossary)
+	}
+	if strings.TrimSpace(glossary.Definition) == "" {
+		return fmt.Errorf("%w: definition is required", ErrInvalidGlossary)
+	}
+	if !IsSupportedLocale(glossary.Locale) {
+		return fmt.Errorf("%w: unsupported locale %q", ErrInvalidGlossary, glossary.Locale)
+	}
+	return nil
+}
+
+// GetAllGlossary lists glossary terms, optionally filtered by a keyword in the term
+// and by locale
+func GetAllGlossary(keyword, locale string) ([]Glossary, error) {
+	glossaries := []Glossary{}
+
+	db := db.CreateCon()
+
+	conditions := []string{}
+	args := []interface{}{}
+	if keyword != "" {
+		conditions = append(conditions, "term LIKE ?")
+		args = append(args, "%"+keyword+"%")
+	}
+	if locale != "" {
+		conditions = append(conditions, "locale = ?")
+		args = append(args, locale)
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := db.Query("SELECT glossary_id, term, locale, definition, pronunciation, created_at, updated_at FROM glossary "+whereClause+" ORDER BY term", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var glossary Glossary
+		err := rows.Scan(&glossary.GlossaryID, &glossary.Term, &glossary.Locale, &glossary.Definition, &glossary.Pronunciation, &glossary.CreatedAt, &glossary.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		glossaries = append(glossaries, glossary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return glossaries, nil
+}
+
+func GetGlossaryDetail(glossaryID int) (Glossary, error) {
+	var glossary Glossary
+
+	db := db.CreateCon()
+
+	err := db.QueryRow("SELECT glossary_id, term, locale, definition, pronunciation, created_at, updated_at FROM glossary WHERE glossary_id = ?", glossaryID).Scan(
+		&glossary.GlossaryID, &glossary.Term, &glossary.Locale, &glossary.Definition, &glossary.Pronunciation, &glossary.CreatedAt, &glossary.UpdatedAt,
+	)
+	if err != nil {
+		return Glossary{}, err
+	}
+
+	return glossary, nil
+}
+
+func CreateGlossary(glossary Glossary) (int64, error) {
+	if err := validateGlossary(glossary); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	result, err := db.Exec("INSERT INTO glossary (term, locale, definition, pronunciation, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		strings.TrimSpace(glossary.Term), glossary.Locale, glossary.Definition, glossary.Pronunciation, time.Now(), time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func UpdateGlossary(glossary Glossary) (int64, error) {
+	if err := validateGlossary(glossary); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	result, err := db.Exec("UPDATE glossary SET term = ?, locale = ?, definition = ?, pronunciation = ?, updated_at = ? WHERE glossary_id = ?",
+		strings.TrimSpace(glossary.Term), glossary.Locale, glossary.Definition, glossary.Pronunciation, time.Now(), glossary.GlossaryID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func DeleteGlossary(glossaryID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM glossary WHERE glossary_id = ?", glossaryID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// SaveStoryContentAnnotation links a word span of one page to a glossary term in
+// the same language. The span may not overlap another annotation of the page.
+func SaveStoryContentAnnotation(annotation StoryContentAnnotation) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	glossary, err := GetGlossaryDetail(annotation.GlossaryID)
+	if err == sql.ErrNoRows {
+		return res, fmt.Errorf("%w: glossary %d does not exist", ErrInvalidAnnotation, annotation.GlossaryID)
+	}
+	if err != nil {
+		return res, err
+	}
+	if glossary.Locale != annotation.Locale {
+		return res, fmt.Errorf("%w: glossary %d is a %q term and cannot annotate %q text", ErrInvalidAnnotation, glossary.GlossaryID, glossary.Locale, annotation.Locale)
+	}
+
+	var pageText string
+	err = con.QueryRow("SELECT content FROM story_content_translation WHERE story_id = ? AND `order` = ? AND locale = ?", annotation.StoryID, annotation.Order, annotation.Locale).Scan(&pageText)
+	if err != nil {
+		// sql.ErrNoRows when the page has no text in the locale
+		return res, err
+	}
+
+	annotation.Text, err = annotationSpanText(pageText, annotation.CharStart, annotation.CharEnd)
+	if err != nil {
+		return res, err
+	}
+
+	var overlapping int
+	err = con.QueryRow(
+		"SELECT COUNT(*) FROM story_content_annotation WHERE story_id = ? AND `order` = ? AND locale = ? AND char_start < ? AND char_end > ?",
+		annotation.StoryID, annotation.Order, annotation.Locale, annotation.CharEnd, annotation.CharStart,
+	).Scan(&overlapping)
+	if err != nil {
+		return res, err
+	}
+	if overlapping > 0 {
+		return res, fmt.Errorf("%w: span %d-%d overlaps another annotation", ErrInvalidAnnotation, annotation.CharStart, annotation.CharEnd)
+	}
+
+	result, err := con.Exec(
+		"INSERT INTO story_content_annotation (story_id, `order`, locale, glossary_id, char_start, char_end, text, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		annotation.StoryID, annotation.Order, annotation.Locale, annotation.GlossaryID, annotation.CharStart, annotation.CharEnd, annotation.Text, time.Now(),
+	)
+	if err != nil {
+		return res, err
+	}
+
+	annotationID, err := result.LastInsertId()
+	if err != nil {
+		return res, err
+	}
+
+	annotation.AnnotationID = int(annotationID)
+	annotation.Term = glossary.Term
+	annotation.Definition = glossary.Definition
+	annotation.Pronunciation = glossary.Pronunciation
+
+	res.Data = map[string]interface{}{
+		"annotation": annotation,
+	}
+
+	return res, nil
+}
+
+// annotationSpanText returns the text between the rune offsets start and end of
+// a page, which must be a non-empty span without surrounding whitespace
+func annotationSpanText(pageText string, start, end int) (string, error) {
+	runes := []rune(pageText)
+	if start < 0 || end <= start || end > len(runes) {
+		return "", fmt.Errorf("%w: span %d-%d is outside the page text of %d characters", ErrInvalidAnnotation, start, end, len(runes))
+	}
+	text := string(runes[start:end])
+	if strings.TrimSpace(text) != text {
+		return "", fmt.Errorf("%w: span %q starts or ends with whitespace", ErrInvalidAnnotation, text)
+	}
+	return text, nil
+}
+
+func DeleteStoryContentAnnotation(annotationID int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	result, err := con.Exec("DELETE FROM story_content_annotation WHERE annotation_id = ?", annotationID)
+	if err != nil {
+		return res, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"rowsAffected":          rowsAffected,
+		"deleted_annotation_id": annotationID,
+	}
+
+	return res, nil
+}
+
+// GetStoryGlossary lists the glossary terms used in a published story with the
+// pages they appear on, optionally limited to one locale
+func GetStoryGlossary(storyID int, locale string) (Response, error) {
+	var res Response
+	entries := []StoryGlossaryEntry{}
+
+	con := db.CreateCon()
+
+	var published int
+	err := con.QueryRow("SELECT COUNT(*) FROM story s WHERE s.story_id = ? AND "+publishedStoryCondition, storyID).Scan(&published)
+	if err != nil {
+		return res, err
+	}
+	if published == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	sqlStatement := "SELECT g.glossary_id, g.term, g.locale, g.definition, g.pronunciation, g.created_at, g.updated_at, GROUP_CONCAT(DISTINCT a.`order` ORDER BY a.`order`) FROM story_content_annotation a JOIN glossary g ON a.glossary_id = g.glossary_id WHERE a.story_id = ?"
+	args := []interface{}{storyID}
+	if locale != "" {
+		sqlStatement += " AND a.locale = ?"
+		args = append(args, locale)
+	}
+	sqlStatement += " GROUP BY g.glossary_id ORDER BY g.term"
+
+	rows, err := con.Query(sqlStatement, args...)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry StoryGlossaryEntry
+		var pages string
+		err := rows.Scan(&entry.GlossaryID, &entry.Term, &entry.Locale, &entry.Definition, &entry.Pronunciation, &entry.CreatedAt, &entry.UpdatedAt, &pages)
+		if err != nil {
+			return res, err
+		}
+		entry.Pages = stringsToIntSlice2(pages)
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id": storyID,
+		"glossary": entries,
+	}
+
+	return res, nil
+}
+
+// getStoryAnnotationsByPage returns the glossary annotations of every page of a
+// story keyed by page order
+func getStoryAnnotationsByPage(ex dbExecutor, storyID int) (map[int][]StoryContentAnnotation, error) {
+	annotationsByPage := make(map[int][]StoryContentAnnotation)
+
+	rows, err := ex.Query("SELECT a.annotation_id, a.story_id, a.`order`, a.locale, a.glossary_id, a.char_start, a.char_end, a.text, g.term, g.definition, g.pronunciation FROM story_content_annotation a JOIN glossary g ON a.glossary_id = g.glossary_id WHERE a.story_id = ? ORDER BY a.`order`, a.locale, a.char_start", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var annotation StoryContentAnnotation
+		err := rows.Scan(
+			&annotation.AnnotationID,
+			&annotation.StoryID,
+			&annotation.Order,
+			&annotation.Locale,
+			&annotation.GlossaryID,
+			&annotation.CharStart,
+			&annotation.CharEnd,
+			&annotation.Text,
+			&annotation.Term,
+			&annotation.Definition,
+			&annotation.Pronunciation,
+		)
+		if err != nil {
+			return nil, err
+		}
+		annotationsByPage[annotation.Order] = append(annotationsByPage[annotation.Order], annotation)
+	}
+
+	return annotationsByPage, rows.Err()
+}
+
+// pageAnnotations picks the annotations of a page in the given locale and checks
+// that their spans still hold the annotated text
+func pageAnnotations(annotations []StoryContentAnnotation, locale, pageText string) []StoryContentAnnotation {
+	var matching []StoryContentAnnotation
+	runes := []rune(pageText)
+	for _, annotation := range annotations {
+		if annotation.Locale != locale {
+			continue
+		}
+		annotation.IsStale = annotation.CharEnd > len(runes) || string(runes[annotation.CharStart:annotation.CharEnd]) != annotation.Text
+		matching = append(matching, annotation)
+	}
+	return matching
+}
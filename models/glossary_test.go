@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAnnotationSpanText(t *testing.T) {
+	const page = "Sang Kancil melompat ke punggung buaya. Él sonríe."
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       string
+		valid      bool
+	}{
+		{"first word", 0, 4, "Sang", true},
+		{"two words", 5, 20, "Kancil melompat", true},
+		{"multibyte runes", 40, 42, "Él", true},
+		{"last word with period", 43, 50, "sonríe.", true},
+		{"leading space", 4, 11, "", false},
+		{"trailing space", 5, 12, "", false},
+		{"empty span", 5, 5, "", false},
+		{"reversed span", 11, 5, "", false},
+		{"negative start", -1, 4, "", false},
+		{"past the end", 43, 51, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := annotationSpanText(page, tt.start, tt.end)
+			if tt.valid != (err == nil) {
+				t.Fatalf("annotationSpanText(%d, %d) error = %v, want valid %v", tt.start, tt.end, err, tt.valid)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidAnnotation) {
+				t.Fatalf("annotationSpanText(%d, %d) error = %v, want ErrInvalidAnnotation", tt.start, tt.end, err)
+			}
+			if got != tt.want {
+				t.Fatalf("annotationSpanText(%d, %d) = %q, want %q", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageAnnotations(t *testing.T) {
+	annotations := []StoryContentAnnotation{
+		{AnnotationID: 1, Locale: "id", CharStart: 5, CharEnd: 11, Text: "Kancil"},
+		{AnnotationID: 2, Locale: "en", CharStart: 0, CharEnd: 5, Text: "Mouse"},
+		{AnnotationID: 3, Locale: "id", CharStart: 12, CharEnd: 20, Text: "melompat"},
+		{AnnotationID: 4, Locale: "id", CharStart: 30, CharEnd: 36, Text: "buaya."},
+	}
+
+	tests := []struct {
+		name      string
+		locale    string
+		text      string
+		wantIDs   []int
+		wantStale []bool
+	}{
+		{"unchanged text", "id", "Sang Kancil melompat jauh", []int{1, 3, 4}, []bool{false, false, true}},
+		{"edited text", "id", "Sang Kancil berlari", []int{1, 3, 4}, []bool{false, true, true}},
+		{"other locale", "en", "Mouse deer", []int{2}, []bool{false}},
+		{"no annotations", "ms", "Sang Kancil", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pageAnnotations(annotations, tt.locale, tt.text)
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("pageAnnotations() returned %d annotations, want %d", len(got), len(tt.wantIDs))
+			}
+			for i, annotation := range got {
+				if annotation.AnnotationID != tt.wantIDs[i] || annotation.IsStale != tt.wantStale[i] {
+					t.Errorf("annotation %d = id %d stale %v, want id %d stale %v", i, annotation.AnnotationID, annotation.IsStale, tt.wantIDs[i], tt.wantStale[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateGlossary(t *testing.T) {
+	tests := []struct {
+		name     string
+		glossary Glossary
+		valid    bool
+	}{
+		{"valid", Glossary{Term: "kancil", Definition: "pelanduk", Locale: LocaleIndonesian}, true},
+		{"missing term", Glossary{Term: " ", Definition: "pelanduk", Locale: LocaleIndonesian}, false},
+		{"missing definition", Glossary{Term: "kancil", Locale: LocaleIndonesian}, false},
+		{"unsupported locale", Glossary{Term: "kancil", Definition: "pelanduk", Locale: "fr"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGlossary(tt.glossary)
+			if tt.valid && err != nil {
+				t.Fatalf("validateGlossary() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidGlossary) {
+				t.Fatalf("validateGlossary() = %v, want ErrInvalidGlossary", err)
+			}
+		})
+	}
+}
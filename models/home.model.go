@@ -6,26 +6,30 @@ import (
 )
 
 type Home struct {
-	HighlightStories []StoryHome     `json:"highlight_stories"`
-	FavoriteStories  []StoryHome     `json:"favorite_stories"`
-	StoryTypes       []StoryTypeHome `json:"story_types"`
+	HighlightStories []StoryHome        `json:"highlight_stories"`
+	FavoriteStories  []StoryHome        `json:"favorite_stories"`
+	StoryTypes       []StoryTypeHome    `json:"story_types"`
+	Sections         []HomeSectionBlock `json:"sections"`
 }
 
 type StoryHome struct {
-	StoryID        int       `json:"story_id"`
-	TypeID         int       `json:"type_id"`
-	TypeName       string    `json:"type_name"`
-	OriginID       int       `json:"origin_id"`
-	OriginName     string    `json:"origin_name"`
-	Title          string    `json:"title"`
-	ThumbnailImage string    `json:"thumbnail_image"`
-	IsHighlighted  int       `json:"is_highligthed"`
-	IsFavorited    int       `json:"is_favorited"`
-	TotalContent   int       `json:"total_content"`
-	ReleasedDate   time.Time `json:"released_date"`
-	ReadCount      int       `json:"read_count"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	StoryID        int        `json:"story_id"`
+	TypeID         int        `json:"type_id"`
+	TypeName       string     `json:"type_name"`
+	OriginID       int        `json:"origin_id"`
+	OriginName     string     `json:"origin_name"`
+	Title          string     `json:"title"`
+	ThumbnailImage string     `json:"thumbnail_image"`
+	ThumbnailMeta  *ImageMeta `json:"thumbnail_meta"`
+	IsHighlighted  int        `json:"is_highligthed"`
+	IsFavorited    int        `json:"is_favorited"`
+	TotalContent   int        `json:"total_content"`
+	ReleasedDate   time.Time  `json:"released_date"`
+	ReadCount      int        `json:"read_count"`
+	LikeCount      int        `json:"like_count"`
+	LastOrder      *int       `json:"last_order,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
 
 type StoryTypeHome struct {
@@ -33,7 +37,10 @@ type StoryTypeHome struct {
 	TypeName string `json:"type_name"`
 }
 
-func GetHomeData() (Response, error) {
+// GetHomeData builds the home page for a reader. The fixed highlight, favorite
+// and type blocks are kept for older clients; newer ones render sections, which
+// start with personal ones when the reader is signed in.
+func GetHomeData(reader HomeReader, locale string) (Response, error) {
 	var res Response
 	var homeData Home
 
@@ -53,6 +60,16 @@ func GetHomeData() (Response, error) {
 	}
 	homeData.FavoriteStories = favoriteStories
 
+	// Attach blurhash, dominant color and variants of the thumbnails
+	if err := attachHomeThumbnailMeta(homeData.HighlightStories); err != nil {
+		res.Error = err.Error()
+		return res, err
+	}
+	if err := attachHomeThumbnailMeta(homeData.FavoriteStories); err != nil {
+		res.Error = err.Error()
+		return res, err
+	}
+
 	// Fetching all story types
 	storyTypes, err := getAllStoryTypes()
 	if err != nil {
@@ -61,15 +78,44 @@ func GetHomeData() (Response, error) {
 	}
 	homeData.StoryTypes = storyTypes
 
+	// Build the configurable sections
+	sections, err := buildHomeSections(reader, locale)
+	if err != nil {
+		res.Error = err.Error()
+		return res, err
+	}
+	homeData.Sections = sections
+
+	// Swap titles and taxonomy names for the requested locale
+	if l := newLocalizer(locale); l != nil {
+		if err := l.localizeStoryHomes(homeData.HighlightStories); err != nil {
+			res.Error = err.Error()
+			return res, err
+		}
+		if err := l.localizeStoryHomes(homeData.FavoriteStories); err != nil {
+			res.Error = err.Error()
+			return res, err
+		}
+		for i := range homeData.StoryTypes {
+			storyType := &homeData.StoryTypes[i]
+			if storyType.TypeName, err = l.taxonomyName(TaxonomyType, storyType.TypeID, storyType.TypeName); err != nil {
+				res.Error = err.Error()
+				return res, err
+			}
+		}
+	}
+
 	// Set the data in the Response struct
 	res.Data = struct {
-		HighlightStories []StoryHome     `json:"highlight_stories"`
-		FavoriteStories  []StoryHome     `json:"favorite_stories"`
-		StoryTypes       []StoryTypeHome `json:"story_types"`
+		HighlightStories []StoryHome        `json:"highlight_stories"`
+		FavoriteStories  []StoryHome        `json:"favorite_stories"`
+		StoryTypes       []StoryTypeHome    `json:"story_types"`
+		Sections         []HomeSectionBlock `json:"sections"`
 	}{
 		HighlightStories: homeData.HighlightStories,
 		FavoriteStories:  homeData.FavoriteStories,
 		StoryTypes:       homeData.StoryTypes,
+		Sections:         homeData.Sections,
 	}
 
 	return res, nil
@@ -80,7 +126,7 @@ func getStoriesByHighlight(highlight int) ([]StoryHome, error) {
 
 	db := db.CreateCon()
 
-	rows, err := db.Query("SELECT s.story_id, s.type_id, t.type_name, s.origin_id, o.origin_name, s.title, s.thumbnail_image, s.is_highligthed, s.is_favorited, s.total_content, s.released_date, s.read_count, s.created_at, s.updated_at FROM story s JOIN type t ON s.type_id = t.type_id JOIN origin o ON s.origin_id = o.origin_id WHERE s.is_highligthed = ?", highlight)
+	rows, err := db.Query("SELECT s.story_id, s.type_id, t.type_name, s.origin_id, o.origin_name, s.title, s.thumbnail_image, s.is_highligthed, s.is_favorited, s.total_content, s.released_date, s.read_count, s.like_count, s.created_at, s.updated_at FROM story s JOIN type t ON s.type_id = t.type_id JOIN origin o ON s.origin_id = o.origin_id WHERE s.is_highligthed = ? AND "+publishedStoryCondition, highlight)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +134,7 @@ func getStoriesByHighlight(highlight int) ([]StoryHome, error) {
 
 	for rows.Next() {
 		var story StoryHome
-		err := rows.Scan(&story.StoryID, &story.TypeID, &story.TypeName, &story.OriginID, &story.OriginName, &story.Title, &story.ThumbnailImage, &story.IsHighlighted, &story.IsFavorited, &story.TotalContent, &story.ReleasedDate, &story.ReadCount, &story.CreatedAt, &story.UpdatedAt)
+		err := rows.Scan(&story.StoryID, &story.TypeID, &story.TypeName, &story.OriginID, &story.OriginName, &story.Title, &story.ThumbnailImage, &story.IsHighlighted, &story.IsFavorited, &story.TotalContent, &story.ReleasedDate, &story.ReadCount, &story.LikeCount, &story.CreatedAt, &story.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -107,7 +153,7 @@ func getStoriesByFavorite(favorite int) ([]StoryHome, error) {
 
 	db := db.CreateCon()
 
-	rows, err := db.Query("SELECT s.story_id, s.type_id, t.type_name, s.origin_id, o.origin_name, s.title, s.thumbnail_image, s.is_highligthed, s.is_favorited, s.total_content, s.released_date, s.read_count, s.created_at, s.updated_at FROM story s JOIN type t ON s.type_id = t.type_id JOIN origin o ON s.origin_id = o.origin_id WHERE s.is_favorited = ?", favorite)
+	rows, err := db.Query("SELECT s.story_id, s.type_id, t.type_name, s.origin_id, o.origin_name, s.title, s.thumbnail_image, s.is_highligthed, s.is_favorited, s.total_content, s.released_date, s.read_count, s.like_count, s.created_at, s.updated_at FROM story s JOIN type t ON s.type_id = t.type_id JOIN origin o ON s.origin_id = o.origin_id WHERE s.is_favorited = ? AND "+publishedStoryCondition, favorite)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +161,7 @@ func getStoriesByFavorite(favorite int) ([]StoryHome, error) {
 
 	for rows.Next() {
 		var story StoryHome
-		err := rows.Scan(&story.StoryID, &story.TypeID, &story.TypeName, &story.OriginID, &story.OriginName, &story.Title, &story.ThumbnailImage, &story.IsHighlighted, &story.IsFavorited, &story.TotalContent, &story.ReleasedDate, &story.ReadCount, &story.CreatedAt, &story.UpdatedAt)
+		err := rows.Scan(&story.StoryID, &story.TypeID, &story.TypeName, &story.OriginID, &story.OriginName, &story.Title, &story.ThumbnailImage, &story.IsHighlighted, &story.IsFavorited, &story.TotalContent, &story.ReleasedDate, &story.ReadCount, &story.LikeCount, &story.CreatedAt, &story.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
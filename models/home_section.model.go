@@ -0,0 +1,596 @@
+// Home Section Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+)
+
+const (
+	HomeSectionHighlight       = "highlight"
+	HomeSectionFavorite        = "favorite"
+	HomeSectionCollection      = "collection"
+	HomeSectionTrending        = "trending"
+	HomeSectionGenre           = "genre"
+	HomeSectionNewReleases     = "new_releases"
+	HomeSectionContinueReading = "continue_reading"
+)
+
+// ErrInvalidHomeSection is returned for a section whose settings do not fit its type
+var ErrInvalidHomeSection = errors.New("invalid home section")
+
+// IsValidHomeSectionType reports whether sectionType is one the home builder can render
+func IsValidHomeSectionType(sectionType string) bool {
+	switch sectionType {
+	case HomeSectionHighlight, HomeSectionFavorite, HomeSectionCollection, HomeSectionTrending,
+		HomeSectionGenre, HomeSectionNewReleases, HomeSectionContinueReading:
+		return true
+	}
+	return false
+}
+
+const (
+	defaultHomeSectionLimit = 10
+	maxHomeSectionLimit     = 50
+
+	// trendingWindow is how far back reading activity counts towards trending
+	trendingWindow = "INTERVAL 7 DAY"
+)
+
+// HomeSection configures one block of the home page. It is shown between
+// ActiveFrom and ActiveUntil; a nil date leaves that side open.
+type HomeSection struct {
+	SectionID    int        `json:"section_id"`
+	SectionType  string     `json:"section_type"`
+	Title        string     `json:"title"`
+	CollectionID *int       `json:"collection_id"`
+	GenreID      *int       `json:"genre_id"`
+	ItemLimit    int        `json:"item_limit"`
+	Position     int        `json:"position"`
+	ActiveFrom   *time.Time `json:"active_from"`
+	ActiveUntil  *time.Time `json:"active_until"`
+	IsActive     bool       `json:"is_active"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// HomeSectionBlock is a section as rendered on the home page. Sections
+// generated for a reader have no SectionID.
+type HomeSectionBlock struct {
+	SectionID      int         `json:"section_id,omitempty"`
+	SectionType    string      `json:"section_type"`
+	Title          string      `json:"title"`
+	CollectionID   *int        `json:"collection_id,omitempty"`
+	GenreID        *int        `json:"genre_id,omitempty"`
+	BasedOnStoryID *int        `json:"based_on_story_id,omitempty"`
+	Stories        []StoryHome `json:"stories"`
+}
+
+// HomeReader identifies who the home page is built for. Both fields nil is an
+// anonymous reader.
+type HomeReader struct {
+	UserID *int
+	UID    *string
+}
+
+func (r HomeReader) isAnonymous() bool {
+	return r.UserID == nil && r.UID == nil
+}
+
+// homeSectionActiveCondition matches sections inside their active window
+const homeSectionActiveCondition = "(hs.active_from IS NULL OR hs.active_from <= UTC_TIMESTAMP()) AND (hs.active_until IS NULL OR hs.active_until > UTC_TIMESTAMP())"
+
+const homeSectionColumns = "hs.section_id, hs.section_type, hs.title, hs.collection_id, hs.genre_id, hs.item_limit, hs.position, hs.active_from, hs.active_until, " + homeSectionActiveCondition + ", hs.created_at, hs.updated_at"
+
+func scanHomeSection(row interface{ Scan(...interface{}) error }, loc *time.Location) (HomeSection, error) {
+	var section HomeSection
+	var collectionID, genreID sql.NullInt64
+	var activeFrom, activeUntil sql.NullTime
+	err := row.Scan(
+		&section.SectionID,
+		&section.SectionType,
+		&section.Title,
+		&collectionID,
+		&genreID,
+		&section.ItemLimit,
+		&section.Position,
+		&activeFrom,
+		&activeUntil,
+		&section.IsActive,
+		&section.CreatedAt,
+		&section.UpdatedAt,
+	)
+	if err != nil {
+		return section, err
+	}
+
+	if collectionID.Valid {
+		id := int(collectionID.Int64)
+		section.CollectionID = &id
+	}
+	if genreID.Valid {
+		id := int(genreID.Int64)
+		section.GenreID = &id
+	}
+
+	// Convert time fields to UTC+8 (Asia/Shanghai) before including them in the response
+	if activeFrom.Valid {
+		t := activeFrom.Time.In(loc)
+		section.ActiveFrom = &t
+	}
+	if activeUntil.Valid {
+		t := activeUntil.Time.In(loc)
+		section.ActiveUntil = &t
+	}
+	section.CreatedAt = section.CreatedAt.In(loc)
+	section.UpdatedAt = section.UpdatedAt.In(loc)
+
+	return section, nil
+}
+
+// getHomeSections lists sections in page order, only the active ones unless
+// includeInactive is set
+func getHomeSections(ex dbExecutor, includeInactive bool) ([]HomeSection, error) {
+	sections := []HomeSection{}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return nil, err
+	}
+
+	sqlStatement := "SELECT " + homeSectionColumns + " FROM home_section hs"
+	if !includeInactive {
+		sqlStatement += " WHERE " + homeSectionActiveCondition
+	}
+	sqlStatement += " ORDER BY hs.position, hs.section_id"
+
+	rows, err := ex.Query(sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		section, err := scanHomeSection(rows, loc)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// GetAllHomeSections lists every section, including inactive ones, in page order
+func GetAllHomeSections() ([]HomeSection, error) {
+	return getHomeSections(db.CreateCon(), true)
+}
+
+func GetHomeSectionDetail(sectionID int) (HomeSection, error) {
+	db := db.CreateCon()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return HomeSection{}, err
+	}
+
+	return scanHomeSection(db.QueryRow("SELECT "+homeSectionColumns+" FROM home_section hs WHERE hs.section_id = ?", sectionID), loc)
+}
+
+// validateHomeSection checks a section and fills in the default item limit
+func validateHomeSection(section *HomeSection) error {
+	if !IsValidHomeSectionType(section.SectionType) {
+		return fmt.Errorf("%w: unknown section_type %q", ErrInvalidHomeSection, section.SectionType)
+	}
+	if strings.TrimSpace(section.Title) == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidHomeSection)
+	}
+	if section.SectionType == HomeSectionCollection && section.CollectionID == nil {
+		return fmt.Errorf("%w: collection sections need a collection_id", ErrInvalidHomeSection)
+	}
+	if section.SectionType == HomeSectionGenre && section.GenreID == nil {
+		return fmt.Errorf("%w: genre sections need a genre_id", ErrInvalidHomeSection)
+	}
+	if section.ItemLimit == 0 {
+		section.ItemLimit = defaultHomeSectionLimit
+	}
+	if section.ItemLimit < 1 || section.ItemLimit > maxHomeSectionLimit {
+		return fmt.Errorf("%w: item_limit must be between 1 and %d", ErrInvalidHomeSection, maxHomeSectionLimit)
+	}
+	if section.ActiveFrom != nil && section.ActiveUntil != nil && !section.ActiveUntil.After(*section.ActiveFrom) {
+		return fmt.Errorf("%w: active_until must be after active_from", ErrInvalidHomeSection)
+	}
+
+	// Drop references the section type does not use
+	if section.SectionType != HomeSectionCollection {
+		section.CollectionID = nil
+	}
+	if section.SectionType != HomeSectionGenre {
+		section.GenreID = nil
+	}
+
+	return nil
+}
+
+// CreateHomeSection adds a section. Without a position it goes to the bottom of the page.
+func CreateHomeSection(section HomeSection) (int64, error) {
+	if err := validateHomeSection(&section); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	if section.Position == 0 {
+		if err := db.QueryRow("SELECT COALESCE(MAX(position), 0) + 1 FROM home_section").Scan(&section.Position); err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := db.Exec("INSERT INTO home_section (section_type, title, collection_id, genre_id, item_limit, position, active_from, active_until, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		section.SectionType, strings.TrimSpace(section.Title), section.CollectionID, section.GenreID, section.ItemLimit, section.Position,
+		nullableTime(section.ActiveFrom), nullableTime(section.ActiveUntil), time.Now(), time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateHomeSection replaces the settings of a section. Without a position it
+// stays where it is.
+func UpdateHomeSection(section HomeSection) (int64, error) {
+	if err := validateHomeSection(&section); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	var position *int
+	if section.Position != 0 {
+		position = &section.Position
+	}
+
+	result, err := db.Exec("UPDATE home_section SET section_type = ?, title = ?, collection_id = ?, genre_id = ?, item_limit = ?, position = COALESCE(?, position), active_from = ?, active_until = ?, updated_at = ? WHERE section_id = ?",
+		section.SectionType, strings.TrimSpace(section.Title), section.CollectionID, section.GenreID, section.ItemLimit, position,
+		nullableTime(section.ActiveFrom), nullableTime(section.ActiveUntil), time.Now(), section.SectionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// MySQL reports no affected rows for an update that changes nothing, so
+	// tell an unknown section apart from an unchanged one
+	if rowsAffected == 0 {
+		var exists int
+		if err := db.QueryRow("SELECT 1 FROM home_section WHERE section_id = ?", section.SectionID).Scan(&exists); err != nil {
+			return 0, err
+		}
+	}
+
+	return rowsAffected, nil
+}
+
+func DeleteHomeSection(sectionID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM home_section WHERE section_id = ?", sectionID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// ReorderHomeSections sets the page order to the order of sectionIDs. Sections
+// left out keep their relative order after the listed ones. An unknown section
+// ID returns sql.ErrNoRows.
+func ReorderHomeSections(sectionIDs []int) ([]HomeSection, error) {
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the sections so a concurrent reorder cannot interleave
+	locked, err := tx.Query("SELECT section_id FROM home_section FOR UPDATE")
+	if err != nil {
+		return nil, err
+	}
+	locked.Close()
+
+	sections, err := getHomeSections(tx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, ok := orderHomeSections(sections, sectionIDs)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	now := time.Now()
+	for _, section := range sections {
+		position := positions[section.SectionID]
+		if position == section.Position {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE home_section SET position = ?, updated_at = ? WHERE section_id = ?", position, now, section.SectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	sections, err = getHomeSections(tx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// orderHomeSections returns the new position of every section: the listed ones
+// first in the given order, then the others in their current page order. ok is
+// false when a listed ID is not one of sections.
+func orderHomeSections(sections []HomeSection, sectionIDs []int) (map[int]int, bool) {
+	known := make(map[int]bool)
+	for _, section := range sections {
+		known[section.SectionID] = true
+	}
+
+	positions := make(map[int]int)
+	for _, sectionID := range sectionIDs {
+		if !known[sectionID] {
+			return nil, false
+		}
+		if _, ok := positions[sectionID]; !ok {
+			positions[sectionID] = len(positions) + 1
+		}
+	}
+
+	// sections is already in page order
+	for _, section := range sections {
+		if _, ok := positions[section.SectionID]; !ok {
+			positions[section.SectionID] = len(positions) + 1
+		}
+	}
+
+	return positions, true
+}
+
+const storyHomeColumns = "s.story_id, s.type_id, COALESCE(t.type_name, ''), s.origin_id, COALESCE(o.origin_name, ''), s.title, s.thumbnail_image, s.is_highligthed, s.is_favorited, s.total_content, s.released_date, s.read_count, s.like_count, s.created_at, s.updated_at"
+
+// queryStoryHomes lists published stories for a home section. join is added
+// after the type and origin joins; args fill the placeholders of join,
+// condition and order, in that order.
+func queryStoryHomes(ex dbExecutor, join, condition string, args []interface{}, order string, limit int) ([]StoryHome, error) {
+	stories := []StoryHome{}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return nil, err
+	}
+
+	// Stories without a type or origin still belong on the home page
+	sqlStatement := "SELECT " + storyHomeColumns + " FROM story s LEFT JOIN type t ON s.type_id = t.type_id LEFT JOIN origin o ON s.origin_id = o.origin_id " + join +
+		" WHERE " + publishedStoryCondition
+	if condition != "" {
+		sqlStatement += " AND " + condition
+	}
+	sqlStatement += " ORDER BY " + order + " LIMIT ?"
+
+	rows, err := ex.Query(sqlStatement, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var story StoryHome
+		err := rows.Scan(&story.StoryID, &story.TypeID, &story.TypeName, &story.OriginID, &story.OriginName, &story.Title, &story.ThumbnailImage, &story.IsHighlighted, &story.IsFavorited, &story.TotalContent, &story.ReleasedDate, &story.ReadCount, &story.LikeCount, &story.CreatedAt, &story.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		// Convert time fields to UTC+8 (Asia/Shanghai) before including them in the response
+		story.ReleasedDate = story.ReleasedDate.In(loc)
+		story.CreatedAt = story.CreatedAt.In(loc)
+		story.UpdatedAt = story.UpdatedAt.In(loc)
+
+		stories = append(stories, story)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stories, nil
+}
+
+// getSectionStories lists the stories of one section. It returns nil when the
+// section cannot be shown to the reader, e.g. continue reading for anonymous readers.
+func getSectionStories(ex dbExecutor, section HomeSection, reader HomeReader) ([]StoryHome, error) {
+	switch section.SectionType {
+	case HomeSectionHighlight:
+		return queryStoryHomes(ex, "", "s.is_highligthed = 1", nil, "s.released_date DESC", section.ItemLimit)
+	case HomeSectionFavorite:
+		return queryStoryHomes(ex, "", "s.is_favorited = 1", nil, "s.released_date DESC", section.ItemLimit)
+	case HomeSectionNewReleases:
+		return queryStoryHomes(ex, "", "", nil, "s.released_date DESC, s.story_id DESC", section.ItemLimit)
+	case HomeSectionTrending:
+		return queryStoryHomes(ex, "", "",
+			nil,
+			"(SELECT COUNT(*) FROM reading_progress rp WHERE rp.story_id = s.story_id AND rp.updated_at >= UTC_TIMESTAMP() - "+trendingWindow+") DESC, s.read_count DESC",
+			section.ItemLimit,
+		)
+	case HomeSectionGenre:
+		return queryStoryHomes(ex, "",
+			"EXISTS (SELECT 1 FROM story_genre sg WHERE sg.story_id = s.story_id AND sg.genre_id = ?)",
+			[]interface{}{*section.GenreID},
+			"s.released_date DESC",
+			section.ItemLimit,
+		)
+	case HomeSectionCollection:
+		return queryStoryHomes(ex,
+			"JOIN collection_story cs ON cs.story_id = s.story_id AND cs.collection_id = ? JOIN collection c ON cs.collection_id = c.collection_id",
+			collectionVisibleCondition,
+			[]interface{}{*section.CollectionID},
+			"cs.position",
+			section.ItemLimit,
+		)
+	case HomeSectionContinueReading:
+		if reader.isAnonymous() {
+			return nil, nil
+		}
+		return getContinueReading(ex, reader, section.ItemLimit)
+	}
+	return nil, fmt.Errorf("unknown section_type %q", section.SectionType)
+}
+
+// getContinueReading lists the unfinished stories of a reader, most recently read first
+func getContinueReading(ex dbExecutor, reader HomeReader, limit int) ([]StoryHome, error) {
+	condition, readerArg := readerCondition("rp.", reader.UserID, reader.UID)
+
+	stories, err := queryStoryHomes(ex,
+		"JOIN reading_progress rp ON rp.story_id = s.story_id AND "+condition,
+		"rp.last_order < s.total_content",
+		[]interface{}{readerArg},
+		"rp.updated_at DESC",
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ex.Query("SELECT rp.story_id, rp.last_order FROM reading_progress rp WHERE "+condition, readerArg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastOrders := make(map[int]int)
+	for rows.Next() {
+		var storyID, lastOrder int
+		if err := rows.Scan(&storyID, &lastOrder); err != nil {
+			return nil, err
+		}
+		lastOrders[storyID] = lastOrder
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range stories {
+		lastOrder := lastOrders[stories[i].StoryID]
+		stories[i].LastOrder = &lastOrder
+	}
+
+	return stories, nil
+}
+
+// buildHomeSections renders the active sections in page order. Signed-in
+// readers get their personal sections first; anonymous readers only see the
+// editorial ones. Sections without stories are left out.
+func buildHomeSections(reader HomeReader, locale string) ([]HomeSectionBlock, error) {
+	con := db.CreateCon()
+
+	sections, err := getHomeSections(con, false)
+	if err != nil {
+		return nil, err
+	}
+
+	l := newLocalizer(locale)
+
+	blocks := []HomeSectionBlock{}
+	hasContinueReading := false
+	for _, section := range sections {
+		if section.SectionType == HomeSectionContinueReading {
+			hasContinueReading = true
+		}
+
+		stories, err := getSectionStories(con, section, reader)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, HomeSectionBlock{
+			SectionID:    section.SectionID,
+			SectionType:  section.SectionType,
+			Title:        section.Title,
+			CollectionID: section.CollectionID,
+			GenreID:      section.GenreID,
+			Stories:      stories,
+		})
+	}
+
+	var personal []HomeSectionBlock
+	if !reader.isAnonymous() {
+		personal, err = buildPersonalSections(con, reader, l, !hasContinueReading)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rendered := []HomeSectionBlock{}
+	for _, block := range assembleHomeSections(personal, blocks) {
+		if err := attachHomeThumbnailMeta(block.Stories); err != nil {
+			return nil, err
+		}
+
+		// Swap titles and taxonomy names for the requested locale
+		if l != nil {
+			if err := l.localizeStoryHomes(block.Stories); err != nil {
+				return nil, err
+			}
+		}
+
+		rendered = append(rendered, block)
+	}
+
+	return rendered, nil
+}
+
+// assembleHomeSections puts the reader's personal sections ahead of the
+// editorial ones and drops the sections without stories
+func assembleHomeSections(personal, editorial []HomeSectionBlock) []HomeSectionBlock {
+	blocks := []HomeSectionBlock{}
+	for _, block := range append(append([]HomeSectionBlock{}, personal...), editorial...) {
+		if len(block.Stories) == 0 {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateHomeSection(t *testing.T) {
+	collectionID, genreID := 3, 5
+	from := time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(7 * 24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		section       HomeSection
+		valid         bool
+		wantLimit     int
+		wantGenre     bool
+		wantCollected bool
+	}{
+		{"default limit", HomeSection{SectionType: HomeSectionTrending, Title: "Populer"}, true, defaultHomeSectionLimit, false, false},
+		{"genre", HomeSection{SectionType: HomeSectionGenre, Title: "Fabel", GenreID: &genreID, ItemLimit: 5}, true, 5, true, false},
+		{"collection drops genre", HomeSection{SectionType: HomeSectionCollection, Title: "Pilihan", CollectionID: &collectionID, GenreID: &genreID}, true, defaultHomeSectionLimit, false, true},
+		{"active window", HomeSection{SectionType: HomeSectionNewReleases, Title: "Baru", ActiveFrom: &from, ActiveUntil: &until}, true, defaultHomeSectionLimit, false, false},
+		{"unknown type", HomeSection{SectionType: "popular", Title: "Populer"}, false, 0, false, false},
+		{"missing title", HomeSection{SectionType: HomeSectionTrending}, false, 0, false, false},
+		{"genre without genre_id", HomeSection{SectionType: HomeSectionGenre, Title: "Fabel"}, false, 0, false, false},
+		{"collection without collection_id", HomeSection{SectionType: HomeSectionCollection, Title: "Pilihan"}, false, 0, false, false},
+		{"limit too high", HomeSection{SectionType: HomeSectionTrending, Title: "Populer", ItemLimit: maxHomeSectionLimit + 1}, false, 0, false, false},
+		{"negative limit", HomeSection{SectionType: HomeSectionTrending, Title: "Populer", ItemLimit: -1}, false, 0, false, false},
+		{"empty window", HomeSection{SectionType: HomeSectionTrending, Title: "Populer", ActiveFrom: &until, ActiveUntil: &from}, false, 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := tt.section
+			err := validateHomeSection(&section)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidHomeSection) {
+					t.Fatalf("validateHomeSection() = %v, want ErrInvalidHomeSection", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateHomeSection() = %v, want nil", err)
+			}
+			if section.ItemLimit != tt.wantLimit {
+				t.Errorf("ItemLimit = %d, want %d", section.ItemLimit, tt.wantLimit)
+			}
+			if (section.GenreID != nil) != tt.wantGenre {
+				t.Errorf("GenreID = %v, want set %v", section.GenreID, tt.wantGenre)
+			}
+			if (section.CollectionID != nil) != tt.wantCollected {
+				t.Errorf("CollectionID = %v, want set %v", section.CollectionID, tt.wantCollected)
+			}
+		})
+	}
+}
+
+func TestOrderHomeSections(t *testing.T) {
+	// In page order
+	sections := []HomeSection{{SectionID: 4, Position: 1}, {SectionID: 2, Position: 2}, {SectionID: 7, Position: 3}, {SectionID: 1, Position: 4}}
+
+	tests := []struct {
+		name       string
+		sectionIDs []int
+		want       map[int]int
+		ok         bool
+	}{
+		{"all sections", []int{1, 2, 4, 7}, map[int]int{1: 1, 2: 2, 4: 3, 7: 4}, true},
+		{"others keep their order", []int{1}, map[int]int{1: 1, 4: 2, 2: 3, 7: 4}, true},
+		{"no sections", nil, map[int]int{4: 1, 2: 2, 7: 3, 1: 4}, true},
+		{"unknown section", []int{1, 9}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := orderHomeSections(sections, tt.sectionIDs)
+			if ok != tt.ok {
+				t.Fatalf("orderHomeSections() ok = %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderHomeSections() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssembleHomeSections(t *testing.T) {
+	stories := []StoryHome{{StoryID: 1}}
+	personal := []HomeSectionBlock{
+		{SectionType: HomeSectionContinueReading, Stories: stories},
+		{SectionType: HomeSectionFavoriteOrigins},
+	}
+	editorial := []HomeSectionBlock{
+		{SectionID: 1, SectionType: HomeSectionTrending, Stories: stories},
+		{SectionID: 2, SectionType: HomeSectionNewReleases},
+		{SectionID: 3, SectionType: HomeSectionGenre, Stories: stories},
+	}
+
+	tests := []struct {
+		name      string
+		personal  []HomeSectionBlock
+		editorial []HomeSectionBlock
+		want      []string
+	}{
+		{"anonymous reader", nil, editorial, []string{HomeSectionTrending, HomeSectionGenre}},
+		{"personal sections first", personal, editorial, []string{HomeSectionContinueReading, HomeSectionTrending, HomeSectionGenre}},
+		{"no sections", nil, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, block := range assembleHomeSections(tt.personal, tt.editorial) {
+				got = append(got, block.SectionType)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("assembleHomeSections() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Image Asset Model
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"kisahloka_be/db"
+	"kisahloka_be/imaging"
+	"kisahloka_be/storage"
+	"strings"
+	"time"
+)
+
+// ImageVariant is a resized copy of an uploaded image
+type ImageVariant struct {
+	Width    int    `json:"width"`
+	Format   string `json:"format"`
+	URL      string `json:"url"`
+	FileSize int64  `json:"file_size"`
+}
+
+// ImageMeta describes an uploaded image: its size, a blurhash and dominant color
+// to show while it loads, and the resized variants clients can pick from
+type ImageMeta struct {
+	Width         int            `json:"width"`
+	Height        int            `json:"height"`
+	Blurhash      string         `json:"blurhash"`
+	DominantColor string         `json:"dominant_color"`
+	Variants      []ImageVariant `json:"variants"`
+}
+
+// processImage decodes an uploaded image, stores its variants next to the
+// original and records its metadata. URLs already processed are skipped.
+func processImage(media StoredMedia, data []byte) (err error) {
+	con := db.CreateCon()
+
+	var exists int
+	if err := con.QueryRow("SELECT COUNT(*) FROM image_asset WHERE url = ?", media.URL).Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	bounds := img.Bounds()
+
+	meta := ImageMeta{
+		Width:         bounds.Dx(),
+		Height:        bounds.Dy(),
+		Blurhash:      imaging.Blurhash(img, 4, 3),
+		DominantColor: imaging.DominantColor(img),
+	}
+
+	// Variants are stored next to the original as <hash>-<width>.<ext>
+	store := storage.GetStorage()
+	keyBase := strings.TrimSuffix(media.Key, ImageMimeTypes[media.MimeType])
+
+	// Remove the variants already stored when a later step fails
+	var storedKeys []string
+	defer func() {
+		if err != nil {
+			for _, key := range storedKeys {
+				store.Delete(key)
+			}
+		}
+	}()
+
+	for _, width := range imaging.VariantWidths {
+		if width >= meta.Width {
+			continue
+		}
+		resized := imaging.Resize(img, width)
+
+		for _, format := range imaging.VariantFormats {
+			encoded, err := imaging.Encode(resized, format)
+			if err != nil {
+				return err
+			}
+
+			key := fmt.Sprintf("%s-%d%s", keyBase, width, imaging.FormatExtensions[format])
+			if err := store.Put(key, bytes.NewReader(encoded), int64(len(encoded)), imaging.FormatMimeTypes[format]); err != nil {
+				return err
+			}
+			storedKeys = append(storedKeys, key)
+
+			meta.Variants = append(meta.Variants, ImageVariant{
+				Width:    width,
+				Format:   format,
+				URL:      store.URL(key),
+				FileSize: int64(len(encoded)),
+			})
+		}
+	}
+
+	tx, err := con.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO image_asset (sha256, url, width, height, blurhash, dominant_color, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		media.SHA256, media.URL, meta.Width, meta.Height, meta.Blurhash, meta.DominantColor, time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	imageID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range meta.Variants {
+		_, err := tx.Exec(
+			"INSERT INTO image_variant (image_id, width, format, url, file_size) VALUES (?, ?, ?, ?, ?)",
+			imageID, variant.Width, variant.Format, variant.URL, variant.FileSize,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// getImageMetaByURL loads the metadata of the images behind the given URLs. URLs
+// that were not uploaded through the API are missing from the result.
+func getImageMetaByURL(ex dbExecutor, urls []string) (map[string]*ImageMeta, error) {
+	metaByURL := make(map[string]*ImageMeta)
+
+	placeholders := []string{}
+	args := []interface{}{}
+	seen := make(map[string]bool)
+	for _, url := range urls {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		placeholders = append(placeholders, "?")
+		args = append(args, url)
+	}
+	if len(args) == 0 {
+		return metaByURL, nil
+	}
+
+	rows, err := ex.Query("SELECT i.image_id, i.url, i.width, i.height, i.blurhash, i.dominant_color, v.width, v.format, v.url, v.file_size FROM image_asset i LEFT JOIN image_variant v ON i.image_id = v.image_id WHERE i.url IN ("+strings.Join(placeholders, ", ")+") ORDER BY i.image_id, v.width, v.format", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var imageID int
+		var url string
+		var meta ImageMeta
+		var variantWidth *int
+		var variantFormat, variantURL *string
+		var variantSize *int64
+		if err := rows.Scan(&imageID, &url, &meta.Width, &meta.Height, &meta.Blurhash, &meta.DominantColor, &variantWidth, &variantFormat, &variantURL, &variantSize); err != nil {
+			return nil, err
+		}
+
+		current, ok := metaByURL[url]
+		if !ok {
+			current = &meta
+			current.Variants = []ImageVariant{}
+			metaByURL[url] = current
+		}
+		if variantWidth != nil {
+			current.Variants = append(current.Variants, ImageVariant{
+				Width:    *variantWidth,
+				Format:   *variantFormat,
+				URL:      *variantURL,
+				FileSize: *variantSize,
+			})
+		}
+	}
+
+	return metaByURL, rows.Err()
+}
+
+// attachPreviewThumbnailMeta fills in the thumbnail metadata of story previews
+func attachPreviewThumbnailMeta(ex dbExecutor, stories []StoryPreview) error {
+	urls := make([]string, len(stories))
+	for i, story := range stories {
+		urls[i] = story.ThumbnailImage
+	}
+
+	metaByURL, err := getImageMetaByURL(ex, urls)
+	if err != nil {
+		return err
+	}
+
+	for i := range stories {
+		stories[i].ThumbnailMeta = metaByURL[stories[i].ThumbnailImage]
+	}
+
+	return nil
+}
+
+// attachHomeThumbnailMeta fills in the thumbnail metadata of home stories
+func attachHomeThumbnailMeta(stories []StoryHome) error {
+	urls := make([]string, len(stories))
+	for i, story := range stories {
+		urls[i] = story.ThumbnailImage
+	}
+
+	metaByURL, err := getImageMetaByURL(db.CreateCon(), urls)
+	if err != nil {
+		return err
+	}
+
+	for i := range stories {
+		stories[i].ThumbnailMeta = metaByURL[stories[i].ThumbnailImage]
+	}
+
+	return nil
+}
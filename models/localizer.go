@@ -0,0 +1,352 @@
+package models
+
+import (
+	"kisahloka_be/db"
+	"strings"
+)
+
+const (
+	TaxonomyType       = "type"
+	TaxonomyOrigin     = "origin"
+	TaxonomyGenre      = "genre"
+	TaxonomyMoralValue = "moral_value"
+)
+
+// LocaleFallbackChain lists the locales tried, in order, when text is missing in
+// the requested locale. The tales are written in Indonesian first, then English.
+func LocaleFallbackChain(locale string) []string {
+	chain := []string{locale}
+	for _, fallback := range []string{LocaleIndonesian, LocaleEnglish} {
+		if fallback != locale {
+			chain = append(chain, fallback)
+		}
+	}
+	return chain
+}
+
+// localizer picks story text and taxonomy names for one requested locale. A nil
+// localizer leaves everything in the original bilingual shape.
+type localizer struct {
+	locale     string
+	chain      []string
+	ex         dbExecutor
+	taxonomies map[string]map[int]string
+}
+
+func newLocalizer(locale string) *localizer {
+	if locale == "" {
+		return nil
+	}
+	return &localizer{
+		locale:     locale,
+		chain:      LocaleFallbackChain(locale),
+		ex:         db.CreateCon(),
+		taxonomies: make(map[string]map[int]string),
+	}
+}
+
+// chainPlaceholders returns "?, ?, ..." and the chain as query arguments
+func (l *localizer) chainPlaceholders() (string, []interface{}) {
+	placeholders := make([]string, len(l.chain))
+	args := make([]interface{}, len(l.chain))
+	for i, locale := range l.chain {
+		placeholders[i] = "?"
+		args[i] = locale
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// rank returns the position of locale in the fallback chain, lower is better
+func (l *localizer) rank(locale string) int {
+	for i, candidate := range l.chain {
+		if candidate == locale {
+			return i
+		}
+	}
+	return len(l.chain)
+}
+
+// storyTitles returns the best title and synopsis per story ID. Both fall back
+// through the chain on their own, so a translation with only a title still gets
+// a synopsis; Locale is the locale of the title.
+func (l *localizer) storyTitles(storyIDs []int) (map[int]StoryTranslation, error) {
+	titles := make(map[int]StoryTranslation)
+	if len(storyIDs) == 0 {
+		return titles, nil
+	}
+
+	idPlaceholders := make([]string, len(storyIDs))
+	args := make([]interface{}, 0, len(storyIDs)+len(l.chain))
+	for i, storyID := range storyIDs {
+		idPlaceholders[i] = "?"
+		args = append(args, storyID)
+	}
+	localePlaceholders, localeArgs := l.chainPlaceholders()
+	args = append(args, localeArgs...)
+
+	rows, err := l.ex.Query("SELECT story_id, locale, title, synopsis FROM story_translation WHERE story_id IN ("+strings.Join(idPlaceholders, ", ")+") AND locale IN ("+localePlaceholders+") AND (title <> '' OR synopsis <> '')", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var translations []StoryTranslation
+	for rows.Next() {
+		var translation StoryTranslation
+		if err := rows.Scan(&translation.StoryID, &translation.Locale, &translation.Title, &translation.Synopsis); err != nil {
+			return nil, err
+		}
+		translations = append(translations, translation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return l.pickStoryTitles(translations), nil
+}
+
+// pickStoryTitles merges the translations of each story into the best title
+// and the best synopsis
+func (l *localizer) pickStoryTitles(translations []StoryTranslation) map[int]StoryTranslation {
+	titles := make(map[int]StoryTranslation)
+	synopsisLocales := make(map[int]string)
+
+	for _, translation := range translations {
+		current, ok := titles[translation.StoryID]
+		if !ok {
+			current = StoryTranslation{StoryID: translation.StoryID}
+		}
+
+		if translation.Title != "" && (current.Title == "" || l.rank(translation.Locale) < l.rank(current.Locale)) {
+			current.Title = translation.Title
+			current.Locale = translation.Locale
+		}
+
+		synopsisLocale, hasSynopsis := synopsisLocales[translation.StoryID]
+		if translation.Synopsis != "" && (!hasSynopsis || l.rank(translation.Locale) < l.rank(synopsisLocale)) {
+			current.Synopsis = translation.Synopsis
+			synopsisLocales[translation.StoryID] = translation.Locale
+		}
+
+		titles[translation.StoryID] = current
+	}
+
+	// Stories with a synopsis but no title anywhere in the chain keep their own title
+	for storyID, translation := range titles {
+		if translation.Title == "" {
+			delete(titles, storyID)
+		}
+	}
+
+	return titles
+}
+
+// storyContent returns the pages of a story with the best available text per page
+func (l *localizer) storyContent(storyID int) ([]StoryContentLocalized, error) {
+	var content []StoryContentLocalized
+
+	rows, err := l.ex.Query("SELECT `order`, image FROM story_content WHERE story_id = ? ORDER BY `order`", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var page StoryContentLocalized
+		if err := rows.Scan(&page.Order, &page.Image); err != nil {
+			return nil, err
+		}
+		content = append(content, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	localePlaceholders, args := l.chainPlaceholders()
+	textRows, err := l.ex.Query("SELECT `order`, locale, content FROM story_content_translation WHERE story_id = ? AND locale IN ("+localePlaceholders+") AND content <> ''", append([]interface{}{storyID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer textRows.Close()
+
+	best := make(map[int]StoryContentTranslation)
+	bestLocale := make(map[int]string)
+	for textRows.Next() {
+		var page StoryContentTranslation
+		var locale string
+		if err := textRows.Scan(&page.Order, &locale, &page.Content); err != nil {
+			return nil, err
+		}
+		current, ok := bestLocale[page.Order]
+		if !ok || l.rank(locale) < l.rank(current) {
+			best[page.Order] = page
+			bestLocale[page.Order] = locale
+		}
+	}
+	if err := textRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range content {
+		content[i].Locale = bestLocale[content[i].Order]
+		content[i].Content = best[content[i].Order].Content
+	}
+
+	return content, nil
+}
+
+// taxonomyName returns the translated name of a type, origin, genre or moral value, or
+// fallback when it has none in the chain
+func (l *localizer) taxonomyName(taxonomy string, taxonomyID int, fallback string) (string, error) {
+	names, ok := l.taxonomies[taxonomy]
+	if !ok {
+		var err error
+		names, err = l.loadTaxonomy(taxonomy)
+		if err != nil {
+			return fallback, err
+		}
+		l.taxonomies[taxonomy] = names
+	}
+
+	if name, ok := names[taxonomyID]; ok {
+		return name, nil
+	}
+	return fallback, nil
+}
+
+func (l *localizer) loadTaxonomy(taxonomy string) (map[int]string, error) {
+	names := make(map[int]string)
+	ranks := make(map[int]int)
+
+	localePlaceholders, args := l.chainPlaceholders()
+	rows, err := l.ex.Query("SELECT taxonomy_id, locale, name FROM taxonomy_translation WHERE taxonomy = ? AND locale IN ("+localePlaceholders+")", append([]interface{}{taxonomy}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taxonomyID int
+		var locale, name string
+		if err := rows.Scan(&taxonomyID, &locale, &name); err != nil {
+			return nil, err
+		}
+		rank, ok := ranks[taxonomyID]
+		if !ok || l.rank(locale) < rank {
+			names[taxonomyID] = name
+			ranks[taxonomyID] = l.rank(locale)
+		}
+	}
+
+	return names, rows.Err()
+}
+
+// genreNames translates a list of genres matched up by position with their IDs
+func (l *localizer) genreNames(genreIDs []int, genreNames []string) ([]string, error) {
+	if len(genreIDs) != len(genreNames) {
+		return genreNames, nil
+	}
+
+	localized := make([]string, len(genreNames))
+	for i, genreID := range genreIDs {
+		name, err := l.taxonomyName(TaxonomyGenre, genreID, genreNames[i])
+		if err != nil {
+			return nil, err
+		}
+		localized[i] = name
+	}
+	return localized, nil
+}
+
+// localizeStories swaps titles, synopses and taxonomy names of stories for the requested locale
+func (l *localizer) localizeStories(stories []Story) error {
+	storyIDs := make([]int, len(stories))
+	for i, story := range stories {
+		storyIDs[i] = story.StoryID
+	}
+
+	titles, err := l.storyTitles(storyIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range stories {
+		story := &stories[i]
+		if title, ok := titles[story.StoryID]; ok {
+			story.Title = title.Title
+			if title.Synopsis != "" {
+				story.Synopsis = title.Synopsis
+			}
+		}
+		if story.TypeName, err = l.taxonomyName(TaxonomyType, story.TypeID, story.TypeName); err != nil {
+			return err
+		}
+		if story.OriginName, err = l.taxonomyName(TaxonomyOrigin, story.OriginID, story.OriginName); err != nil {
+			return err
+		}
+		if story.GenreName, err = l.genreNames(story.GenreID, story.GenreName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// localizeStoryPreviews swaps titles and taxonomy names of previews for the requested locale
+func (l *localizer) localizeStoryPreviews(stories []StoryPreview) error {
+	storyIDs := make([]int, len(stories))
+	for i, story := range stories {
+		storyIDs[i] = story.StoryID
+	}
+
+	titles, err := l.storyTitles(storyIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range stories {
+		story := &stories[i]
+		if title, ok := titles[story.StoryID]; ok {
+			story.Title = title.Title
+		}
+		if story.TypeName, err = l.taxonomyName(TaxonomyType, story.TypeID, story.TypeName); err != nil {
+			return err
+		}
+		if story.OriginName, err = l.taxonomyName(TaxonomyOrigin, story.OriginID, story.OriginName); err != nil {
+			return err
+		}
+		if story.GenreName, err = l.genreNames(story.GenreID, story.GenreName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// localizeStoryHomes swaps titles and taxonomy names of home stories for the requested locale
+func (l *localizer) localizeStoryHomes(stories []StoryHome) error {
+	storyIDs := make([]int, len(stories))
+	for i, story := range stories {
+		storyIDs[i] = story.StoryID
+	}
+
+	titles, err := l.storyTitles(storyIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range stories {
+		story := &stories[i]
+		if title, ok := titles[story.StoryID]; ok {
+			story.Title = title.Title
+		}
+		if story.TypeName, err = l.taxonomyName(TaxonomyType, story.TypeID, story.TypeName); err != nil {
+			return err
+		}
+		if story.OriginName, err = l.taxonomyName(TaxonomyOrigin, story.OriginID, story.OriginName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package models
+
+import "testing"
+
+func TestLocaleFallbackChain(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{LocaleIndonesian, []string{"id", "en"}},
+		{LocaleEnglish, []string{"en", "id"}},
+		{"jv", []string{"jv", "id", "en"}},
+	}
+
+	for _, tt := range tests {
+		got := LocaleFallbackChain(tt.locale)
+		if len(got) != len(tt.want) {
+			t.Fatalf("LocaleFallbackChain(%q) = %v, want %v", tt.locale, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("LocaleFallbackChain(%q) = %v, want %v", tt.locale, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestPickStoryTitles(t *testing.T) {
+	l := &localizer{locale: "jv", chain: LocaleFallbackChain("jv")}
+
+	tests := []struct {
+		name         string
+		translations []StoryTranslation
+		want         StoryTranslation
+		found        bool
+	}{
+		{
+			name: "requested locale wins",
+			translations: []StoryTranslation{
+				{StoryID: 1, Locale: "id", Title: "Malin Kundang", Synopsis: "Anak durhaka"},
+				{StoryID: 1, Locale: "jv", Title: "Malin Kundang (jv)", Synopsis: "Bocah durhaka"},
+			},
+			want:  StoryTranslation{StoryID: 1, Locale: "jv", Title: "Malin Kundang (jv)", Synopsis: "Bocah durhaka"},
+			found: true,
+		},
+		{
+			name: "synopsis falls back on its own",
+			translations: []StoryTranslation{
+				{StoryID: 1, Locale: "jv", Title: "Malin Kundang (jv)"},
+				{StoryID: 1, Locale: "en", Title: "Malin Kundang", Synopsis: "The ungrateful son"},
+				{StoryID: 1, Locale: "id", Title: "Malin Kundang", Synopsis: "Anak durhaka"},
+			},
+			want:  StoryTranslation{StoryID: 1, Locale: "jv", Title: "Malin Kundang (jv)", Synopsis: "Anak durhaka"},
+			found: true,
+		},
+		{
+			name: "title falls back on its own",
+			translations: []StoryTranslation{
+				{StoryID: 1, Locale: "jv", Synopsis: "Bocah durhaka"},
+				{StoryID: 1, Locale: "en", Title: "Malin Kundang", Synopsis: "The ungrateful son"},
+			},
+			want:  StoryTranslation{StoryID: 1, Locale: "en", Title: "Malin Kundang", Synopsis: "Bocah durhaka"},
+			found: true,
+		},
+		{
+			name: "no title anywhere",
+			translations: []StoryTranslation{
+				{StoryID: 1, Locale: "jv", Synopsis: "Bocah durhaka"},
+			},
+			found: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := l.pickStoryTitles(tt.translations)[1]
+			if ok != tt.found {
+				t.Fatalf("found = %v, want %v", ok, tt.found)
+			}
+			if ok && (got.Locale != tt.want.Locale || got.Title != tt.want.Title || got.Synopsis != tt.want.Synopsis) {
+				t.Fatalf("pickStoryTitles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Media Model
+
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"kisahloka_be/db"
+	"kisahloka_be/imaging"
+	"kisahloka_be/storage"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ImageMimeTypes maps the accepted image types to the extension stored files get
+var ImageMimeTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// MaxImageFileSize is the largest thumbnail or page image accepted, in bytes
+const MaxImageFileSize = 5 << 20
+
+// MaxImageDimension is the largest width or height of an uploaded image, in
+// pixels. A small file can still declare huge dimensions, so this is checked
+// before the pixels are decoded.
+const MaxImageDimension = 8192
+
+// ErrUnsupportedImage is returned for uploads that are not a readable JPEG, PNG
+// or WebP image
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+// ErrImageTooLarge is returned for images over MaxImageDimension
+var ErrImageTooLarge = errors.New("image is too large")
+
+// StoredMedia describes a file written to the media storage
+type StoredMedia struct {
+	Key      string `json:"key"`
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	FileSize int64  `json:"file_size"`
+	SHA256   string `json:"sha256"`
+}
+
+// storeMediaFile copies at most maxSize bytes from r into the media storage under
+// prefix/<sha256><ext>. Naming files after their content means uploading the
+// same file twice stores it once and the URL changes whenever the content does.
+func storeMediaFile(prefix, ext, mimeType string, r io.Reader, maxSize int64) (StoredMedia, error) {
+	var media StoredMedia
+
+	// Spool to a temporary file because the hash is only known at the end
+	tmp, err := os.CreateTemp("", "kisahloka-upload-*")
+	if err != nil {
+		return media, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return media, err
+	}
+	if size > maxSize {
+		return media, fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+	if size == 0 {
+		return media, fmt.Errorf("file is empty")
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return media, err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	key := fmt.Sprintf("%s/%s/%s%s", prefix, sum[:2], sum, ext)
+
+	store := storage.GetStorage()
+	if err := store.Put(key, tmp, size, mimeType); err != nil {
+		return media, err
+	}
+
+	media = StoredMedia{
+		Key:      key,
+		URL:      store.URL(key),
+		MimeType: mimeType,
+		FileSize: size,
+		SHA256:   sum,
+	}
+
+	return media, nil
+}
+
+// detectImageType sniffs the first bytes of an upload and returns its MIME type
+// when it is an accepted image. The reader returned still yields the whole file.
+func detectImageType(r io.Reader) (string, io.Reader, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", nil, err
+	}
+	head = head[:n]
+
+	mimeType := http.DetectContentType(head)
+	if _, ok := ImageMimeTypes[mimeType]; !ok {
+		return "", nil, fmt.Errorf("%w: type %q, expected JPEG, PNG or WebP", ErrUnsupportedImage, mimeType)
+	}
+
+	return mimeType, io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// storeImage validates an uploaded image, writes it to the media storage and
+// generates its resized variants
+func storeImage(prefix string, file io.Reader) (StoredMedia, error) {
+	mimeType, file, err := detectImageType(file)
+	if err != nil {
+		return StoredMedia{}, err
+	}
+
+	// Images are small enough to keep in memory for decoding
+	data, err := io.ReadAll(io.LimitReader(file, MaxImageFileSize+1))
+	if err != nil {
+		return StoredMedia{}, err
+	}
+
+	config, _, err := imaging.DecodeConfig(data)
+	if err != nil {
+		return StoredMedia{}, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if config.Width > MaxImageDimension || config.Height > MaxImageDimension {
+		return StoredMedia{}, fmt.Errorf("%w: %dx%d pixels, at most %d on each side", ErrImageTooLarge, config.Width, config.Height, MaxImageDimension)
+	}
+
+	media, err := storeMediaFile(prefix, ImageMimeTypes[mimeType], mimeType, bytes.NewReader(data), MaxImageFileSize)
+	if err != nil {
+		return media, err
+	}
+
+	if err := processImage(media, data); err != nil {
+		// Nothing points at the original yet, so do not leave it behind
+		storage.GetStorage().Delete(media.Key)
+		return media, err
+	}
+
+	return media, nil
+}
+
+// SaveStoryThumbnail uploads a thumbnail and points the story's thumbnail_image at it
+func SaveStoryThumbnail(storyID int, file io.Reader) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	var exists int
+	if err := con.QueryRow("SELECT COUNT(*) FROM story WHERE story_id = ?", storyID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	media, err := storeImage("images/thumbnails", file)
+	if err != nil {
+		return res, err
+	}
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	if err := ensureStoryRevisionBaseline(tx, storyID); err != nil {
+		return res, err
+	}
+
+	_, err = tx.Exec("UPDATE story SET thumbnail_image = ?, updated_at = ? WHERE story_id = ?", media.URL, time.Now(), storyID)
+	if err != nil {
+		return res, err
+	}
+
+	if _, err := recordStoryRevision(tx, storyID, "Thumbnail uploaded"); err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id": storyID,
+		"media":    media,
+	}
+
+	return res, nil
+}
+
+// SaveStoryContentImage uploads the illustration of one page and points the
+// page's image at it
+func SaveStoryContentImage(storyID, order int, file io.Reader) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	var exists int
+	err := con.QueryRow("SELECT COUNT(*) FROM story_content WHERE story_id = ? AND `order` = ?", storyID, order).Scan(&exists)
+	if err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	media, err := storeImage("images/pages", file)
+	if err != nil {
+		return res, err
+	}
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	if err := ensureStoryRevisionBaseline(tx, storyID); err != nil {
+		return res, err
+	}
+
+	if _, err := tx.Exec("UPDATE story_content SET image = ? WHERE story_id = ? AND `order` = ?", media.URL, storyID, order); err != nil {
+		return res, err
+	}
+	if _, err := tx.Exec("UPDATE story SET updated_at = ? WHERE story_id = ?", time.Now(), storyID); err != nil {
+		return res, err
+	}
+
+	if _, err := recordStoryRevision(tx, storyID, fmt.Sprintf("Image of page %d uploaded", order)); err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id": storyID,
+		"order":    order,
+		"media":    media,
+	}
+
+	return res, nil
+}
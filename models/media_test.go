@@ -0,0 +1,41 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// The uploads below are rejected before anything reaches the media storage
+func TestStoreImageRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"not an image", []byte("just some text"), ErrUnsupportedImage},
+		{"truncated PNG", encodePNG(t, 4, 4)[:20], ErrUnsupportedImage},
+		{"too wide", encodePNG(t, MaxImageDimension+1, 1), ErrImageTooLarge},
+		{"too tall", encodePNG(t, 1, MaxImageDimension+1), ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := storeImage("images/test", bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("storeImage() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
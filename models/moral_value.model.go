@@ -0,0 +1,237 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+)
+
+// ErrUnknownMoralValue is returned when a story is given a moral value that does not exist
+var ErrUnknownMoralValue = errors.New("unknown moral value")
+
+type MoralValue struct {
+	MoralValueID int       `json:"moral_value_id"`
+	ValueName    string    `json:"value_name"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// StoryMoralValue is a value taught by a story, as shown on the story
+type StoryMoralValue struct {
+	MoralValueID int    `json:"moral_value_id"`
+	ValueName    string `json:"value_name"`
+}
+
+func GetAllMoralValues() ([]MoralValue, error) {
+	var moralValues []MoralValue
+
+	db := db.CreateCon()
+
+	rows, err := db.Query("SELECT moral_value_id, value_name, created_at, updated_at FROM moral_value ORDER BY value_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var moralValue MoralValue
+		err := rows.Scan(&moralValue.MoralValueID, &moralValue.ValueName, &moralValue.CreatedAt, &moralValue.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		moralValues = append(moralValues, moralValue)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return moralValues, nil
+}
+
+func GetMoralValueDetail(moralValueID int) (MoralValue, error) {
+	var moralValue MoralValue
+
+	db := db.CreateCon()
+
+	err := db.QueryRow("SELECT moral_value_id, value_name, created_at, updated_at FROM moral_value WHERE moral_value_id = ?", moralValueID).Scan(
+		&moralValue.MoralValueID, &moralValue.ValueName, &moralValue.CreatedAt, &moralValue.UpdatedAt,
+	)
+	if err != nil {
+		return MoralValue{}, err
+	}
+
+	return moralValue, nil
+}
+
+func CreateMoralValue(valueName string) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("INSERT INTO moral_value (value_name, created_at, updated_at) VALUES (?, ?, ?)",
+		valueName, time.Now(), time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func UpdateMoralValue(moralValueID int, valueName string) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("UPDATE moral_value SET value_name = ?, updated_at = ? WHERE moral_value_id = ?",
+		valueName, time.Now(), moralValueID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func DeleteMoralValue(moralValueID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM moral_value WHERE moral_value_id = ?", moralValueID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// SetStoryMoralValues replaces the values a story teaches
+func SetStoryMoralValues(storyID int, moralValueIDs []int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM story WHERE story_id = ?", storyID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	uniqueIDs := []int{}
+	seen := make(map[int]bool)
+	for _, moralValueID := range moralValueIDs {
+		if moralValueID <= 0 {
+			return res, fmt.Errorf("%w: %d", ErrUnknownMoralValue, moralValueID)
+		}
+		if !seen[moralValueID] {
+			seen[moralValueID] = true
+			uniqueIDs = append(uniqueIDs, moralValueID)
+		}
+	}
+
+	if len(uniqueIDs) > 0 {
+		placeholders := make([]string, len(uniqueIDs))
+		args := make([]interface{}, len(uniqueIDs))
+		for i, moralValueID := range uniqueIDs {
+			placeholders[i] = "?"
+			args[i] = moralValueID
+		}
+
+		var found int
+		err := tx.QueryRow("SELECT COUNT(*) FROM moral_value WHERE moral_value_id IN ("+strings.Join(placeholders, ", ")+")", args...).Scan(&found)
+		if err != nil {
+			return res, err
+		}
+		if found != len(uniqueIDs) {
+			return res, fmt.Errorf("%w: moral_value_ids %v include one that does not exist", ErrUnknownMoralValue, uniqueIDs)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM story_moral_value WHERE story_id = ?", storyID); err != nil {
+		return res, err
+	}
+
+	for _, moralValueID := range uniqueIDs {
+		if _, err := tx.Exec("INSERT INTO story_moral_value (story_id, moral_value_id) VALUES (?, ?)", storyID, moralValueID); err != nil {
+			return res, err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE story SET updated_at = ? WHERE story_id = ?", time.Now(), storyID); err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+		return res, err
+	}
+
+	moralValues, err := getStoryMoralValues(tx, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id":     storyID,
+		"moral_values": moralValues,
+	}
+
+	return res, nil
+}
+
+// getStoryMoralValues lists the values a story teaches
+func getStoryMoralValues(ex dbExecutor, storyID int) ([]StoryMoralValue, error) {
+	moralValues := []StoryMoralValue{}
+
+	rows, err := ex.Query("SELECT m.moral_value_id, m.value_name FROM story_moral_value smv JOIN moral_value m ON smv.moral_value_id = m.moral_value_id WHERE smv.story_id = ? ORDER BY m.value_name", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var moralValue StoryMoralValue
+		if err := rows.Scan(&moralValue.MoralValueID, &moralValue.ValueName); err != nil {
+			return nil, err
+		}
+		moralValues = append(moralValues, moralValue)
+	}
+
+	return moralValues, rows.Err()
+}
+
+// moralValueCondition matches stories teaching any of the given values
+func moralValueCondition(moralValueIDs []int) (string, []interface{}) {
+	placeholders := make([]string, len(moralValueIDs))
+	args := make([]interface{}, len(moralValueIDs))
+	for i, moralValueID := range moralValueIDs {
+		placeholders[i] = "?"
+		args[i] = moralValueID
+	}
+	return "EXISTS (SELECT 1 FROM story_moral_value smv WHERE smv.story_id = s.story_id AND smv.moral_value_id IN (" + strings.Join(placeholders, ", ") + "))", args
+}
@@ -0,0 +1,362 @@
+// Notification Model
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kisahloka_be/db"
+	"kisahloka_be/notify"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const NotificationKindNewStory = "new_story"
+
+// pushTimeout bounds how long one batch of pushes waits on the push provider
+const pushTimeout = 30 * time.Second
+
+// Notification is an entry in a user's in-app inbox
+type Notification struct {
+	NotificationID int        `json:"notification_id"`
+	UserID         int        `json:"user_id"`
+	StoryID        *int       `json:"story_id"`
+	Kind           string     `json:"kind"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	IsRead         bool       `json:"is_read"`
+	ReadAt         *time.Time `json:"read_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// originAncestorsQuery selects an origin and every origin above it, so following
+// an island covers the stories of its provinces
+const originAncestorsQuery = "WITH RECURSIVE ancestors AS (SELECT origin_id, parent_id FROM origin WHERE origin_id = ? UNION SELECT o.origin_id, o.parent_id FROM origin o JOIN ancestors a ON o.origin_id = a.parent_id) SELECT origin_id FROM ancestors"
+
+// GetNotifications lists a page of a user's inbox, newest first, with the number of unread notifications
+func GetNotifications(userID, page, pageSize int, unreadOnly bool) (Response, error) {
+	var res Response
+	var meta Meta
+	notifications := []Notification{}
+
+	con := db.CreateCon()
+
+	whereClause := " WHERE user_id = ?"
+	if unreadOnly {
+		whereClause += " AND read_at IS NULL"
+	}
+
+	var unreadCount int
+	if err := con.QueryRow("SELECT COUNT(*) FROM notification WHERE user_id = ? AND read_at IS NULL", userID).Scan(&unreadCount); err != nil {
+		return res, err
+	}
+
+	var totalItems int
+	if err := con.QueryRow("SELECT COUNT(*) FROM notification"+whereClause, userID).Scan(&totalItems); err != nil {
+		return res, err
+	}
+
+	meta.Limit = pageSize
+	meta.Page = page
+	meta.TotalPages = calculateTotalPages(totalItems, pageSize)
+	meta.TotalItems = totalItems
+
+	if totalItems > 0 {
+		// Check if the requested page is greater than the total number of pages
+		if page > meta.TotalPages {
+			return res, fmt.Errorf("requested page (%d) exceeds total number of pages (%d)", page, meta.TotalPages)
+		}
+
+		// Load the UTC+8 time zone
+		loc, err := time.LoadLocation("Asia/Shanghai")
+		if err != nil {
+			return res, err
+		}
+
+		// Calculate the offset based on the page number and page size
+		offset := (page - 1) * pageSize
+
+		rows, err := con.Query("SELECT notification_id, user_id, story_id, kind, title, body, read_at, created_at FROM notification"+whereClause+" ORDER BY created_at DESC, notification_id DESC LIMIT ? OFFSET ?", userID, pageSize, offset)
+		if err != nil {
+			return res, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var notification Notification
+			var storyID sql.NullInt64
+			var readAt sql.NullTime
+			if err := rows.Scan(&notification.NotificationID, &notification.UserID, &storyID, &notification.Kind, &notification.Title, &notification.Body, &readAt, &notification.CreatedAt); err != nil {
+				return res, err
+			}
+
+			if storyID.Valid {
+				id := int(storyID.Int64)
+				notification.StoryID = &id
+			}
+
+			// Convert time fields to UTC+8 (Asia/Shanghai) before including them in the response
+			if readAt.Valid {
+				t := readAt.Time.In(loc)
+				notification.ReadAt = &t
+				notification.IsRead = true
+			}
+			notification.CreatedAt = notification.CreatedAt.In(loc)
+
+			notifications = append(notifications, notification)
+		}
+
+		if err := rows.Err(); err != nil {
+			return res, err
+		}
+	}
+
+	res.Data = map[string]interface{}{
+		"notifications": notifications,
+		"unread_count":  unreadCount,
+		"meta":          meta,
+	}
+
+	return res, nil
+}
+
+// MarkNotificationsRead marks the given notifications of a user as read, or
+// all of them when notificationIDs is empty
+func MarkNotificationsRead(userID int, notificationIDs []int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	sqlStatement := "UPDATE notification SET read_at = ? WHERE user_id = ? AND read_at IS NULL"
+	args := []interface{}{time.Now(), userID}
+	if len(notificationIDs) > 0 {
+		placeholders := make([]string, len(notificationIDs))
+		for i, notificationID := range notificationIDs {
+			placeholders[i] = "?"
+			args = append(args, notificationID)
+		}
+		sqlStatement += " AND notification_id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	result, err := con.Exec(sqlStatement, args...)
+	if err != nil {
+		return res, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"rows_affected": rowsAffected,
+	}
+
+	return res, nil
+}
+
+// SavePushToken registers a device token of a user. A token moves to the
+// latest user that registers it, e.g. after signing in with another account.
+func SavePushToken(userID int, token, platform string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	db := db.CreateCon()
+
+	now := time.Now()
+	_, err := db.Exec("INSERT INTO push_token (user_id, token, platform, created_at, updated_at) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE user_id = VALUES(user_id), platform = VALUES(platform), updated_at = VALUES(updated_at)",
+		userID, token, platform, now, now,
+	)
+	return err
+}
+
+func DeletePushToken(token string) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM push_token WHERE token = ?", token)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// notifyStoryPublished puts a notification in the inbox of every follower of a
+// story's genres and origin. It runs in the transaction that publishes the
+// story; the push dispatcher sends the pushes afterwards. Stories not visible
+// to readers yet are skipped, and a story whose origin is gone only reaches the
+// genre followers. The title is stored rendered in Indonesian, as pushes carry
+// no reader locale.
+func notifyStoryPublished(ex dbExecutor, storyID int) error {
+	var title string
+	var originID sql.NullInt64
+	var originName sql.NullString
+	err := ex.QueryRow("SELECT s.title, o.origin_id, o.origin_name FROM story s LEFT JOIN origin o ON s.origin_id = o.origin_id WHERE s.story_id = ? AND "+publishedStoryCondition, storyID).Scan(&title, &originID, &originName)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	heading := "Cerita baru"
+	followers := "(f.target_type = ? AND f.target_id IN (SELECT sg.genre_id FROM story_genre sg WHERE sg.story_id = ?))"
+	args := []interface{}{FollowTargetGenre, storyID}
+	if originID.Valid {
+		heading = "Cerita baru dari " + originName.String
+		followers += " OR (f.target_type = ? AND f.target_id IN (" + originAncestorsQuery + "))"
+		args = append(args, FollowTargetOrigin, originID.Int64)
+	}
+
+	// The unique key skips users already told about this story
+	_, err = ex.Exec(`
+		INSERT IGNORE INTO notification (user_id, story_id, kind, title, body, created_at)
+		SELECT DISTINCT f.user_id, ?, ?, ?, ?, ? FROM user_follow f
+		WHERE `+followers,
+		append([]interface{}{storyID, NotificationKindNewStory, heading, title, time.Now()}, args...)...,
+	)
+	return err
+}
+
+// pendingPush is an inbox notification waiting to be pushed to the devices of its user
+type pendingPush struct {
+	NotificationID int
+	UserID         int
+	StoryID        *int
+	Kind           string
+	Title          string
+	Body           string
+	Tokens         []string
+}
+
+// DeliverPendingPushes pushes up to limit notifications that have not been
+// pushed yet and returns how many messages were sent. Tokens the provider no
+// longer knows are forgotten. Other send errors are logged and not retried, as
+// the notification is still in the inbox.
+func DeliverPendingPushes(limit int) (int, error) {
+	con := db.CreateCon()
+
+	rows, err := con.Query("SELECT notification_id, user_id, story_id, kind, title, body FROM notification WHERE pushed_at IS NULL ORDER BY notification_id LIMIT ?", limit)
+	if err != nil {
+		return 0, err
+	}
+	var pushes []pendingPush
+	for rows.Next() {
+		var push pendingPush
+		var storyID sql.NullInt64
+		if err := rows.Scan(&push.NotificationID, &push.UserID, &storyID, &push.Kind, &push.Title, &push.Body); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if storyID.Valid {
+			id := int(storyID.Int64)
+			push.StoryID = &id
+		}
+		pushes = append(pushes, push)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(pushes) == 0 {
+		return 0, nil
+	}
+
+	userIDs := make([]interface{}, 0, len(pushes))
+	notificationIDs := make([]interface{}, 0, len(pushes))
+	seen := make(map[int]bool)
+	for _, push := range pushes {
+		notificationIDs = append(notificationIDs, push.NotificationID)
+		if !seen[push.UserID] {
+			seen[push.UserID] = true
+			userIDs = append(userIDs, push.UserID)
+		}
+	}
+
+	tokensByUser := make(map[int][]string)
+	rows, err = con.Query("SELECT user_id, token FROM push_token WHERE user_id IN ("+placeholderList(len(userIDs))+")", userIDs...)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var userID int
+		var token string
+		if err := rows.Scan(&userID, &token); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		tokensByUser[userID] = append(tokensByUser[userID], token)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for i := range pushes {
+		pushes[i].Tokens = tokensByUser[pushes[i].UserID]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+
+	sent, invalidTokens := sendPushes(ctx, notify.GetSender(), pushes)
+
+	for _, token := range invalidTokens {
+		if _, err := con.Exec("DELETE FROM push_token WHERE token = ?", token); err != nil {
+			return sent, err
+		}
+	}
+
+	args := append([]interface{}{time.Now()}, notificationIDs...)
+	if _, err := con.Exec("UPDATE notification SET pushed_at = ? WHERE notification_id IN ("+placeholderList(len(notificationIDs))+")", args...); err != nil {
+		return sent, err
+	}
+
+	return sent, nil
+}
+
+// sendPushes sends every notification to each device token of its user. It
+// returns how many messages were sent and the tokens the provider rejected.
+func sendPushes(ctx context.Context, sender notify.Sender, pushes []pendingPush) (int, []string) {
+	sent := 0
+	var invalidTokens []string
+	for _, push := range pushes {
+		data := map[string]string{"kind": push.Kind}
+		if push.StoryID != nil {
+			data["story_id"] = strconv.Itoa(*push.StoryID)
+		}
+
+		for _, token := range push.Tokens {
+			err := sender.Send(ctx, notify.Message{
+				Token: token,
+				Title: push.Title,
+				Body:  push.Body,
+				Data:  data,
+			})
+			if err == notify.ErrInvalidToken {
+				invalidTokens = append(invalidTokens, token)
+				continue
+			}
+			if err != nil {
+				log.Printf("push notification %d: %v", push.NotificationID, err)
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, invalidTokens
+}
+
+// placeholderList returns n comma-separated "?" placeholders
+func placeholderList(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"context"
+	"kisahloka_be/notify"
+	"reflect"
+	"testing"
+)
+
+func TestSendPushes(t *testing.T) {
+	storyID := 42
+	fake := &notify.Fake{Invalid: map[string]bool{"uninstalled": true}}
+
+	pushes := []pendingPush{
+		{NotificationID: 1, UserID: 7, StoryID: &storyID, Kind: NotificationKindNewStory, Title: "Cerita baru dari Jawa Barat", Body: "Sangkuriang", Tokens: []string{"phone", "uninstalled"}},
+		{NotificationID: 2, UserID: 8, StoryID: &storyID, Kind: NotificationKindNewStory, Title: "Cerita baru dari Jawa Barat", Body: "Sangkuriang"},
+		{NotificationID: 3, UserID: 9, Kind: "announcement", Title: "Halo", Body: "Fitur baru", Tokens: []string{"tablet"}},
+	}
+
+	sent, invalid := sendPushes(context.Background(), fake, pushes)
+	if sent != 2 {
+		t.Fatalf("sent = %d, want 2", sent)
+	}
+	if !reflect.DeepEqual(invalid, []string{"uninstalled"}) {
+		t.Fatalf("invalid tokens = %v, want [uninstalled]", invalid)
+	}
+
+	want := []notify.Message{
+		{
+			Token: "phone",
+			Title: "Cerita baru dari Jawa Barat",
+			Body:  "Sangkuriang",
+			Data:  map[string]string{"kind": NotificationKindNewStory, "story_id": "42"},
+		},
+		{
+			Token: "tablet",
+			Title: "Halo",
+			Body:  "Fitur baru",
+			Data:  map[string]string{"kind": "announcement"},
+		},
+	}
+	if got := fake.Sent(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sent messages = %+v, want %+v", got, want)
+	}
+}
+
+func TestSendPushesWithoutTokens(t *testing.T) {
+	fake := &notify.Fake{}
+	sent, invalid := sendPushes(context.Background(), fake, []pendingPush{{NotificationID: 1, UserID: 7, Title: "Halo"}})
+	if sent != 0 || invalid != nil || len(fake.Sent()) != 0 {
+		t.Fatalf("sendPushes() = %d, %v with %d messages; want nothing sent", sent, invalid, len(fake.Sent()))
+	}
+}
@@ -2,21 +2,158 @@
 package models
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"kisahloka_be/db"
 	"reflect"
 	"time"
 )
 
+// ErrInvalidOrigin is returned for origin fields that are out of range or would
+// break the origin hierarchy
+var ErrInvalidOrigin = errors.New("invalid origin")
+
 type Origin struct {
 	OriginID   int       `json:"origin_id"`
 	OriginName string    `json:"origin_name"`
+	ParentID   *int      `json:"parent_id"`
+	Level      string    `json:"level"`
+	Province   string    `json:"province"`
+	Island     string    `json:"island"`
+	Latitude   *float64  `json:"latitude"`
+	Longitude  *float64  `json:"longitude"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
-// GetAllOrigins retrieves all origins with pagination and optional keyword filtering
-func GetAllOrigins(page, pageSize int, keyword string) (Response, error) {
+// originColumns lists the origin columns in the order scanned by the origin queries
+const originColumns = "origin_id, origin_name, parent_id, COALESCE(level, ''), COALESCE(province, ''), COALESCE(island, ''), latitude, longitude, created_at, updated_at"
+
+const (
+	OriginLevelIsland      = "island"
+	OriginLevelProvince    = "province"
+	OriginLevelEthnicGroup = "ethnic_group"
+)
+
+// OriginNode is an origin in the origin tree. StoryCount counts the published
+// stories of the origin itself, TotalStoryCount adds those of its descendants.
+type OriginNode struct {
+	OriginID        int          `json:"origin_id"`
+	OriginName      string       `json:"origin_name"`
+	Level           string       `json:"level"`
+	StoryCount      int          `json:"story_count"`
+	TotalStoryCount int          `json:"total_story_count"`
+	Children        []OriginNode `json:"children"`
+}
+
+// originDescendantsQuery selects the IDs of an origin and all origins below it.
+// UNION rather than UNION ALL stops the recursion should a cycle ever slip in.
+const originDescendantsQuery = "WITH RECURSIVE descendants AS (SELECT origin_id FROM origin WHERE origin_id = ? UNION SELECT o.origin_id FROM origin o JOIN descendants d ON o.parent_id = d.origin_id) SELECT origin_id FROM descendants"
+
+// IsValidOriginLevel reports whether level is one of the origin levels or empty
+func IsValidOriginLevel(level string) bool {
+	switch level {
+	case "", OriginLevelIsland, OriginLevelProvince, OriginLevelEthnicGroup:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateOriginParent checks that parentID exists and that making it the parent
+// of originID would not create a cycle. originID is 0 for a new origin. Inside a
+// transaction the origins above parentID stay locked until it ends, so a
+// concurrent move cannot form a cycle with this one.
+func validateOriginParent(ex dbExecutor, originID, parentID int) error {
+	current := parentID
+	for depth := 0; ; depth++ {
+		if current == originID {
+			return fmt.Errorf("%w: origin %d cannot be placed under its own descendant %d", ErrInvalidOrigin, originID, parentID)
+		}
+		// Any real hierarchy is a handful of levels deep
+		if depth > 100 {
+			return fmt.Errorf("%w: origin hierarchy above %d is too deep", ErrInvalidOrigin, parentID)
+		}
+
+		var next sql.NullInt64
+		err := ex.QueryRow("SELECT parent_id FROM origin WHERE origin_id = ? FOR UPDATE", current).Scan(&next)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: origin %d does not exist", ErrInvalidOrigin, current)
+		}
+		if err != nil {
+			return err
+		}
+		if !next.Valid {
+			return nil
+		}
+		current = int(next.Int64)
+	}
+}
+
+// GeoJSONFeatureCollection is a GeoJSON (RFC 7946) collection of point features
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONPoint holds its coordinates as [longitude, latitude]
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// validateOriginCoordinates checks that latitude and longitude are given together
+// and lie on the globe
+func validateOriginCoordinates(latitude, longitude *float64) error {
+	if (latitude == nil) != (longitude == nil) {
+		return fmt.Errorf("%w: latitude and longitude must be given together", ErrInvalidOrigin)
+	}
+	if latitude != nil && (*latitude < -90 || *latitude > 90) {
+		return fmt.Errorf("%w: latitude %v is out of range", ErrInvalidOrigin, *latitude)
+	}
+	if longitude != nil && (*longitude < -180 || *longitude > 180) {
+		return fmt.Errorf("%w: longitude %v is out of range", ErrInvalidOrigin, *longitude)
+	}
+	return nil
+}
+
+// validateOriginUpdateCoordinates checks the latitude and longitude of an origin
+// update. Both must be sent, as numbers or null, or neither.
+func validateOriginUpdateCoordinates(updateFields map[string]interface{}) error {
+	latitudeValue, hasLatitude := updateFields["latitude"]
+	longitudeValue, hasLongitude := updateFields["longitude"]
+	if !hasLatitude && !hasLongitude {
+		return nil
+	}
+	if hasLatitude != hasLongitude {
+		return fmt.Errorf("%w: latitude and longitude must be updated together", ErrInvalidOrigin)
+	}
+
+	coordinates := [2]*float64{}
+	for i, value := range []interface{}{latitudeValue, longitudeValue} {
+		if value == nil {
+			continue
+		}
+		coordinate, isNumber := value.(float64)
+		if !isNumber {
+			return fmt.Errorf("%w: coordinate %v is not a number", ErrInvalidOrigin, value)
+		}
+		coordinates[i] = &coordinate
+	}
+
+	return validateOriginCoordinates(coordinates[0], coordinates[1])
+}
+
+// GetAllOrigins retrieves all origins with pagination and optional keyword filtering,
+// with their names in the given locale, if any
+func GetAllOrigins(page, pageSize int, keyword, locale string) (Response, error) {
 	var res Response
 	var arrobj reflect.Value
 	var meta Meta
@@ -67,18 +204,26 @@ func GetAllOrigins(page, pageSize int, keyword string) (Response, error) {
 
 	// Calculate the offset based on the page number and page size
 	offset := (page - 1) * pageSize
-	sqlStatement := fmt.Sprintf("SELECT * FROM origin %s LIMIT %d OFFSET %d", whereClause, pageSize, offset)
+	sqlStatement := fmt.Sprintf("SELECT %s FROM origin %s LIMIT %d OFFSET %d", originColumns, whereClause, pageSize, offset)
 	rows, err := con.Query(sqlStatement)
 	if err != nil {
 		return res, err
 	}
 	defer rows.Close()
 
+	l := newLocalizer(locale)
+
 	for rows.Next() {
 		var obj Origin
 		err := rows.Scan(
 			&obj.OriginID,
 			&obj.OriginName,
+			&obj.ParentID,
+			&obj.Level,
+			&obj.Province,
+			&obj.Island,
+			&obj.Latitude,
+			&obj.Longitude,
 			&obj.CreatedAt,
 			&obj.UpdatedAt,
 		)
@@ -90,6 +235,12 @@ func GetAllOrigins(page, pageSize int, keyword string) (Response, error) {
 		obj.CreatedAt = obj.CreatedAt.In(loc)
 		obj.UpdatedAt = obj.UpdatedAt.In(loc)
 
+		if l != nil {
+			if obj.OriginName, err = l.taxonomyName(TaxonomyOrigin, obj.OriginID, obj.OriginName); err != nil {
+				return res, err
+			}
+		}
+
 		if !arrobj.IsValid() {
 			arrobj = reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(obj)), 0, 0)
 		}
@@ -117,13 +268,19 @@ func GetOriginDetail(originID int) (Response, error) {
 
 	con := db.CreateCon()
 
-	sqlStatement := "SELECT * FROM origin WHERE origin_id = ?"
+	sqlStatement := "SELECT " + originColumns + " FROM origin WHERE origin_id = ?"
 
 	row := con.QueryRow(sqlStatement, originID)
 
 	err := row.Scan(
 		&originDetail.OriginID,
 		&originDetail.OriginName,
+		&originDetail.ParentID,
+		&originDetail.Level,
+		&originDetail.Province,
+		&originDetail.Island,
+		&originDetail.Latitude,
+		&originDetail.Longitude,
 		&originDetail.CreatedAt,
 		&originDetail.UpdatedAt,
 	)
@@ -150,12 +307,30 @@ func GetOriginDetail(originID int) (Response, error) {
 }
 
 // CreateOrigin creates a new origin
-func CreateOrigin(originName string) (Response, error) {
+func CreateOrigin(origin Origin) (Response, error) {
 	var res Response
 
+	if err := validateOriginCoordinates(origin.Latitude, origin.Longitude); err != nil {
+		return res, err
+	}
+	if !IsValidOriginLevel(origin.Level) {
+		return res, fmt.Errorf("%w: level %q", ErrInvalidOrigin, origin.Level)
+	}
+
 	con := db.CreateCon()
 
-	sqlStatement := "INSERT INTO origin (origin_name, created_at, updated_at) VALUES (?, ?, ?)"
+	if origin.ParentID != nil {
+		if err := validateOriginParent(con, 0, *origin.ParentID); err != nil {
+			return res, err
+		}
+	}
+
+	var level *string
+	if origin.Level != "" {
+		level = &origin.Level
+	}
+
+	sqlStatement := "INSERT INTO origin (origin_name, parent_id, level, province, island, latitude, longitude, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
 	stmt, err := con.Prepare(sqlStatement)
 
@@ -173,7 +348,13 @@ func CreateOrigin(originName string) (Response, error) {
 	updated_at := time.Now()
 
 	result, err := stmt.Exec(
-		originName,
+		origin.OriginName,
+		origin.ParentID,
+		level,
+		origin.Province,
+		origin.Island,
+		origin.Latitude,
+		origin.Longitude,
 		created_at,
 		updated_at,
 	)
@@ -200,6 +381,41 @@ func CreateOrigin(originName string) (Response, error) {
 func UpdateOrigin(originID int, updateFields map[string]interface{}) (Response, error) {
 	var res Response
 
+	if err := validateOriginUpdateCoordinates(updateFields); err != nil {
+		return res, err
+	}
+
+	if level, ok := updateFields["level"]; ok && level != nil {
+		if levelName, isString := level.(string); !isString || !IsValidOriginLevel(levelName) {
+			return res, fmt.Errorf("%w: level %v", ErrInvalidOrigin, level)
+		}
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	// Lock the origin first so two origins cannot be moved under each other at once
+	var lockedID int
+	if err := tx.QueryRow("SELECT origin_id FROM origin WHERE origin_id = ? FOR UPDATE", originID).Scan(&lockedID); err != nil {
+		return res, err
+	}
+
+	// Moving an origin must keep the hierarchy a tree
+	if parent, ok := updateFields["parent_id"]; ok && parent != nil {
+		parentID, isNumber := parent.(float64)
+		if !isNumber {
+			return res, fmt.Errorf("%w: parent_id %v", ErrInvalidOrigin, parent)
+		}
+		if err := validateOriginParent(tx, originID, int(parentID)); err != nil {
+			return res, err
+		}
+	}
+
 	// Load the UTC+8 time zone
 	loc, err := time.LoadLocation("Asia/Shanghai")
 	if err != nil {
@@ -210,8 +426,6 @@ func UpdateOrigin(originID int, updateFields map[string]interface{}) (Response,
 	updateFields["updated_at"] = time.Now().In(loc)
 	updated_at := updateFields["updated_at"]
 
-	con := db.CreateCon()
-
 	// Construct the SET part of the SQL statement dynamically
 	setStatement := "SET "
 	values := []interface{}{}
@@ -230,18 +444,17 @@ func UpdateOrigin(originID int, updateFields map[string]interface{}) (Response,
 	sqlStatement := "UPDATE origin " + setStatement + " WHERE origin_id = ?"
 	values = append(values, originID)
 
-	stmt, err := con.Prepare(sqlStatement)
+	result, err := tx.Exec(sqlStatement, values...)
 	if err != nil {
 		return res, err
 	}
 
-	result, err := stmt.Exec(values...)
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return res, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	if err := tx.Commit(); err != nil {
 		return res, err
 	}
 
@@ -285,3 +498,146 @@ func DeleteOrigin(originID int) (Response, error) {
 
 	return res, err
 }
+
+// GetOriginMap returns the origins that have coordinates as GeoJSON point
+// features with the number of published stories from each
+func GetOriginMap(locale string) (GeoJSONFeatureCollection, error) {
+	collection := GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []GeoJSONFeature{},
+	}
+
+	con := db.CreateCon()
+
+	rows, err := con.Query(`
+		SELECT
+			o.origin_id, o.origin_name, o.parent_id, COALESCE(o.level, ''), COALESCE(o.province, ''), COALESCE(o.island, ''), o.latitude, o.longitude,
+			(SELECT COUNT(*) FROM story s WHERE s.origin_id = o.origin_id AND ` + publishedStoryCondition + `)
+		FROM
+			origin o
+		WHERE
+			o.latitude IS NOT NULL AND o.longitude IS NOT NULL
+		ORDER BY
+			o.origin_name`)
+	if err != nil {
+		return collection, err
+	}
+	defer rows.Close()
+
+	l := newLocalizer(locale)
+
+	for rows.Next() {
+		var originID, storyCount int
+		var parentID *int
+		var originName, level, province, island string
+		var latitude, longitude float64
+		if err := rows.Scan(&originID, &originName, &parentID, &level, &province, &island, &latitude, &longitude, &storyCount); err != nil {
+			return collection, err
+		}
+
+		if l != nil {
+			if originName, err = l.taxonomyName(TaxonomyOrigin, originID, originName); err != nil {
+				return collection, err
+			}
+		}
+
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type: "Feature",
+			Geometry: GeoJSONPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{longitude, latitude},
+			},
+			Properties: map[string]interface{}{
+				"origin_id":   originID,
+				"origin_name": originName,
+				"parent_id":   parentID,
+				"level":       level,
+				"province":    province,
+				"island":      island,
+				"story_count": storyCount,
+			},
+		})
+	}
+
+	return collection, rows.Err()
+}
+
+// GetOriginTree returns every origin arranged under its parent, with the number
+// of published stories of each origin and of its whole subtree
+func GetOriginTree(locale string) ([]OriginNode, error) {
+	con := db.CreateCon()
+
+	rows, err := con.Query(`
+		SELECT
+			o.origin_id, o.origin_name, o.parent_id, COALESCE(o.level, ''),
+			(SELECT COUNT(*) FROM story s WHERE s.origin_id = o.origin_id AND ` + publishedStoryCondition + `)
+		FROM
+			origin o
+		ORDER BY
+			o.origin_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	l := newLocalizer(locale)
+
+	nodes := make(map[int]*OriginNode)
+	parents := make(map[int]int)
+	var order []int
+	for rows.Next() {
+		var node OriginNode
+		var parentID sql.NullInt64
+		if err := rows.Scan(&node.OriginID, &node.OriginName, &parentID, &node.Level, &node.StoryCount); err != nil {
+			return nil, err
+		}
+		if l != nil {
+			if node.OriginName, err = l.taxonomyName(TaxonomyOrigin, node.OriginID, node.OriginName); err != nil {
+				return nil, err
+			}
+		}
+		node.Children = []OriginNode{}
+		nodes[node.OriginID] = &node
+		if parentID.Valid {
+			parents[node.OriginID] = int(parentID.Int64)
+		}
+		order = append(order, node.OriginID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]int)
+	var roots []int
+	for _, originID := range order {
+		parentID, ok := parents[originID]
+		if _, parentExists := nodes[parentID]; ok && parentExists {
+			children[parentID] = append(children[parentID], originID)
+		} else {
+			roots = append(roots, originID)
+		}
+	}
+
+	// Build the nested nodes bottom-up so each subtree carries its story total
+	var build func(originID int, depth int) OriginNode
+	build = func(originID int, depth int) OriginNode {
+		node := *nodes[originID]
+		node.TotalStoryCount = node.StoryCount
+		if depth > 100 {
+			return node
+		}
+		for _, childID := range children[originID] {
+			child := build(childID, depth+1)
+			node.TotalStoryCount += child.TotalStoryCount
+			node.Children = append(node.Children, child)
+		}
+		return node
+	}
+
+	tree := []OriginNode{}
+	for _, rootID := range roots {
+		tree = append(tree, build(rootID, 0))
+	}
+
+	return tree, nil
+}
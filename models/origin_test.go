@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateOriginUpdateCoordinates(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]interface{}
+		valid  bool
+	}{
+		{"no coordinates", map[string]interface{}{"origin_name": "Jawa Barat"}, true},
+		{"both set", map[string]interface{}{"latitude": -6.9, "longitude": 107.6}, true},
+		{"both cleared", map[string]interface{}{"latitude": nil, "longitude": nil}, true},
+		{"latitude only", map[string]interface{}{"latitude": -6.9}, false},
+		{"longitude only", map[string]interface{}{"longitude": 107.6}, false},
+		{"one cleared", map[string]interface{}{"latitude": nil, "longitude": 107.6}, false},
+		{"not a number", map[string]interface{}{"latitude": "-6.9", "longitude": 107.6}, false},
+		{"latitude out of range", map[string]interface{}{"latitude": 91.0, "longitude": 107.6}, false},
+		{"longitude out of range", map[string]interface{}{"latitude": -6.9, "longitude": -180.5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOriginUpdateCoordinates(tt.fields)
+			if tt.valid && err != nil {
+				t.Fatalf("validateOriginUpdateCoordinates() = %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidOrigin) {
+				t.Fatalf("validateOriginUpdateCoordinates() = %v, want ErrInvalidOrigin", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Personalized Home Model
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Sections generated per reader rather than configured in home_section
+const (
+	HomeSectionBecauseBookmarked = "because_you_bookmarked"
+	HomeSectionFavoriteOrigins   = "favorite_origins"
+)
+
+// favoriteOriginLimit is how many of the reader's most visited origins feed the
+// favorite origins section
+const favoriteOriginLimit = 3
+
+// personalSectionTitles holds the titles of generated sections per locale. The
+// because-you-bookmarked title takes the bookmarked story's title.
+var personalSectionTitles = map[string]map[string]string{
+	LocaleIndonesian: {
+		HomeSectionContinueReading:   "Lanjutkan Membaca",
+		HomeSectionBecauseBookmarked: "Karena kamu menandai %s",
+		HomeSectionFavoriteOrigins:   "Dari daerah favoritmu",
+	},
+	LocaleEnglish: {
+		HomeSectionContinueReading:   "Continue Reading",
+		HomeSectionBecauseBookmarked: "Because you bookmarked %s",
+		HomeSectionFavoriteOrigins:   "From your favorite regions",
+	},
+}
+
+func personalSectionTitle(sectionType, locale string) string {
+	titles, ok := personalSectionTitles[locale]
+	if !ok {
+		titles = personalSectionTitles[LocaleIndonesian]
+	}
+	return titles[sectionType]
+}
+
+// buildPersonalSections renders the sections generated for a signed-in reader.
+// Continue reading is skipped when the editorial sections already have one.
+func buildPersonalSections(ex dbExecutor, reader HomeReader, l *localizer, withContinueReading bool) ([]HomeSectionBlock, error) {
+	locale := LocaleIndonesian
+	if l != nil {
+		locale = l.locale
+	}
+
+	blocks := []HomeSectionBlock{}
+
+	if withContinueReading {
+		stories, err := getContinueReading(ex, reader, defaultHomeSectionLimit)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, HomeSectionBlock{
+			SectionType: HomeSectionContinueReading,
+			Title:       personalSectionTitle(HomeSectionContinueReading, locale),
+			Stories:     stories,
+		})
+	}
+
+	bookmarked, err := getBecauseBookmarked(ex, reader, l, locale)
+	if err != nil {
+		return nil, err
+	}
+	if bookmarked != nil {
+		blocks = append(blocks, *bookmarked)
+	}
+
+	stories, err := getFavoriteOriginStories(ex, reader)
+	if err != nil {
+		return nil, err
+	}
+	blocks = append(blocks, HomeSectionBlock{
+		SectionType: HomeSectionFavoriteOrigins,
+		Title:       personalSectionTitle(HomeSectionFavoriteOrigins, locale),
+		Stories:     stories,
+	})
+
+	return blocks, nil
+}
+
+// getBecauseBookmarked suggests stories sharing genres with the reader's latest
+// bookmark, most shared genres first. It returns nil without a bookmark.
+func getBecauseBookmarked(ex dbExecutor, reader HomeReader, l *localizer, locale string) (*HomeSectionBlock, error) {
+	condition, readerArg := readerCondition("b.", reader.UserID, reader.UID)
+
+	var storyID int
+	var title string
+	err := ex.QueryRow("SELECT s.story_id, s.title FROM bookmark b JOIN story s ON b.story_id = s.story_id WHERE "+condition+" AND "+publishedStoryCondition+" ORDER BY b.created_at DESC, b.bookmark_id DESC LIMIT 1", readerArg).Scan(&storyID, &title)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if l != nil {
+		titles, err := l.storyTitles([]int{storyID})
+		if err != nil {
+			return nil, err
+		}
+		if translation, ok := titles[storyID]; ok {
+			title = translation.Title
+		}
+	}
+
+	const sharedGenres = "(SELECT COUNT(*) FROM story_genre sg JOIN story_genre bsg ON sg.genre_id = bsg.genre_id WHERE sg.story_id = s.story_id AND bsg.story_id = ?)"
+
+	stories, err := queryStoryHomes(ex, "",
+		"s.story_id <> ? AND "+sharedGenres+" > 0 AND NOT EXISTS (SELECT 1 FROM bookmark b WHERE b.story_id = s.story_id AND "+condition+")",
+		[]interface{}{storyID, storyID, readerArg, storyID},
+		sharedGenres+" DESC, s.read_count DESC",
+		defaultHomeSectionLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HomeSectionBlock{
+		SectionType:    HomeSectionBecauseBookmarked,
+		Title:          fmt.Sprintf(personalSectionTitle(HomeSectionBecauseBookmarked, locale), title),
+		BasedOnStoryID: &storyID,
+		Stories:        stories,
+	}, nil
+}
+
+// getFavoriteOriginStories suggests unread stories from the origins the reader
+// bookmarks and reads the most
+func getFavoriteOriginStories(ex dbExecutor, reader HomeReader) ([]StoryHome, error) {
+	bookmarkCondition, readerArg := readerCondition("b.", reader.UserID, reader.UID)
+	progressCondition, _ := readerCondition("rp.", reader.UserID, reader.UID)
+
+	rows, err := ex.Query(`
+		SELECT s.origin_id
+		FROM (
+			SELECT b.story_id FROM bookmark b WHERE `+bookmarkCondition+`
+			UNION ALL
+			SELECT rp.story_id FROM reading_progress rp WHERE `+progressCondition+`
+		) r
+		JOIN story s ON r.story_id = s.story_id
+		WHERE `+publishedStoryCondition+`
+		GROUP BY s.origin_id
+		ORDER BY COUNT(*) DESC, s.origin_id
+		LIMIT ?`, readerArg, readerArg, favoriteOriginLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	placeholders := []string{}
+	args := []interface{}{}
+	for rows.Next() {
+		var originID int
+		if err := rows.Scan(&originID); err != nil {
+			return nil, err
+		}
+		placeholders = append(placeholders, "?")
+		args = append(args, originID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	return queryStoryHomes(ex, "",
+		"s.origin_id IN ("+strings.Join(placeholders, ", ")+")"+
+			" AND NOT EXISTS (SELECT 1 FROM bookmark b WHERE b.story_id = s.story_id AND "+bookmarkCondition+")"+
+			" AND NOT EXISTS (SELECT 1 FROM reading_progress rp WHERE rp.story_id = s.story_id AND "+progressCondition+")",
+		append(args, readerArg, readerArg),
+		"s.released_date DESC, s.story_id DESC",
+		defaultHomeSectionLimit,
+	)
+}
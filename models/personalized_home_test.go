@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestPersonalSectionTitle(t *testing.T) {
+	tests := []struct {
+		sectionType string
+		locale      string
+		want        string
+	}{
+		{HomeSectionContinueReading, LocaleIndonesian, "Lanjutkan Membaca"},
+		{HomeSectionContinueReading, LocaleEnglish, "Continue Reading"},
+		{HomeSectionFavoriteOrigins, LocaleEnglish, "From your favorite regions"},
+		{HomeSectionBecauseBookmarked, LocaleIndonesian, "Karena kamu menandai %s"},
+		{HomeSectionFavoriteOrigins, "fr", "Dari daerah favoritmu"},
+		{HomeSectionContinueReading, "", "Lanjutkan Membaca"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sectionType+"/"+tt.locale, func(t *testing.T) {
+			if got := personalSectionTitle(tt.sectionType, tt.locale); got != tt.want {
+				t.Errorf("personalSectionTitle(%q, %q) = %q, want %q", tt.sectionType, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
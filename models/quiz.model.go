@@ -0,0 +1,556 @@
+// Quiz Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+)
+
+const (
+	QuizMultipleChoice = "multiple_choice"
+	QuizTrueFalse      = "true_false"
+)
+
+// ErrInvalidQuiz is returned for quiz questions or answers that do not fit the story's quiz
+var ErrInvalidQuiz = errors.New("invalid quiz")
+
+// QuizOption is one answer of a question. IsCorrect is only filled in for editors.
+type QuizOption struct {
+	OptionID   int    `json:"option_id"`
+	OptionIndo string `json:"option_indo"`
+	OptionEng  string `json:"option_eng"`
+	IsCorrect  *bool  `json:"is_correct,omitempty"`
+	Position   int    `json:"position"`
+}
+
+// QuizQuestion is a bilingual comprehension question of a story. For true/false
+// questions the options are generated from CorrectAnswer when none are given.
+type QuizQuestion struct {
+	QuestionID      int          `json:"question_id"`
+	StoryID         int          `json:"story_id"`
+	QuestionType    string       `json:"question_type"`
+	QuestionIndo    string       `json:"question_indo"`
+	QuestionEng     string       `json:"question_eng"`
+	ExplanationIndo *string      `json:"explanation_indo,omitempty"`
+	ExplanationEng  *string      `json:"explanation_eng,omitempty"`
+	CorrectAnswer   *bool        `json:"correct_answer,omitempty"`
+	Position        int          `json:"position"`
+	Options         []QuizOption `json:"options"`
+}
+
+// QuizFeedback tells a reader how one question of a submitted quiz went
+type QuizFeedback struct {
+	QuestionID       int     `json:"question_id"`
+	SelectedOptionID *int    `json:"selected_option_id"`
+	CorrectOptionID  int     `json:"correct_option_id"`
+	IsCorrect        bool    `json:"is_correct"`
+	ExplanationIndo  *string `json:"explanation_indo"`
+	ExplanationEng   *string `json:"explanation_eng"`
+}
+
+type QuizAttempt struct {
+	AttemptID int       `json:"attempt_id"`
+	StoryID   int       `json:"story_id"`
+	UserID    *int      `json:"user_id"`
+	UID       *string   `json:"uid"`
+	Score     int       `json:"score"`
+	Total     int       `json:"total"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// validateQuizQuestion checks a question sent by an editor and generates the
+// options of true/false questions
+func validateQuizQuestion(question *QuizQuestion) error {
+	if strings.TrimSpace(question.QuestionIndo) == "" || strings.TrimSpace(question.QuestionEng) == "" {
+		return fmt.Errorf("%w: question %d needs question_indo and question_eng", ErrInvalidQuiz, question.Position)
+	}
+
+	switch question.QuestionType {
+	case QuizTrueFalse:
+		if len(question.Options) == 0 {
+			if question.CorrectAnswer == nil {
+				return fmt.Errorf("%w: true/false question %d needs correct_answer", ErrInvalidQuiz, question.Position)
+			}
+			isTrue, isFalse := *question.CorrectAnswer, !*question.CorrectAnswer
+			question.Options = []QuizOption{
+				{OptionIndo: "Benar", OptionEng: "True", IsCorrect: &isTrue},
+				{OptionIndo: "Salah", OptionEng: "False", IsCorrect: &isFalse},
+			}
+		}
+		if len(question.Options) != 2 {
+			return fmt.Errorf("%w: true/false question %d must have 2 options", ErrInvalidQuiz, question.Position)
+		}
+	case QuizMultipleChoice:
+		if len(question.Options) < 2 {
+			return fmt.Errorf("%w: multiple choice question %d needs at least 2 options", ErrInvalidQuiz, question.Position)
+		}
+	default:
+		return fmt.Errorf("%w: invalid question_type %q", ErrInvalidQuiz, question.QuestionType)
+	}
+
+	correct := 0
+	for _, option := range question.Options {
+		if strings.TrimSpace(option.OptionIndo) == "" || strings.TrimSpace(option.OptionEng) == "" {
+			return fmt.Errorf("%w: options of question %d need option_indo and option_eng", ErrInvalidQuiz, question.Position)
+		}
+		if option.IsCorrect != nil && *option.IsCorrect {
+			correct++
+		}
+	}
+	if correct != 1 {
+		return fmt.Errorf("%w: question %d must have exactly one correct option", ErrInvalidQuiz, question.Position)
+	}
+
+	return nil
+}
+
+// loadStoryQuiz reads the questions of a story in order. Correct answers and
+// explanations are left out unless withAnswers is set.
+func loadStoryQuiz(ex dbExecutor, storyID int, withAnswers bool) ([]QuizQuestion, error) {
+	questions := []QuizQuestion{}
+	positionByID := make(map[int]int)
+
+	rows, err := ex.Query("SELECT question_id, story_id, question_type, question_indo, question_eng, explanation_indo, explanation_eng, position FROM quiz_question WHERE story_id = ? ORDER BY position, question_id", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var question QuizQuestion
+		err := rows.Scan(&question.QuestionID, &question.StoryID, &question.QuestionType, &question.QuestionIndo, &question.QuestionEng, &question.ExplanationIndo, &question.ExplanationEng, &question.Position)
+		if err != nil {
+			return nil, err
+		}
+		if !withAnswers {
+			question.ExplanationIndo = nil
+			question.ExplanationEng = nil
+		}
+		question.Options = []QuizOption{}
+		positionByID[question.QuestionID] = len(questions)
+		questions = append(questions, question)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	optionRows, err := ex.Query("SELECT o.option_id, o.question_id, o.option_indo, o.option_eng, o.is_correct, o.position FROM quiz_option o JOIN quiz_question q ON o.question_id = q.question_id WHERE q.story_id = ? ORDER BY o.question_id, o.position, o.option_id", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer optionRows.Close()
+
+	for optionRows.Next() {
+		var option QuizOption
+		var questionID int
+		var isCorrect bool
+		if err := optionRows.Scan(&option.OptionID, &questionID, &option.OptionIndo, &option.OptionEng, &isCorrect, &option.Position); err != nil {
+			return nil, err
+		}
+		if withAnswers {
+			option.IsCorrect = &isCorrect
+		}
+		question := &questions[positionByID[questionID]]
+		question.Options = append(question.Options, option)
+	}
+
+	return questions, optionRows.Err()
+}
+
+// GetStoryQuiz returns the questions of a published story without their answers
+func GetStoryQuiz(storyID int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	var published int
+	err := con.QueryRow("SELECT COUNT(*) FROM story s WHERE s.story_id = ? AND "+publishedStoryCondition, storyID).Scan(&published)
+	if err != nil {
+		return res, err
+	}
+	if published == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	questions, err := loadStoryQuiz(con, storyID, false)
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id":  storyID,
+		"questions": questions,
+	}
+
+	return res, nil
+}
+
+// SaveStoryQuiz replaces the quiz of a story. Questions and options sent with
+// their ID are updated in place so earlier attempts keep pointing at them; those
+// left out are removed.
+func SaveStoryQuiz(storyID int, questions []QuizQuestion) (Response, error) {
+	var res Response
+
+	for i := range questions {
+		questions[i].Position = i + 1
+		if err := validateQuizQuestion(&questions[i]); err != nil {
+			return res, err
+		}
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM story WHERE story_id = ?", storyID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	existing := make(map[int]bool)
+	rows, err := tx.Query("SELECT question_id FROM quiz_question WHERE story_id = ?", storyID)
+	if err != nil {
+		return res, err
+	}
+	for rows.Next() {
+		var questionID int
+		if err := rows.Scan(&questionID); err != nil {
+			rows.Close()
+			return res, err
+		}
+		existing[questionID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	now := time.Now()
+	kept := make(map[int]bool)
+	for _, question := range questions {
+		questionID := question.QuestionID
+
+		if questionID != 0 {
+			if !existing[questionID] {
+				return res, fmt.Errorf("%w: question %d does not belong to story %d", ErrInvalidQuiz, questionID, storyID)
+			}
+			_, err := tx.Exec(
+				"UPDATE quiz_question SET question_type = ?, question_indo = ?, question_eng = ?, explanation_indo = ?, explanation_eng = ?, position = ?, updated_at = ? WHERE question_id = ?",
+				question.QuestionType, question.QuestionIndo, question.QuestionEng, question.ExplanationIndo, question.ExplanationEng, question.Position, now, questionID,
+			)
+			if err != nil {
+				return res, err
+			}
+		} else {
+			result, err := tx.Exec(
+				"INSERT INTO quiz_question (story_id, question_type, question_indo, question_eng, explanation_indo, explanation_eng, position, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				storyID, question.QuestionType, question.QuestionIndo, question.QuestionEng, question.ExplanationIndo, question.ExplanationEng, question.Position, now, now,
+			)
+			if err != nil {
+				return res, err
+			}
+			lastID, err := result.LastInsertId()
+			if err != nil {
+				return res, err
+			}
+			questionID = int(lastID)
+		}
+		kept[questionID] = true
+
+		if err := saveQuizOptions(tx, questionID, question); err != nil {
+			return res, err
+		}
+	}
+
+	for questionID := range existing {
+		if kept[questionID] {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM quiz_question WHERE question_id = ?", questionID); err != nil {
+			return res, err
+		}
+	}
+
+	saved, err := loadStoryQuiz(tx, storyID, true)
+	if err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id":  storyID,
+		"questions": saved,
+	}
+
+	return res, nil
+}
+
+// saveQuizOptions writes the options of a saved question. Options sent with an
+// option_id are updated in place so earlier attempts keep the option they chose;
+// options left out are removed.
+func saveQuizOptions(ex dbExecutor, questionID int, question QuizQuestion) error {
+	var existingIDs []int
+	rows, err := ex.Query("SELECT option_id FROM quiz_option WHERE question_id = ? ORDER BY position, option_id", questionID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var optionID int
+		if err := rows.Scan(&optionID); err != nil {
+			rows.Close()
+			return err
+		}
+		existingIDs = append(existingIDs, optionID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	options, err := matchQuizOptions(question, existingIDs)
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[int]bool)
+	for i, option := range options {
+		isCorrect := option.IsCorrect != nil && *option.IsCorrect
+		if option.OptionID != 0 {
+			_, err := ex.Exec(
+				"UPDATE quiz_option SET option_indo = ?, option_eng = ?, is_correct = ?, position = ? WHERE option_id = ?",
+				option.OptionIndo, option.OptionEng, isCorrect, i+1, option.OptionID,
+			)
+			if err != nil {
+				return err
+			}
+			kept[option.OptionID] = true
+			continue
+		}
+		_, err := ex.Exec(
+			"INSERT INTO quiz_option (question_id, option_indo, option_eng, is_correct, position) VALUES (?, ?, ?, ?, ?)",
+			questionID, option.OptionIndo, option.OptionEng, isCorrect, i+1,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, optionID := range existingIDs {
+		if kept[optionID] {
+			continue
+		}
+		if _, err := ex.Exec("DELETE FROM quiz_option WHERE option_id = ?", optionID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchQuizOptions checks that the option IDs of a question belong to it. True/false
+// options sent without IDs, as generated from correct_answer, take over the
+// existing two options by position.
+func matchQuizOptions(question QuizQuestion, existingIDs []int) ([]QuizOption, error) {
+	options := make([]QuizOption, len(question.Options))
+	copy(options, question.Options)
+
+	existing := make(map[int]bool)
+	for _, optionID := range existingIDs {
+		existing[optionID] = true
+	}
+
+	withoutIDs := true
+	seen := make(map[int]bool)
+	for _, option := range options {
+		if option.OptionID == 0 {
+			continue
+		}
+		withoutIDs = false
+		if !existing[option.OptionID] {
+			return nil, fmt.Errorf("%w: option %d does not belong to question %d", ErrInvalidQuiz, option.OptionID, question.Position)
+		}
+		if seen[option.OptionID] {
+			return nil, fmt.Errorf("%w: option %d is sent twice in question %d", ErrInvalidQuiz, option.OptionID, question.Position)
+		}
+		seen[option.OptionID] = true
+	}
+
+	if question.QuestionType == QuizTrueFalse && withoutIDs && len(existingIDs) == len(options) {
+		for i := range options {
+			options[i].OptionID = existingIDs[i]
+		}
+	}
+
+	return options, nil
+}
+
+// SubmitStoryQuiz scores the answers of a reader, keyed by question ID with the
+// chosen option ID, stores the attempt and returns feedback for every question.
+// Unanswered questions count as wrong.
+func SubmitStoryQuiz(storyID int, userID *int, uid *string, answers map[int]int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	var published int
+	err := con.QueryRow("SELECT COUNT(*) FROM story s WHERE s.story_id = ? AND "+publishedStoryCondition, storyID).Scan(&published)
+	if err != nil {
+		return res, err
+	}
+	if published == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	questions, err := loadStoryQuiz(con, storyID, true)
+	if err != nil {
+		return res, err
+	}
+	if len(questions) == 0 {
+		return res, fmt.Errorf("%w: story %d has no quiz", ErrInvalidQuiz, storyID)
+	}
+
+	feedback := []QuizFeedback{}
+	score := 0
+	for _, question := range questions {
+		item := QuizFeedback{
+			QuestionID:      question.QuestionID,
+			ExplanationIndo: question.ExplanationIndo,
+			ExplanationEng:  question.ExplanationEng,
+		}
+
+		selected, answered := answers[question.QuestionID]
+		for _, option := range question.Options {
+			if *option.IsCorrect {
+				item.CorrectOptionID = option.OptionID
+			}
+			if answered && option.OptionID == selected {
+				optionID := option.OptionID
+				item.SelectedOptionID = &optionID
+			}
+		}
+		if answered && item.SelectedOptionID == nil {
+			return res, fmt.Errorf("%w: option %d is not an option of question %d", ErrInvalidQuiz, selected, question.QuestionID)
+		}
+
+		item.IsCorrect = item.SelectedOptionID != nil && *item.SelectedOptionID == item.CorrectOptionID
+		if item.IsCorrect {
+			score++
+		}
+		feedback = append(feedback, item)
+	}
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO quiz_attempt (story_id, user_id, uid, score, total, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		storyID, userID, uid, score, len(questions), time.Now(),
+	)
+	if err != nil {
+		return res, err
+	}
+
+	attemptID, err := result.LastInsertId()
+	if err != nil {
+		return res, err
+	}
+
+	for _, item := range feedback {
+		_, err := tx.Exec(
+			"INSERT INTO quiz_attempt_answer (attempt_id, question_id, option_id, is_correct) VALUES (?, ?, ?, ?)",
+			attemptID, item.QuestionID, item.SelectedOptionID, item.IsCorrect,
+		)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"attempt_id": attemptID,
+		"story_id":   storyID,
+		"score":      score,
+		"total":      len(questions),
+		"feedback":   feedback,
+	}
+
+	return res, nil
+}
+
+// GetQuizAttempts lists the attempts of a user or uid on the quiz of a story,
+// newest first
+func GetQuizAttempts(storyID int, userID *int, uid *string) (Response, error) {
+	var res Response
+	attempts := []QuizAttempt{}
+
+	con := db.CreateCon()
+
+	sqlStatement := "SELECT attempt_id, story_id, user_id, uid, score, total, created_at FROM quiz_attempt WHERE story_id = ?"
+	args := []interface{}{storyID}
+	if userID != nil {
+		sqlStatement += " AND user_id = ?"
+		args = append(args, *userID)
+	} else {
+		sqlStatement += " AND uid = ?"
+		args = append(args, *uid)
+	}
+	sqlStatement += " ORDER BY created_at DESC, attempt_id DESC"
+
+	rows, err := con.Query(sqlStatement, args...)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	bestScore := 0
+	for rows.Next() {
+		var attempt QuizAttempt
+		if err := rows.Scan(&attempt.AttemptID, &attempt.StoryID, &attempt.UserID, &attempt.UID, &attempt.Score, &attempt.Total, &attempt.CreatedAt); err != nil {
+			return res, err
+		}
+		attempt.CreatedAt = attempt.CreatedAt.In(loc)
+		if attempt.Score > bestScore {
+			bestScore = attempt.Score
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id":   storyID,
+		"attempts":   attempts,
+		"best_score": bestScore,
+	}
+
+	return res, nil
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateQuizQuestion(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name        string
+		question    QuizQuestion
+		valid       bool
+		wantOptions int
+	}{
+		{
+			name:        "true/false from correct_answer",
+			question:    QuizQuestion{QuestionType: QuizTrueFalse, QuestionIndo: "Kancil cerdik?", QuestionEng: "Is the mouse deer clever?", CorrectAnswer: &yes},
+			valid:       true,
+			wantOptions: 2,
+		},
+		{
+			name:     "true/false without correct_answer",
+			question: QuizQuestion{QuestionType: QuizTrueFalse, QuestionIndo: "Kancil cerdik?", QuestionEng: "Is the mouse deer clever?"},
+		},
+		{
+			name: "multiple choice",
+			question: QuizQuestion{QuestionType: QuizMultipleChoice, QuestionIndo: "Siapa?", QuestionEng: "Who?", Options: []QuizOption{
+				{OptionIndo: "Kancil", OptionEng: "Mouse deer", IsCorrect: &yes},
+				{OptionIndo: "Buaya", OptionEng: "Crocodile", IsCorrect: &no},
+				{OptionIndo: "Harimau", OptionEng: "Tiger"},
+			}},
+			valid:       true,
+			wantOptions: 3,
+		},
+		{
+			name: "multiple choice with two correct options",
+			question: QuizQuestion{QuestionType: QuizMultipleChoice, QuestionIndo: "Siapa?", QuestionEng: "Who?", Options: []QuizOption{
+				{OptionIndo: "Kancil", OptionEng: "Mouse deer", IsCorrect: &yes},
+				{OptionIndo: "Buaya", OptionEng: "Crocodile", IsCorrect: &yes},
+			}},
+		},
+		{
+			name: "multiple choice with one option",
+			question: QuizQuestion{QuestionType: QuizMultipleChoice, QuestionIndo: "Siapa?", QuestionEng: "Who?", Options: []QuizOption{
+				{OptionIndo: "Kancil", OptionEng: "Mouse deer", IsCorrect: &yes},
+			}},
+		},
+		{
+			name: "option missing a language",
+			question: QuizQuestion{QuestionType: QuizMultipleChoice, QuestionIndo: "Siapa?", QuestionEng: "Who?", Options: []QuizOption{
+				{OptionIndo: "Kancil", IsCorrect: &yes},
+				{OptionIndo: "Buaya", OptionEng: "Crocodile"},
+			}},
+		},
+		{
+			name:     "missing English question",
+			question: QuizQuestion{QuestionType: QuizTrueFalse, QuestionIndo: "Kancil cerdik?", CorrectAnswer: &yes},
+		},
+		{
+			name:     "unknown type",
+			question: QuizQuestion{QuestionType: "essay", QuestionIndo: "Ceritakan", QuestionEng: "Tell"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateQuizQuestion(&tt.question)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidQuiz) {
+					t.Fatalf("validateQuizQuestion() = %v, want ErrInvalidQuiz", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateQuizQuestion() = %v", err)
+			}
+			if len(tt.question.Options) != tt.wantOptions {
+				t.Fatalf("got %d options, want %d", len(tt.question.Options), tt.wantOptions)
+			}
+		})
+	}
+}
+
+func TestMatchQuizOptions(t *testing.T) {
+	yes, no := true, false
+	trueFalse := []QuizOption{
+		{OptionIndo: "Benar", OptionEng: "True", IsCorrect: &no},
+		{OptionIndo: "Salah", OptionEng: "False", IsCorrect: &yes},
+	}
+
+	tests := []struct {
+		name        string
+		question    QuizQuestion
+		existingIDs []int
+		wantIDs     []int
+		valid       bool
+	}{
+		{
+			name:        "new question",
+			question:    QuizQuestion{QuestionType: QuizTrueFalse, Options: trueFalse},
+			existingIDs: nil,
+			wantIDs:     []int{0, 0},
+			valid:       true,
+		},
+		{
+			name:        "true/false keeps its options",
+			question:    QuizQuestion{QuestionType: QuizTrueFalse, Options: trueFalse},
+			existingIDs: []int{7, 8},
+			wantIDs:     []int{7, 8},
+			valid:       true,
+		},
+		{
+			name: "multiple choice updates, adds and drops",
+			question: QuizQuestion{QuestionType: QuizMultipleChoice, Options: []QuizOption{
+				{OptionID: 9, OptionIndo: "Kancil", OptionEng: "Mouse deer"},
+				{OptionIndo: "Gajah", OptionEng: "Elephant"},
+			}},
+			existingIDs: []int{7, 8, 9},
+			wantIDs:     []int{9, 0},
+			valid:       true,
+		},
+		{
+			name: "multiple choice without IDs",
+			question: QuizQuestion{QuestionType: QuizMultipleChoice, Options: []QuizOption{
+				{OptionIndo: "Kancil", OptionEng: "Mouse deer"},
+				{OptionIndo: "Gajah", OptionEng: "Elephant"},
+			}},
+			existingIDs: []int{7, 8},
+			wantIDs:     []int{0, 0},
+			valid:       true,
+		},
+		{
+			name: "option of another question",
+			question: QuizQuestion{QuestionType: QuizMultipleChoice, Options: []QuizOption{
+				{OptionID: 42, OptionIndo: "Kancil", OptionEng: "Mouse deer"},
+				{OptionIndo: "Gajah", OptionEng: "Elephant"},
+			}},
+			existingIDs: []int{7, 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := matchQuizOptions(tt.question, tt.existingIDs)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidQuiz) {
+					t.Fatalf("matchQuizOptions() = %v, want ErrInvalidQuiz", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("matchQuizOptions() = %v, want nil", err)
+			}
+			for i, option := range options {
+				if option.OptionID != tt.wantIDs[i] {
+					t.Errorf("option %d has ID %d, want %d", i, option.OptionID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
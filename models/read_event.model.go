@@ -0,0 +1,146 @@
+// Read Event Model
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"kisahloka_be/db"
+	"time"
+)
+
+const (
+	ReadEventRead   = "read"
+	ReadEventFinish = "finish"
+)
+
+// IsValidReadEventType reports whether eventType is one of the known read events
+func IsValidReadEventType(eventType string) bool {
+	return eventType == ReadEventRead || eventType == ReadEventFinish
+}
+
+// readDayOffset shifts UTC timestamps to UTC+8 so reading days match the dates
+// shown everywhere else in the API
+const readDayOffset = "INTERVAL 8 HOUR"
+
+// ReadingStreak counts consecutive days with reading activity
+type ReadingStreak struct {
+	CurrentStreak int     `json:"current_streak"`
+	LongestStreak int     `json:"longest_streak"`
+	LastReadDate  *string `json:"last_read_date"`
+	ReadToday     bool    `json:"read_today"`
+}
+
+// RecordReadEvent stores a read or finish event of a user and awards the badges
+// it unlocks, which are returned together with the updated streak
+func RecordReadEvent(userID, storyID int, eventType string) (Response, error) {
+	var res Response
+
+	if !IsValidReadEventType(eventType) {
+		return res, fmt.Errorf("invalid event_type %q", eventType)
+	}
+
+	con := db.CreateCon()
+
+	var exists int
+	if err := con.QueryRow("SELECT COUNT(*) FROM story s WHERE s.story_id = ? AND "+publishedStoryCondition, storyID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	if _, err := con.Exec("INSERT INTO read_event (user_id, story_id, event_type, created_at) VALUES (?, ?, ?, ?)", userID, storyID, eventType, time.Now()); err != nil {
+		return res, err
+	}
+
+	streak, err := getReadingStreak(con, userID)
+	if err != nil {
+		return res, err
+	}
+
+	awarded, err := awardBadges(con, userID, streak)
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"streak":         streak,
+		"awarded_badges": awarded,
+	}
+
+	return res, nil
+}
+
+// GetReadingStreak returns the current and longest daily reading streak of a user
+func GetReadingStreak(userID int) (ReadingStreak, error) {
+	return getReadingStreak(db.CreateCon(), userID)
+}
+
+func getReadingStreak(ex dbExecutor, userID int) (ReadingStreak, error) {
+	rows, err := ex.Query("SELECT DISTINCT DATE_FORMAT(created_at + "+readDayOffset+", '%Y-%m-%d') AS read_day FROM read_event WHERE user_id = ? ORDER BY read_day", userID)
+	if err != nil {
+		return ReadingStreak{}, err
+	}
+	defer rows.Close()
+
+	var days []string
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return ReadingStreak{}, err
+		}
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return ReadingStreak{}, err
+	}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return ReadingStreak{}, err
+	}
+
+	return computeReadingStreak(days, time.Now().In(loc)), nil
+}
+
+// computeReadingStreak derives the streaks from the sorted "2006-01-02" days a
+// user read on. The current streak survives until the end of the day after the
+// last reading day, so it does not drop to zero before the user reads today.
+func computeReadingStreak(days []string, now time.Time) ReadingStreak {
+	var streak ReadingStreak
+	if len(days) == 0 {
+		return streak
+	}
+
+	run := 0
+	var previous time.Time
+	for i, day := range days {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		if i > 0 && date.Sub(previous) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		if run > streak.LongestStreak {
+			streak.LongestStreak = run
+		}
+		previous = date
+	}
+
+	lastReadDate := days[len(days)-1]
+	streak.LastReadDate = &lastReadDate
+
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	streak.ReadToday = lastReadDate == today
+	if lastReadDate == today || lastReadDate == yesterday {
+		streak.CurrentStreak = run
+	}
+
+	return streak
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeReadingStreak(t *testing.T) {
+	utc8 := time.FixedZone("UTC+8", 8*60*60)
+
+	tests := []struct {
+		name          string
+		days          []string
+		now           time.Time
+		wantCurrent   int
+		wantLongest   int
+		wantReadToday bool
+	}{
+		{
+			name: "no reading",
+			now:  time.Date(2026, 3, 10, 12, 0, 0, 0, utc8),
+		},
+		{
+			name:          "read today only",
+			days:          []string{"2026-03-10"},
+			now:           time.Date(2026, 3, 10, 12, 0, 0, 0, utc8),
+			wantCurrent:   1,
+			wantLongest:   1,
+			wantReadToday: true,
+		},
+		{
+			name:          "three days up to today",
+			days:          []string{"2026-03-08", "2026-03-09", "2026-03-10"},
+			now:           time.Date(2026, 3, 10, 8, 0, 0, 0, utc8),
+			wantCurrent:   3,
+			wantLongest:   3,
+			wantReadToday: true,
+		},
+		{
+			name:        "streak kept until the end of the next day",
+			days:        []string{"2026-03-08", "2026-03-09"},
+			now:         time.Date(2026, 3, 10, 23, 59, 0, 0, utc8),
+			wantCurrent: 2,
+			wantLongest: 2,
+		},
+		{
+			name:        "streak lost after midnight UTC+8",
+			days:        []string{"2026-03-08", "2026-03-09"},
+			now:         time.Date(2026, 3, 11, 0, 30, 0, 0, utc8),
+			wantCurrent: 0,
+			wantLongest: 2,
+		},
+		{
+			// 16:30 UTC on the 10th is already 00:30 on the 11th in UTC+8
+			name:        "day boundary follows UTC+8, not UTC",
+			days:        []string{"2026-03-08", "2026-03-09"},
+			now:         time.Date(2026, 3, 10, 16, 30, 0, 0, time.UTC).In(utc8),
+			wantCurrent: 0,
+			wantLongest: 2,
+		},
+		{
+			name:          "gap resets the current run",
+			days:          []string{"2026-03-01", "2026-03-02", "2026-03-03", "2026-03-04", "2026-03-08", "2026-03-09", "2026-03-10"},
+			now:           time.Date(2026, 3, 10, 20, 0, 0, 0, utc8),
+			wantCurrent:   3,
+			wantLongest:   4,
+			wantReadToday: true,
+		},
+		{
+			name:        "across a month end",
+			days:        []string{"2026-02-27", "2026-02-28", "2026-03-01"},
+			now:         time.Date(2026, 3, 2, 9, 0, 0, 0, utc8),
+			wantCurrent: 3,
+			wantLongest: 3,
+		},
+		{
+			name:          "unparseable day skipped",
+			days:          []string{"2026-03-09", "not-a-day", "2026-03-10"},
+			now:           time.Date(2026, 3, 10, 9, 0, 0, 0, utc8),
+			wantCurrent:   2,
+			wantLongest:   2,
+			wantReadToday: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeReadingStreak(tt.days, tt.now)
+			if got.CurrentStreak != tt.wantCurrent || got.LongestStreak != tt.wantLongest || got.ReadToday != tt.wantReadToday {
+				t.Fatalf("computeReadingStreak() = current %d, longest %d, today %v; want %d, %d, %v",
+					got.CurrentStreak, got.LongestStreak, got.ReadToday, tt.wantCurrent, tt.wantLongest, tt.wantReadToday)
+			}
+			if len(tt.days) > 0 && (got.LastReadDate == nil || *got.LastReadDate != tt.days[len(tt.days)-1]) {
+				t.Fatalf("LastReadDate = %v, want %s", got.LastReadDate, tt.days[len(tt.days)-1])
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Reading Progress Model
+
+package models
+
+import (
+	"fmt"
+	"kisahloka_be/db"
+	"time"
+)
+
+// readerCondition matches rows of a signed-in user (user_id) or, failing that,
+// of a device (uid). column prefixes the columns, e.g. "rp." for "rp.user_id".
+func readerCondition(column string, userID *int, uid *string) (string, interface{}) {
+	if userID != nil {
+		return column + "user_id = ?", *userID
+	}
+	return column + "uid = ?", *uid
+}
+
+// SaveReadingProgress records the page a reader has reached in a story. Going
+// back to an earlier page moves the progress back too.
+func SaveReadingProgress(storyID int, userID *int, uid *string, order int) (Response, error) {
+	var res Response
+
+	if userID == nil && uid == nil {
+		return res, fmt.Errorf("user_id or uid is required")
+	}
+
+	con := db.CreateCon()
+
+	var totalContent int
+	if err := con.QueryRow("SELECT total_content FROM story WHERE story_id = ?", storyID).Scan(&totalContent); err != nil {
+		return res, err
+	}
+	if order < 1 || order > totalContent {
+		return res, fmt.Errorf("order must be between 1 and %d", totalContent)
+	}
+
+	// Signed-in progress is keyed by user only so it follows the user across devices
+	var uidValue interface{}
+	if userID == nil {
+		uidValue = *uid
+	}
+	var userIDValue interface{}
+	if userID != nil {
+		userIDValue = *userID
+	}
+
+	now := time.Now()
+	_, err := con.Exec("INSERT INTO reading_progress (user_id, uid, story_id, last_order, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE last_order = VALUES(last_order), updated_at = VALUES(updated_at)",
+		userIDValue, uidValue, storyID, order, now, now,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id":      storyID,
+		"last_order":    order,
+		"total_content": totalContent,
+		"is_finished":   order >= totalContent,
+	}
+
+	return res, nil
+}
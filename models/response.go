@@ -1,5 +1,7 @@
 package models
 
+import "database/sql"
+
 type Response struct {
 	Data  interface{} `json:"data"`
 	Error string      `json:"error"`
@@ -31,3 +33,11 @@ func calculateTotalPages(totalItems, pageSize int) int {
 	}
 	return totalPages
 }
+
+// dbExecutor is satisfied by both *sql.DB and *sql.Tx so helpers can run
+// inside or outside a transaction
+type dbExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
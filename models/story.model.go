@@ -11,6 +11,9 @@ import (
 	"time"
 )
 
+// Story is a tale as edited by admins. IsFavorited is the editorial "favorite"
+// pick shown on the home page, not a per-reader favorite; those are likes,
+// counted in LikeCount.
 type Story struct {
 	StoryID        int                  `json:"story_id"`
 	TypeID         int                  `json:"type_id"`
@@ -22,77 +25,128 @@ type Story struct {
 	ReleasedDate   time.Time            `json:"released_date"`
 	ThumbnailImage string               `json:"thumbnail_image"`
 	ReadCount      int                  `json:"read_count"`
+	LikeCount      int                  `json:"like_count"`
 	IsHighlighted  int                  `json:"is_highligthed"`
 	IsFavorited    int                  `json:"is_favorited"`
+	Status         string               `json:"status"`
 	GenreID        []int                `json:"genre_id"`
 	GenreName      []string             `json:"genre_name"`
 	Synopsis       string               `json:"synopsis"`
+	MoralIndo      string               `json:"moral_indo"`
+	MoralEng       string               `json:"moral_eng"`
 	StoryContent   []StoryContentOnList `json:"story_content"`
 	CreatedAt      time.Time            `json:"created_at"`
 	UpdatedAt      time.Time            `json:"updated_at"`
 }
 
 type StoryPreview struct {
-	StoryID        int       `json:"story_id"`
-	TypeID         int       `json:"type_id"`
-	TypeName       string    `json:"type_name"`
-	OriginID       int       `json:"origin_id"`
-	OriginName     string    `json:"origin_name"`
-	Title          string    `json:"title"`
-	TotalContent   int       `json:"total_content"`
-	ReleasedDate   time.Time `json:"released_date"`
-	ThumbnailImage string    `json:"thumbnail_image"`
-	ReadCount      int       `json:"read_count"`
-	IsHighlighted  int       `json:"is_highligthed"`
-	IsFavorited    int       `json:"is_favorited"`
-	GenreName      []string  `json:"genre_name"`
+	StoryID        int           `json:"story_id"`
+	TypeID         int           `json:"type_id"`
+	TypeName       string        `json:"type_name"`
+	OriginID       int           `json:"origin_id"`
+	OriginName     string        `json:"origin_name"`
+	Title          string        `json:"title"`
+	TotalContent   int           `json:"total_content"`
+	ReleasedDate   time.Time     `json:"released_date"`
+	ThumbnailImage string        `json:"thumbnail_image"`
+	ThumbnailMeta  *ImageMeta    `json:"thumbnail_meta"`
+	ReadCount      int           `json:"read_count"`
+	LikeCount      int           `json:"like_count"`
+	IsHighlighted  int           `json:"is_highligthed"`
+	IsFavorited    int           `json:"is_favorited"`
+	GenreID        []int         `json:"genre_id"`
+	GenreName      []string      `json:"genre_name"`
+	Rating         RatingSummary `json:"rating"`
+	Locales        []string      `json:"locales"`
 }
 
 type StoryDetail struct {
-	StoryID        int       `json:"story_id"`
-	TypeID         int       `json:"type_id"`
-	TypeName       string    `json:"type_name"`
-	OriginID       int       `json:"origin_id"`
-	OriginName     string    `json:"origin_name"`
-	Title          string    `json:"title"`
-	TotalContent   int       `json:"total_content"`
-	ReleasedDate   time.Time `json:"released_date"`
-	ThumbnailImage string    `json:"thumbnail_image"`
-	ReadCount      int       `json:"read_count"`
-	IsHighlighted  int       `json:"is_highligthed"`
-	IsFavorited    int       `json:"is_favorited"`
-	GenreID        []int     `json:"genre_id"`
-	GenreName      []string  `json:"genre_name"`
-	Synopsis       string    `json:"synopsis"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	IsBookmark     int       `json:"is_bookmark"`
-	BookmarkID     int       `json:"bookmark_id"`
+	StoryID        int               `json:"story_id"`
+	TypeID         int               `json:"type_id"`
+	TypeName       string            `json:"type_name"`
+	OriginID       int               `json:"origin_id"`
+	OriginName     string            `json:"origin_name"`
+	Title          string            `json:"title"`
+	TotalContent   int               `json:"total_content"`
+	ReleasedDate   time.Time         `json:"released_date"`
+	ThumbnailImage string            `json:"thumbnail_image"`
+	ThumbnailMeta  *ImageMeta        `json:"thumbnail_meta"`
+	ReadCount      int               `json:"read_count"`
+	LikeCount      int               `json:"like_count"`
+	IsHighlighted  int               `json:"is_highligthed"`
+	IsFavorited    int               `json:"is_favorited"`
+	GenreID        []int             `json:"genre_id"`
+	GenreName      []string          `json:"genre_name"`
+	Synopsis       string            `json:"synopsis"`
+	MoralIndo      string            `json:"moral_indo"`
+	MoralEng       string            `json:"moral_eng"`
+	MoralValues    []StoryMoralValue `json:"moral_values"`
+	Characters     []StoryCharacter  `json:"characters"`
+	Tags           []StoryTag        `json:"tags"`
+	Rating         RatingSummary     `json:"rating"`
+	MyReview       *StoryReview      `json:"my_review"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	IsBookmark     int               `json:"is_bookmark"`
+	BookmarkID     int               `json:"bookmark_id"`
+	IsLiked        int               `json:"is_liked"`
+	Locales        []string          `json:"locales"`
 }
 
 type StoryContentOnList struct {
-	Order       int    `json:"order"`
-	Image       string `json:"image"`
-	ContentIndo string `json:"content_indo"`
-	ContentEng  string `json:"content_eng"`
+	Order       int                      `json:"order"`
+	Image       string                   `json:"image"`
+	ContentIndo string                   `json:"content_indo"`
+	ContentEng  string                   `json:"content_eng"`
+	Audio       []StoryContentAudio      `json:"audio,omitempty"`
+	Timings     []StoryContentTiming     `json:"timings,omitempty"`
+	Annotations []StoryContentAnnotation `json:"annotations,omitempty"`
 }
 
-func GetAllStoriesCompleted(page, pageSize int, keyword string) (Response, error) {
+// StoryContentLocalized is a page with its text in a single locale. Locale is the
+// locale the text was taken from, which differs from the requested one on fallback.
+type StoryContentLocalized struct {
+	Order       int                      `json:"order"`
+	Image       string                   `json:"image"`
+	Locale      string                   `json:"locale"`
+	Content     string                   `json:"content"`
+	Audio       []StoryContentAudio      `json:"audio,omitempty"`
+	Timings     []StoryContentTiming     `json:"timings,omitempty"`
+	Annotations []StoryContentAnnotation `json:"annotations,omitempty"`
+}
+
+// GetAllStoriesCompleted lists stories with their pages. Readers only get the
+// published ones; the admin listing passes publishedOnly false to see every status.
+// A locale swaps titles, synopses and taxonomy names; pages stay bilingual.
+func GetAllStoriesCompleted(page, pageSize int, keyword, status string, publishedOnly bool, locale string) (Response, error) {
 	var res Response
 	var arrobj []Story
 	var meta Meta
 
 	con := db.CreateCon()
 
-	// Add a WHERE clause to filter stories based on the keyword
-	whereClause := ""
+	// Add a WHERE clause to filter stories based on the keyword and status (if provided)
+	conditions := []string{}
+	args := []interface{}{}
+	if publishedOnly {
+		conditions = append(conditions, publishedStoryCondition)
+	}
 	if keyword != "" {
-		whereClause = " WHERE title LIKE '%" + keyword + "%'"
+		conditions = append(conditions, "s.title LIKE ?")
+		args = append(args, "%"+keyword+"%")
+	}
+	if status != "" {
+		conditions = append(conditions, "s.status = ?")
+		args = append(args, status)
+	}
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
 	}
 
 	// Count total items in the database
 	var totalItems int
-	err := con.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM story %s", whereClause)).Scan(&totalItems)
+	err := con.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM story s %s", whereClause), args...).Scan(&totalItems)
 	if err != nil {
 		return res, err
 	}
@@ -128,8 +182,8 @@ func GetAllStoriesCompleted(page, pageSize int, keyword string) (Response, error
 
 	// Calculate the offset based on the page number and page size
 	offset := (page - 1) * pageSize
-	sqlStatement := fmt.Sprintf("SELECT s.*, t.type_name, o.origin_name, GROUP_CONCAT(g.genre_id) AS genre_id, GROUP_CONCAT(g.genre_name) AS genre_name FROM story s LEFT JOIN type t ON s.type_id = t.type_id LEFT JOIN origin o ON s.origin_id = o.origin_id LEFT JOIN story_genre sg ON s.story_id = sg.story_id LEFT JOIN genre g ON sg.genre_id = g.genre_id %s GROUP BY s.story_id LIMIT %d OFFSET %d", whereClause, pageSize, offset)
-	rows, err := con.Query(sqlStatement)
+	sqlStatement := fmt.Sprintf("SELECT s.story_id, s.type_id, s.origin_id, s.title, s.total_content, s.released_date, s.synopsis, COALESCE(s.moral_indo, ''), COALESCE(s.moral_eng, ''), s.thumbnail_image, s.read_count, s.like_count, s.is_highligthed, s.is_favorited, s.status, s.created_at, s.updated_at, t.type_name, o.origin_name, GROUP_CONCAT(g.genre_id) AS genre_id, GROUP_CONCAT(g.genre_name) AS genre_name FROM story s LEFT JOIN type t ON s.type_id = t.type_id LEFT JOIN origin o ON s.origin_id = o.origin_id LEFT JOIN story_genre sg ON s.story_id = sg.story_id LEFT JOIN genre g ON sg.genre_id = g.genre_id %s GROUP BY s.story_id LIMIT %d OFFSET %d", whereClause, pageSize, offset)
+	rows, err := con.Query(sqlStatement, args...)
 	if err != nil {
 		return res, err
 	}
@@ -146,10 +200,14 @@ func GetAllStoriesCompleted(page, pageSize int, keyword string) (Response, error
 			&obj.TotalContent,
 			&obj.ReleasedDate,
 			&obj.Synopsis,
+			&obj.MoralIndo,
+			&obj.MoralEng,
 			&obj.ThumbnailImage,
 			&obj.ReadCount,
+			&obj.LikeCount,
 			&obj.IsHighlighted,
 			&obj.IsFavorited,
+			&obj.Status,
 			&obj.CreatedAt,
 			&obj.UpdatedAt,
 			&obj.TypeName,
@@ -188,6 +246,16 @@ func GetAllStoriesCompleted(page, pageSize int, keyword string) (Response, error
 		meta.TotalItems = totalItems
 	}
 
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	if l := newLocalizer(locale); l != nil {
+		if err := l.localizeStories(arrobj); err != nil {
+			return res, err
+		}
+	}
+
 	res.Data = map[string]interface{}{
 		"stories": arrobj,
 		"meta":    meta,
@@ -196,12 +264,75 @@ func GetAllStoriesCompleted(page, pageSize int, keyword string) (Response, error
 	return res, nil
 }
 
-func GetStoryContentOnStory(storyID int) (Response, error) {
+// GetStoryContentOnStory returns the pages of a published story. With an empty
+// locale every page carries both content_indo and content_eng, otherwise a single
+// content field in the locale or its fallback.
+func GetStoryContentOnStory(storyID int, locale string) (Response, error) {
 	var res Response
 	var storyContent []StoryContentOnList
 
 	con := db.CreateCon()
 
+	// Only published stories expose their pages
+	var title string
+	err := con.QueryRow("SELECT s.title FROM story s WHERE s.story_id = ? AND "+publishedStoryCondition, storyID).Scan(&title)
+	if err != nil {
+		return res, err
+	}
+
+	audioByPage, err := getStoryAudioByPage(con, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	timingsByPage, err := getStoryTimingsByPage(con, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	annotationsByPage, err := getStoryAnnotationsByPage(con, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	if l := newLocalizer(locale); l != nil {
+		titles, err := l.storyTitles([]int{storyID})
+		if err != nil {
+			return res, err
+		}
+		if translation, ok := titles[storyID]; ok {
+			title = translation.Title
+		}
+
+		localizedContent, err := l.storyContent(storyID)
+		if err != nil {
+			return res, err
+		}
+
+		// Only narration, timings and annotations that match the text shown on the page
+		for i := range localizedContent {
+			page := &localizedContent[i]
+			for _, audio := range audioByPage[page.Order] {
+				if audio.Locale == page.Locale {
+					page.Audio = append(page.Audio, audio)
+				}
+			}
+			page.Timings = pageTimings(timingsByPage[page.Order], page.Locale, page.Content)
+			page.Annotations = pageAnnotations(annotationsByPage[page.Order], page.Locale, page.Content)
+		}
+
+		res.Data = map[string]interface{}{
+			"story": map[string]interface{}{
+				"story_id":      storyID,
+				"title":         title,
+				"locale":        locale,
+				"story_content": localizedContent,
+			},
+		}
+
+		return res, nil
+	}
+
 	sqlStatement := `
 		SELECT 
 			` + "`order`" + `, image, content_indo, content_eng 
@@ -229,13 +360,22 @@ func GetStoryContentOnStory(storyID int) (Response, error) {
 		if err != nil {
 			return res, err
 		}
+		content.Audio = audioByPage[content.Order]
+		content.Timings = append(
+			pageTimings(timingsByPage[content.Order], LocaleIndonesian, content.ContentIndo),
+			pageTimings(timingsByPage[content.Order], LocaleEnglish, content.ContentEng)...,
+		)
+		content.Annotations = append(
+			pageAnnotations(annotationsByPage[content.Order], LocaleIndonesian, content.ContentIndo),
+			pageAnnotations(annotationsByPage[content.Order], LocaleEnglish, content.ContentEng)...,
+		)
 		storyContent = append(storyContent, content)
 	}
 
 	res.Data = map[string]interface{}{
 		"story": map[string]interface{}{
 			"story_id":      storyID,
-			"title":         "Keong Mas", // Jika title perlu diambil dari database, Anda dapat menggantinya dengan query ke tabel cerita
+			"title":         title,
 			"story_content": storyContent,
 		},
 	}
@@ -244,35 +384,90 @@ func GetStoryContentOnStory(storyID int) (Response, error) {
 	return res, nil
 }
 
-func GetAllStoriesPreview(page, pageSize int, keyword string, typeID int) (Response, error) {
+// StoryPreviewFilter narrows the published stories listed by GetAllStoriesPreview.
+// Zero values leave a filter out.
+type StoryPreviewFilter struct {
+	Keyword       string
+	TypeID        int
+	MoralValueIDs []int
+	CharacterID   int
+	CharacterName string
+	// OriginID matches stories from the origin or any origin below it
+	OriginID int
+	// TagSlugs matches stories carrying all of the tags
+	TagSlugs []string
+	// CollectionID matches stories in the collection and lists them in collection order
+	CollectionID int
+	// Sort orders the listing, see StoryPreviewSorts; it overrides the collection order
+	Sort string
+}
+
+// StoryPreviewSortMostLiked lists the stories with the most likes first
+const StoryPreviewSortMostLiked = "most_liked"
+
+// StoryPreviewSorts maps the sort query param of story listings to its ORDER BY
+var StoryPreviewSorts = map[string]string{
+	StoryPreviewSortMostLiked: "s.like_count DESC, s.story_id DESC",
+	"newest":                  "s.released_date DESC, s.story_id DESC",
+}
+
+func GetAllStoriesPreview(page, pageSize int, filter StoryPreviewFilter, locale string) (Response, error) {
 	var res Response
 	var arrobj []StoryPreview // Menggunakan struktur StoryPreview
 	var meta Meta
 
 	con := db.CreateCon()
 
-	// Add a WHERE clause to filter stories based on the keyword and type_id (if provided)
-	whereClause1 := ""
-	whereClause := ""
-	if keyword != "" {
-		whereClause += " WHERE title LIKE '%" + keyword + "%'"
-		whereClause1 += " WHERE title LIKE '%" + keyword + "%'"
+	// Add a WHERE clause to filter published stories based on the filter (if provided)
+	conditions := []string{publishedStoryCondition}
+	args := []interface{}{}
+	if filter.Keyword != "" {
+		conditions = append(conditions, "s.title LIKE ?")
+		args = append(args, "%"+filter.Keyword+"%")
 	}
-	if typeID != 0 {
-		if whereClause == "" {
-			whereClause += " WHERE"
-			whereClause1 += " WHERE"
-		} else {
-			whereClause += " AND"
-			whereClause1 += " AND"
-		}
-		whereClause += " s.type_id = " + strconv.Itoa(typeID)
-		whereClause1 += " type_id = " + strconv.Itoa(typeID)
+	if filter.TypeID != 0 {
+		conditions = append(conditions, "s.type_id = ?")
+		args = append(args, filter.TypeID)
+	}
+	if len(filter.MoralValueIDs) > 0 {
+		condition, conditionArgs := moralValueCondition(filter.MoralValueIDs)
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	if len(filter.TagSlugs) > 0 {
+		condition, conditionArgs := tagCondition(filter.TagSlugs)
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	if filter.OriginID != 0 {
+		conditions = append(conditions, "s.origin_id IN ("+originDescendantsQuery+")")
+		args = append(args, filter.OriginID)
+	}
+	if filter.CharacterID != 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM story_character sc WHERE sc.story_id = s.story_id AND sc.character_id = ?)")
+		args = append(args, filter.CharacterID)
+	}
+	if filter.CharacterName != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM story_character sc JOIN `character` c ON sc.character_id = c.character_id WHERE sc.story_id = s.story_id AND c.name LIKE ?)")
+		args = append(args, "%"+filter.CharacterName+"%")
 	}
+	orderClause := ""
+	orderArgs := []interface{}{}
+	if filter.CollectionID != 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM collection_story cs WHERE cs.story_id = s.story_id AND cs.collection_id = ?)")
+		args = append(args, filter.CollectionID)
+		orderClause = " ORDER BY (SELECT cs.position FROM collection_story cs WHERE cs.story_id = s.story_id AND cs.collection_id = ?)"
+		orderArgs = append(orderArgs, filter.CollectionID)
+	}
+	if order, ok := StoryPreviewSorts[filter.Sort]; ok {
+		orderClause = " ORDER BY " + order
+		orderArgs = nil
+	}
+	whereClause := " WHERE " + strings.Join(conditions, " AND ")
 
 	// Count total items in the database
 	var totalItems int
-	err := con.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM story %s", whereClause1)).Scan(&totalItems)
+	err := con.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM story s %s", whereClause), args...).Scan(&totalItems)
 	if err != nil {
 		return res, err
 	}
@@ -319,12 +514,14 @@ func GetAllStoriesPreview(page, pageSize int, keyword string, typeID int) (Respo
 			s.total_content, 
 			s.released_date, 
 			s.thumbnail_image, 
-			s.read_count, 
+			s.read_count, s.like_count, 
 			s.is_highligthed, 
 			s.is_favorited, 
 			t.type_name, 
 			o.origin_name, 
-			GROUP_CONCAT(g.genre_name) AS genre_name 
+			GROUP_CONCAT(g.genre_id) AS genre_id, 
+			GROUP_CONCAT(g.genre_name) AS genre_name, 
+			` + storyLocalesColumn + ` 
 		FROM 
 			story s 
 			LEFT JOIN type t ON s.type_id = t.type_id 
@@ -332,10 +529,11 @@ func GetAllStoriesPreview(page, pageSize int, keyword string, typeID int) (Respo
 			LEFT JOIN story_genre sg ON s.story_id = sg.story_id 
 			LEFT JOIN genre g ON sg.genre_id = g.genre_id ` + whereClause + `
 		GROUP BY 
-			s.story_id 
+			s.story_id ` + orderClause + `
 		LIMIT ? OFFSET ?`
 
-	rows, err := con.Query(sqlStatement, pageSize, offset)
+	args = append(args, orderArgs...)
+	rows, err := con.Query(sqlStatement, append(args, pageSize, offset)...)
 	if err != nil {
 		return res, err
 	}
@@ -343,7 +541,7 @@ func GetAllStoriesPreview(page, pageSize int, keyword string, typeID int) (Respo
 
 	for rows.Next() {
 		var obj StoryPreview // Menggunakan struktur StoryPreview
-		var genreNames sql.NullString
+		var genreIDs, genreNames, locales sql.NullString
 		err := rows.Scan(
 			&obj.StoryID,
 			&obj.TypeID,
@@ -353,11 +551,14 @@ func GetAllStoriesPreview(page, pageSize int, keyword string, typeID int) (Respo
 			&obj.ReleasedDate,
 			&obj.ThumbnailImage,
 			&obj.ReadCount,
+			&obj.LikeCount,
 			&obj.IsHighlighted,
 			&obj.IsFavorited,
 			&obj.TypeName,
 			&obj.OriginName,
+			&genreIDs,
 			&genreNames,
+			&locales,
 		)
 		if err != nil {
 			return res, err
@@ -366,10 +567,16 @@ func GetAllStoriesPreview(page, pageSize int, keyword string, typeID int) (Respo
 		// Convert time fields to UTC+8 (Asia/Shanghai) before including them in the response
 		obj.ReleasedDate = obj.ReleasedDate.In(loc)
 
-		// Parse genre names
+		// Parse genres and locales
+		if genreIDs.Valid {
+			obj.GenreID = stringsToIntSlice2(genreIDs.String)
+		}
 		if genreNames.Valid {
 			obj.GenreName = strings.Split(genreNames.String, ",")
 		}
+		if locales.Valid {
+			obj.Locales = strings.Split(locales.String, ",")
+		}
 
 		arrobj = append(arrobj, obj)
 
@@ -379,6 +586,21 @@ func GetAllStoriesPreview(page, pageSize int, keyword string, typeID int) (Respo
 		meta.TotalItems = totalItems
 	}
 
+	// Swap titles and taxonomy names for the requested locale
+	if l := newLocalizer(locale); l != nil {
+		if err := l.localizeStoryPreviews(arrobj); err != nil {
+			return res, err
+		}
+	}
+
+	if err := attachPreviewThumbnailMeta(con, arrobj); err != nil {
+		return res, err
+	}
+
+	if err := attachPreviewRatings(con, arrobj); err != nil {
+		return res, err
+	}
+
 	res.Data = map[string]interface{}{
 		"stories": arrobj,
 		"meta":    meta,
@@ -416,7 +638,7 @@ func GetStoryContentOnList(storyID int) ([]StoryContentOnList, error) {
 	return content, nil
 }
 
-func GetStoryDetail(storyID int, userID *int, uid *string) (Response, error) {
+func GetStoryDetail(storyID int, userID *int, uid *string, locale string) (Response, error) {
 	var storyDetail StoryDetail
 	var res Response
 
@@ -425,20 +647,23 @@ func GetStoryDetail(storyID int, userID *int, uid *string) (Response, error) {
 	sqlStatement := `
 		SELECT s.story_id, s.type_id, t.type_name, s.origin_id, o.origin_name, 
         s.title, s.total_content, s.released_date, s.thumbnail_image, 
-        s.read_count, s.is_highligthed, s.is_favorited, s.synopsis,
-		GROUP_CONCAT(sg.genre_id) AS genre_id, GROUP_CONCAT(g.genre_name) AS genre_name
+        s.read_count, s.like_count, s.is_highligthed, s.is_favorited, s.synopsis,
+		COALESCE(s.moral_indo, ''), COALESCE(s.moral_eng, ''),
+		GROUP_CONCAT(sg.genre_id) AS genre_id, GROUP_CONCAT(g.genre_name) AS genre_name,
+		` + storyLocalesColumn + `
 		FROM story s 
 		LEFT JOIN type t ON s.type_id = t.type_id 
 		LEFT JOIN origin o ON s.origin_id = o.origin_id 
 		LEFT JOIN story_genre sg ON s.story_id = sg.story_id 
 		LEFT JOIN genre g ON sg.genre_id = g.genre_id 
-		WHERE s.story_id = ?
+		WHERE s.story_id = ? AND ` + publishedStoryCondition + `
 		GROUP BY s.story_id
 	`
 
 	row := con.QueryRow(sqlStatement, storyID)
 
 	var genreIDs, genreNames string
+	var locales sql.NullString
 	err := row.Scan(
 		&storyDetail.StoryID,
 		&storyDetail.TypeID,
@@ -450,11 +675,15 @@ func GetStoryDetail(storyID int, userID *int, uid *string) (Response, error) {
 		&storyDetail.ReleasedDate,
 		&storyDetail.ThumbnailImage,
 		&storyDetail.ReadCount,
+		&storyDetail.LikeCount,
 		&storyDetail.IsHighlighted,
 		&storyDetail.IsFavorited,
 		&storyDetail.Synopsis,
+		&storyDetail.MoralIndo,
+		&storyDetail.MoralEng,
 		&genreIDs,
 		&genreNames,
+		&locales,
 	)
 
 	if err != nil {
@@ -473,6 +702,73 @@ func GetStoryDetail(storyID int, userID *int, uid *string) (Response, error) {
 	// Split genre IDs and names into slices
 	storyDetail.GenreID = stringsToIntSlice2(genreIDs)
 	storyDetail.GenreName = strings.Split(genreNames, ",")
+	if locales.Valid {
+		storyDetail.Locales = strings.Split(locales.String, ",")
+	}
+
+	storyDetail.MoralValues, err = getStoryMoralValues(con, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	storyDetail.Characters, err = getStoryCharacters(con, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	storyDetail.Tags, err = getStoryTags(con, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	ratings, err := getRatingSummaries(con, []int{storyID})
+	if err != nil {
+		return res, err
+	}
+	storyDetail.Rating = ratings[storyID]
+
+	// Include the reader's own review so the app can offer to edit it
+	if userID != nil {
+		storyDetail.MyReview, err = getUserReview(con, storyID, *userID)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	thumbnailMeta, err := getImageMetaByURL(con, []string{storyDetail.ThumbnailImage})
+	if err != nil {
+		return res, err
+	}
+	storyDetail.ThumbnailMeta = thumbnailMeta[storyDetail.ThumbnailImage]
+
+	// Swap title, synopsis and taxonomy names for the requested locale
+	if l := newLocalizer(locale); l != nil {
+		titles, err := l.storyTitles([]int{storyID})
+		if err != nil {
+			return res, err
+		}
+		if translation, ok := titles[storyID]; ok {
+			storyDetail.Title = translation.Title
+			if translation.Synopsis != "" {
+				storyDetail.Synopsis = translation.Synopsis
+			}
+		}
+		if storyDetail.TypeName, err = l.taxonomyName(TaxonomyType, storyDetail.TypeID, storyDetail.TypeName); err != nil {
+			return res, err
+		}
+		if storyDetail.OriginName, err = l.taxonomyName(TaxonomyOrigin, storyDetail.OriginID, storyDetail.OriginName); err != nil {
+			return res, err
+		}
+		if storyDetail.GenreName, err = l.genreNames(storyDetail.GenreID, storyDetail.GenreName); err != nil {
+			return res, err
+		}
+		for i := range storyDetail.MoralValues {
+			moralValue := &storyDetail.MoralValues[i]
+			if moralValue.ValueName, err = l.taxonomyName(TaxonomyMoralValue, moralValue.MoralValueID, moralValue.ValueName); err != nil {
+				return res, err
+			}
+		}
+	}
 
 	// Check if the story is bookmarked by the user, if userID or uid is provided
 	var bookmarkID int
@@ -502,6 +798,17 @@ func GetStoryDetail(storyID int, userID *int, uid *string) (Response, error) {
 		storyDetail.BookmarkID = 0
 	}
 
+	// Check if the story is liked by the user, if userID or uid is provided
+	if userID != nil || uid != nil {
+		liked, err := isStoryLiked(con, storyID, userID, uid)
+		if err != nil {
+			return res, err
+		}
+		if liked {
+			storyDetail.IsLiked = 1
+		}
+	}
+
 	res.Data = map[string]interface{}{
 		"story": storyDetail,
 	}
@@ -514,9 +821,23 @@ func CreateStory(story Story) (Response, error) {
 
 	con := db.CreateCon()
 
-	sqlStatement := "INSERT INTO story (type_id, origin_id, title, total_content, released_date, synopsis, thumbnail_image, read_count, is_highligthed, is_favorited, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	// New stories start as drafts unless a status is given
+	if story.Status == "" {
+		story.Status = StoryStatusDraft
+	}
+	if err := validateStoryStatus(story.Status, &story.ReleasedDate); err != nil {
+		return res, err
+	}
+
+	sqlStatement := "INSERT INTO story (type_id, origin_id, title, total_content, released_date, synopsis, moral_indo, moral_eng, thumbnail_image, read_count, is_highligthed, is_favorited, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
 
-	stmt, err := con.Prepare(sqlStatement)
+	stmt, err := tx.Prepare(sqlStatement)
 
 	if err != nil {
 		return res, err
@@ -538,10 +859,13 @@ func CreateStory(story Story) (Response, error) {
 		story.TotalContent,
 		story.ReleasedDate,
 		story.Synopsis,
+		story.MoralIndo,
+		story.MoralEng,
 		story.ThumbnailImage,
 		story.ReadCount,
 		story.IsHighlighted,
 		story.IsFavorited,
+		story.Status,
 		story.CreatedAt,
 		story.UpdatedAt,
 	)
@@ -556,6 +880,37 @@ func CreateStory(story Story) (Response, error) {
 		return res, err
 	}
 
+	if err := syncStoryTitleTranslation(tx, int(getIDLast)); err != nil {
+		return res, err
+	}
+
+	// Store the pages sent along with the story
+	if len(story.StoryContent) > 0 {
+		if err := replaceStoryContent(tx, int(getIDLast), story.StoryContent); err != nil {
+			return res, err
+		}
+	}
+
+	if _, err := recordStoryRevision(tx, int(getIDLast), "Story created"); err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryCreated, int(getIDLast)); err != nil {
+		return res, err
+	}
+	if story.Status == StoryStatusPublished {
+		if err := enqueueWebhookEvent(tx, WebhookEventStoryPublished, int(getIDLast)); err != nil {
+			return res, err
+		}
+		if err := notifyStoryPublished(tx, int(getIDLast)); err != nil {
+			return res, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
 	res.Data = map[string]interface{}{
 		"getIDLast":  getIDLast,
 		"created_at": story.CreatedAt.In(loc),
@@ -567,6 +922,11 @@ func CreateStory(story Story) (Response, error) {
 func UpdateStory(storyID int, updateFields map[string]interface{}) (Response, error) {
 	var res Response
 
+	// Status changes go through UpdateStoryStatus, which checks the release date
+	if _, ok := updateFields["status"]; ok {
+		return res, fmt.Errorf("%w: change the status with PUT /api/v1/story/status", ErrInvalidStoryStatus)
+	}
+
 	// Load the UTC+8 time zone
 	loc, err := time.LoadLocation("Asia/Shanghai")
 	if err != nil {
@@ -597,7 +957,18 @@ func UpdateStory(storyID int, updateFields map[string]interface{}) (Response, er
 	sqlStatement := "UPDATE story " + setStatement + " WHERE story_id = ?"
 	values = append(values, storyID)
 
-	stmt, err := con.Prepare(sqlStatement)
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	// Keep the state before this edit for stories created before revisions existed
+	if err := ensureStoryRevisionBaseline(tx, storyID); err != nil && err != sql.ErrNoRows {
+		return res, err
+	}
+
+	stmt, err := tx.Prepare(sqlStatement)
 	if err != nil {
 		return res, err
 	}
@@ -612,6 +983,29 @@ func UpdateStory(storyID int, updateFields map[string]interface{}) (Response, er
 		return res, err
 	}
 
+	// The story title and synopsis are the Indonesian translation
+	_, titleChanged := updateFields["title"]
+	_, synopsisChanged := updateFields["synopsis"]
+	if rowsAffected > 0 && (titleChanged || synopsisChanged) {
+		if err := syncStoryTitleTranslation(tx, storyID); err != nil {
+			return res, err
+		}
+	}
+
+	if rowsAffected > 0 {
+		if _, err := recordStoryRevision(tx, storyID, "Story updated"); err != nil {
+			return res, err
+		}
+
+		if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+			return res, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
 	res.Data = map[string]interface{}{
 		"rowsAffected": rowsAffected,
 		"updated_at":   updated_at,
@@ -620,6 +1014,82 @@ func UpdateStory(storyID int, updateFields map[string]interface{}) (Response, er
 	return res, nil
 }
 
+// UpdateStoryContent replaces all pages of a story and records the change as a revision
+func UpdateStoryContent(storyID int, content []StoryContentOnList) (Response, error) {
+	var res Response
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	if err := ensureStoryRevisionBaseline(tx, storyID); err != nil {
+		return res, err
+	}
+
+	if err := replaceStoryContent(tx, storyID, content); err != nil {
+		return res, err
+	}
+
+	updatedAt := time.Now()
+	if _, err := tx.Exec("UPDATE story SET updated_at = ? WHERE story_id = ?", updatedAt, storyID); err != nil {
+		return res, err
+	}
+
+	revisionID, err := recordStoryRevision(tx, storyID, "Story content updated")
+	if err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"total_content": len(content),
+		"revision_id":   revisionID,
+		"updated_at":    updatedAt.In(loc),
+	}
+
+	return res, nil
+}
+
+// replaceStoryContent swaps the pages of a story for the given ones and keeps total_content in sync
+func replaceStoryContent(ex dbExecutor, storyID int, content []StoryContentOnList) error {
+	if _, err := ex.Exec("DELETE FROM story_content WHERE story_id = ?", storyID); err != nil {
+		return err
+	}
+
+	for _, c := range content {
+		_, err := ex.Exec(
+			"INSERT INTO story_content (story_id, `order`, image, content_indo, content_eng) VALUES (?, ?, ?, ?, ?)",
+			storyID, c.Order, c.Image, c.ContentIndo, c.ContentEng,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := ex.Exec("UPDATE story SET total_content = ? WHERE story_id = ?", len(content), storyID); err != nil {
+		return err
+	}
+
+	return syncStoryContentTranslations(ex, storyID, content)
+}
+
 func DeleteStory(storyID int) (Response, error) {
 	var res Response
 
@@ -627,7 +1097,18 @@ func DeleteStory(storyID int) (Response, error) {
 
 	sqlStatement := "DELETE FROM story WHERE story_id = ?"
 
-	stmt, err := con.Prepare(sqlStatement)
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	// The event carries the story as it was, so it is queued before the delete
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryDeleted, storyID); err != nil {
+		return res, err
+	}
+
+	stmt, err := tx.Prepare(sqlStatement)
 
 	if err != nil {
 		return res, err
@@ -644,6 +1125,10 @@ func DeleteStory(storyID int) (Response, error) {
 		return res, err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
 	res.Data = map[string]interface{}{
 		"rowsAffected":     rowsAffected,
 		"deleted_story_id": storyID,
@@ -652,7 +1137,7 @@ func DeleteStory(storyID int) (Response, error) {
 	return res, err
 }
 
-func GetStoriesRecommendationRandom(limit int, excludeStoryID int) (Response, error) {
+func GetStoriesRecommendationRandom(limit int, excludeStoryID int, locale string) (Response, error) {
 	var res Response
 	var arrobj []StoryPreview
 
@@ -668,19 +1153,21 @@ func GetStoriesRecommendationRandom(limit int, excludeStoryID int) (Response, er
 			s.total_content, 
 			s.released_date, 
 			s.thumbnail_image, 
-			s.read_count, 
+			s.read_count, s.like_count, 
 			s.is_highligthed, 
 			s.is_favorited, 
 			t.type_name, 
 			o.origin_name, 
-			GROUP_CONCAT(g.genre_name) AS genre_name 
+			GROUP_CONCAT(g.genre_id) AS genre_id, 
+			GROUP_CONCAT(g.genre_name) AS genre_name, 
+			` + storyLocalesColumn + ` 
 		FROM 
 			story s 
 			LEFT JOIN type t ON s.type_id = t.type_id 
 			LEFT JOIN origin o ON s.origin_id = o.origin_id 
 			LEFT JOIN story_genre sg ON s.story_id = sg.story_id 
 			LEFT JOIN genre g ON sg.genre_id = g.genre_id 
-		WHERE s.story_id != ?
+		WHERE s.story_id != ? AND ` + publishedStoryCondition + `
 		GROUP BY 
 			s.story_id 
 		ORDER BY 
@@ -696,7 +1183,7 @@ func GetStoriesRecommendationRandom(limit int, excludeStoryID int) (Response, er
 
 	for rows.Next() {
 		var obj StoryPreview
-		var genreNames sql.NullString
+		var genreIDs, genreNames, locales sql.NullString
 		err := rows.Scan(
 			&obj.StoryID,
 			&obj.TypeID,
@@ -706,11 +1193,14 @@ func GetStoriesRecommendationRandom(limit int, excludeStoryID int) (Response, er
 			&obj.ReleasedDate,
 			&obj.ThumbnailImage,
 			&obj.ReadCount,
+			&obj.LikeCount,
 			&obj.IsHighlighted,
 			&obj.IsFavorited,
 			&obj.TypeName,
 			&obj.OriginName,
+			&genreIDs,
 			&genreNames,
+			&locales,
 		)
 		if err != nil {
 			return res, err
@@ -723,14 +1213,35 @@ func GetStoriesRecommendationRandom(limit int, excludeStoryID int) (Response, er
 		}
 		obj.ReleasedDate = obj.ReleasedDate.In(loc)
 
-		// Parse genre names
+		// Parse genres and locales
+		if genreIDs.Valid {
+			obj.GenreID = stringsToIntSlice2(genreIDs.String)
+		}
 		if genreNames.Valid {
 			obj.GenreName = strings.Split(genreNames.String, ",")
 		}
+		if locales.Valid {
+			obj.Locales = strings.Split(locales.String, ",")
+		}
 
 		arrobj = append(arrobj, obj)
 	}
 
+	// Swap titles and taxonomy names for the requested locale
+	if l := newLocalizer(locale); l != nil {
+		if err := l.localizeStoryPreviews(arrobj); err != nil {
+			return res, err
+		}
+	}
+
+	if err := attachPreviewThumbnailMeta(con, arrobj); err != nil {
+		return res, err
+	}
+
+	if err := attachPreviewRatings(con, arrobj); err != nil {
+		return res, err
+	}
+
 	res.Data = map[string]interface{}{
 		"stories": arrobj,
 	}
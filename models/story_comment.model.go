@@ -0,0 +1,399 @@
+// Story Comment Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"kisahloka_be/moderation"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+	CommentStatusHidden   = "hidden"
+)
+
+// IsValidCommentStatus reports whether status is one of the known comment statuses
+func IsValidCommentStatus(status string) bool {
+	switch status {
+	case CommentStatusPending, CommentStatusApproved, CommentStatusRejected, CommentStatusHidden:
+		return true
+	}
+	return false
+}
+
+const maxCommentLength = 2000
+
+// ErrInvalidComment is returned for comments that cannot be posted as sent
+var ErrInvalidComment = errors.New("invalid comment")
+
+// isTrustedCommenter reports whether a user's new comments may skip the
+// moderation queue. Every rejected or hidden comment cancels out threshold
+// approved ones, so a user has to earn trust back after being moderated.
+func isTrustedCommenter(approved, removed, threshold int) bool {
+	return approved-removed*threshold >= threshold
+}
+
+// StoryComment is a comment on a story or, with a ParentID, a reply to another comment
+type StoryComment struct {
+	CommentID    int            `json:"comment_id"`
+	StoryID      int            `json:"story_id"`
+	UserID       int            `json:"user_id"`
+	UserName     string         `json:"user_name"`
+	ParentID     *int           `json:"parent_id"`
+	Content      string         `json:"content"`
+	Status       string         `json:"status"`
+	FlaggedWords []string       `json:"flagged_words,omitempty"`
+	ModeratedBy  *int           `json:"moderated_by,omitempty"`
+	ModeratedAt  *time.Time     `json:"moderated_at,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	Replies      []StoryComment `json:"replies,omitempty"`
+}
+
+const storyCommentColumns = "c.comment_id, c.story_id, c.user_id, COALESCE(u.name, ''), c.parent_id, c.content, c.status, COALESCE(c.flagged_words, ''), c.moderated_by, c.moderated_at, c.created_at, c.updated_at"
+
+func scanStoryComment(row interface{ Scan(...interface{}) error }, loc *time.Location) (StoryComment, error) {
+	var comment StoryComment
+	var parentID, moderatedBy sql.NullInt64
+	var moderatedAt sql.NullTime
+	var flaggedWords string
+	err := row.Scan(
+		&comment.CommentID,
+		&comment.StoryID,
+		&comment.UserID,
+		&comment.UserName,
+		&parentID,
+		&comment.Content,
+		&comment.Status,
+		&flaggedWords,
+		&moderatedBy,
+		&moderatedAt,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+	if err != nil {
+		return comment, err
+	}
+
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		comment.ParentID = &id
+	}
+	if moderatedBy.Valid {
+		id := int(moderatedBy.Int64)
+		comment.ModeratedBy = &id
+	}
+	if flaggedWords != "" {
+		comment.FlaggedWords = strings.Split(flaggedWords, ",")
+	}
+
+	// Convert time fields to UTC+8 (Asia/Shanghai) before including them in the response
+	if moderatedAt.Valid {
+		t := moderatedAt.Time.In(loc)
+		comment.ModeratedAt = &t
+	}
+	comment.CreatedAt = comment.CreatedAt.In(loc)
+	comment.UpdatedAt = comment.UpdatedAt.In(loc)
+
+	return comment, nil
+}
+
+func queryStoryComments(ex dbExecutor, sqlStatement string, args ...interface{}) ([]StoryComment, error) {
+	comments := []StoryComment{}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ex.Query(sqlStatement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		comment, err := scanStoryComment(rows, loc)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+// GetStoryComments lists a page of top-level comments on a story, newest first,
+// each with its replies nested oldest first. Readers see approved comments;
+// viewerID also shows that user's own comments still waiting for moderation.
+func GetStoryComments(storyID, page, pageSize int, viewerID *int) (Response, error) {
+	var res Response
+	var meta Meta
+
+	con := db.CreateCon()
+
+	visible := "c.status = '" + CommentStatusApproved + "'"
+	visibleArgs := []interface{}{}
+	if viewerID != nil {
+		visible = "(" + visible + " OR (c.user_id = ? AND c.status = '" + CommentStatusPending + "'))"
+		visibleArgs = append(visibleArgs, *viewerID)
+	}
+
+	var totalItems int
+	err := con.QueryRow("SELECT COUNT(*) FROM story_comment c WHERE c.story_id = ? AND c.parent_id IS NULL AND "+visible, append([]interface{}{storyID}, visibleArgs...)...).Scan(&totalItems)
+	if err != nil {
+		return res, err
+	}
+
+	meta.Limit = pageSize
+	meta.Page = page
+	meta.TotalPages = calculateTotalPages(totalItems, pageSize)
+	meta.TotalItems = totalItems
+
+	comments := []StoryComment{}
+	if totalItems > 0 {
+		// Check if the requested page is greater than the total number of pages
+		if page > meta.TotalPages {
+			return res, fmt.Errorf("requested page (%d) exceeds total number of pages (%d)", page, meta.TotalPages)
+		}
+
+		// Calculate the offset based on the page number and page size
+		offset := (page - 1) * pageSize
+
+		args := append([]interface{}{storyID}, visibleArgs...)
+		comments, err = queryStoryComments(con,
+			"SELECT "+storyCommentColumns+" FROM story_comment c LEFT JOIN user u ON c.user_id = u.user_id WHERE c.story_id = ? AND c.parent_id IS NULL AND "+visible+" ORDER BY c.created_at DESC, c.comment_id DESC LIMIT ? OFFSET ?",
+			append(args, pageSize, offset)...,
+		)
+		if err != nil {
+			return res, err
+		}
+
+		// Load every visible reply of the story once and hang them under their parents
+		replies, err := queryStoryComments(con,
+			"SELECT "+storyCommentColumns+" FROM story_comment c LEFT JOIN user u ON c.user_id = u.user_id WHERE c.story_id = ? AND c.parent_id IS NOT NULL AND "+visible+" ORDER BY c.created_at, c.comment_id",
+			append([]interface{}{storyID}, visibleArgs...)...,
+		)
+		if err != nil {
+			return res, err
+		}
+		comments = nestCommentReplies(comments, replies)
+	}
+
+	res.Data = map[string]interface{}{
+		"comments": comments,
+		"meta":     meta,
+	}
+
+	return res, nil
+}
+
+// nestCommentReplies attaches replies, at any depth, to the given top-level
+// comments. Replies whose parent is not visible are dropped with it.
+func nestCommentReplies(comments, replies []StoryComment) []StoryComment {
+	children := make(map[int][]StoryComment)
+	for _, reply := range replies {
+		children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+	}
+
+	var attach func(comment StoryComment) StoryComment
+	attach = func(comment StoryComment) StoryComment {
+		for _, child := range children[comment.CommentID] {
+			comment.Replies = append(comment.Replies, attach(child))
+		}
+		return comment
+	}
+
+	for i := range comments {
+		comments[i] = attach(comments[i])
+	}
+
+	return comments
+}
+
+// CreateStoryComment posts a comment or a reply for a user. It is approved
+// straight away only when the user is a trusted commenter and no banned word
+// was found; otherwise it waits in the moderation queue.
+func CreateStoryComment(storyID, userID int, parentID *int, content string) (Response, error) {
+	var res Response
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return res, fmt.Errorf("%w: content is required", ErrInvalidComment)
+	}
+	if utf8.RuneCountInString(content) > maxCommentLength {
+		return res, fmt.Errorf("%w: content is longer than %d characters", ErrInvalidComment, maxCommentLength)
+	}
+
+	con := db.CreateCon()
+
+	var exists int
+	if err := con.QueryRow("SELECT COUNT(*) FROM story s WHERE s.story_id = ? AND "+publishedStoryCondition, storyID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	if parentID != nil {
+		var parentStoryID int
+		var parentStatus string
+		err := con.QueryRow("SELECT story_id, status FROM story_comment WHERE comment_id = ?", *parentID).Scan(&parentStoryID, &parentStatus)
+		if err == sql.ErrNoRows || (err == nil && parentStoryID != storyID) {
+			return res, fmt.Errorf("%w: parent comment %d is not on story %d", ErrInvalidComment, *parentID, storyID)
+		}
+		if err != nil {
+			return res, err
+		}
+		if parentStatus == CommentStatusRejected || parentStatus == CommentStatusHidden {
+			return res, fmt.Errorf("%w: parent comment %d was removed by a moderator", ErrInvalidComment, *parentID)
+		}
+	}
+
+	var approvedCount, removedCount int
+	err := con.QueryRow(
+		"SELECT COALESCE(SUM(status = ?), 0), COALESCE(SUM(status IN (?, ?)), 0) FROM story_comment WHERE user_id = ?",
+		CommentStatusApproved, CommentStatusRejected, CommentStatusHidden, userID,
+	).Scan(&approvedCount, &removedCount)
+	if err != nil {
+		return res, err
+	}
+
+	flaggedWords := moderation.GetFilter().Matches(content)
+
+	status := CommentStatusPending
+	if len(flaggedWords) == 0 && isTrustedCommenter(approvedCount, removedCount, moderation.TrustThreshold()) {
+		status = CommentStatusApproved
+	}
+
+	var flagged interface{}
+	if len(flaggedWords) > 0 {
+		flagged = strings.Join(flaggedWords, ",")
+	}
+
+	now := time.Now()
+	result, err := con.Exec("INSERT INTO story_comment (story_id, user_id, parent_id, content, status, flagged_words, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		storyID, userID, parentID, content, status, flagged, now, now,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	commentID, err := result.LastInsertId()
+	if err != nil {
+		return res, err
+	}
+
+	comment, err := getStoryComment(con, int(commentID))
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"comment": comment,
+	}
+
+	return res, nil
+}
+
+func getStoryComment(ex dbExecutor, commentID int) (StoryComment, error) {
+	comments, err := queryStoryComments(ex, "SELECT "+storyCommentColumns+" FROM story_comment c LEFT JOIN user u ON c.user_id = u.user_id WHERE c.comment_id = ?", commentID)
+	if err != nil {
+		return StoryComment{}, err
+	}
+	if len(comments) == 0 {
+		return StoryComment{}, sql.ErrNoRows
+	}
+	return comments[0], nil
+}
+
+// GetModerationQueue lists comments with the given status for moderators,
+// flagged comments first and then oldest first
+func GetModerationQueue(status string, page, pageSize int) (Response, error) {
+	var res Response
+	var meta Meta
+
+	con := db.CreateCon()
+
+	var totalItems int
+	if err := con.QueryRow("SELECT COUNT(*) FROM story_comment WHERE status = ?", status).Scan(&totalItems); err != nil {
+		return res, err
+	}
+
+	meta.Limit = pageSize
+	meta.Page = page
+	meta.TotalPages = calculateTotalPages(totalItems, pageSize)
+	meta.TotalItems = totalItems
+
+	comments := []StoryComment{}
+	if totalItems > 0 {
+		// Check if the requested page is greater than the total number of pages
+		if page > meta.TotalPages {
+			return res, fmt.Errorf("requested page (%d) exceeds total number of pages (%d)", page, meta.TotalPages)
+		}
+
+		// Calculate the offset based on the page number and page size
+		offset := (page - 1) * pageSize
+
+		var err error
+		comments, err = queryStoryComments(con,
+			"SELECT "+storyCommentColumns+" FROM story_comment c LEFT JOIN user u ON c.user_id = u.user_id WHERE c.status = ? ORDER BY c.flagged_words IS NULL, c.created_at, c.comment_id LIMIT ? OFFSET ?",
+			status, pageSize, offset,
+		)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	res.Data = map[string]interface{}{
+		"comments": comments,
+		"meta":     meta,
+	}
+
+	return res, nil
+}
+
+// ModerateStoryComment sets the status of a comment on behalf of a moderator
+func ModerateStoryComment(commentID int, status string, moderatorID *int) (Response, error) {
+	var res Response
+
+	if !IsValidCommentStatus(status) {
+		return res, fmt.Errorf("invalid status %q", status)
+	}
+
+	con := db.CreateCon()
+
+	now := time.Now()
+	result, err := con.Exec("UPDATE story_comment SET status = ?, moderated_by = ?, moderated_at = ?, updated_at = ? WHERE comment_id = ?",
+		status, moderatorID, now, now, commentID,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return res, err
+	} else if rowsAffected == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	comment, err := getStoryComment(con, commentID)
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"comment": comment,
+	}
+
+	return res, nil
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestIsTrustedCommenter(t *testing.T) {
+	tests := []struct {
+		name              string
+		approved, removed int
+		want              bool
+	}{
+		{"new user", 0, 0, false},
+		{"below threshold", 2, 0, false},
+		{"at threshold", 3, 0, true},
+		{"removed comment cancels trust", 3, 1, false},
+		{"trust earned back", 6, 1, true},
+		{"repeatedly moderated", 8, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTrustedCommenter(tt.approved, tt.removed, 3); got != tt.want {
+				t.Fatalf("isTrustedCommenter(%d, %d, 3) = %v, want %v", tt.approved, tt.removed, got, tt.want)
+			}
+		})
+	}
+}
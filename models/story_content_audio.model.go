@@ -0,0 +1,284 @@
+// Story Content Audio Model
+
+package models
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"kisahloka_be/audioprobe"
+	"kisahloka_be/db"
+	"kisahloka_be/storage"
+	"log"
+	"time"
+)
+
+type StoryContentAudio struct {
+	AudioID    int       `json:"audio_id"`
+	StoryID    int       `json:"story_id"`
+	Order      int       `json:"order"`
+	Locale     string    `json:"locale"`
+	URL        string    `json:"url"`
+	FilePath   string    `json:"-"`
+	MimeType   string    `json:"mime_type"`
+	FileSize   int64     `json:"file_size"`
+	DurationMs int       `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AudioMimeTypes maps the accepted narration file extensions to their MIME type.
+// The type of an upload is detected from its content, the extension only names the stored file.
+var AudioMimeTypes = map[string]string{
+	".mp3": "audio/mpeg",
+	".m4a": "audio/mp4",
+	".aac": "audio/aac",
+	".ogg": "audio/ogg",
+	".wav": "audio/wav",
+}
+
+// MaxAudioFileSize is the largest narration file accepted, in bytes
+const MaxAudioFileSize = 20 << 20
+
+func audioURL(audioID int) string {
+	return fmt.Sprintf("/api/v1/audio/%d", audioID)
+}
+
+// SaveStoryContentAudio stores a narration file for one page in one locale,
+// replacing the previous file of that page and locale. The MIME type and
+// duration are read from the file itself; unrecognized files give
+// audioprobe.ErrUnsupported.
+func SaveStoryContentAudio(audio StoryContentAudio, file io.Reader) (Response, error) {
+	var res Response
+
+	if !IsSupportedLocale(audio.Locale) {
+		return res, fmt.Errorf("unsupported locale %q", audio.Locale)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxAudioFileSize+1))
+	if err != nil {
+		return res, err
+	}
+	if len(data) > MaxAudioFileSize {
+		return res, fmt.Errorf("file is larger than %d bytes", MaxAudioFileSize)
+	}
+
+	info, err := audioprobe.Probe(data)
+	if err != nil {
+		return res, err
+	}
+	if info.DurationMs <= 0 {
+		return res, fmt.Errorf("%w: audio is empty", audioprobe.ErrUnsupported)
+	}
+	audio.MimeType = info.MimeType
+	audio.DurationMs = info.DurationMs
+
+	con := db.CreateCon()
+
+	var exists int
+	err = con.QueryRow("SELECT COUNT(*) FROM story_content WHERE story_id = ? AND `order` = ?", audio.StoryID, audio.Order).Scan(&exists)
+	if err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, fmt.Errorf("story %d has no page with order %d", audio.StoryID, audio.Order)
+	}
+
+	// Write the file first so a failed upload never leaves a row without a file
+	ext := ""
+	for candidate, mimeType := range AudioMimeTypes {
+		if mimeType == audio.MimeType {
+			ext = candidate
+		}
+	}
+	if ext == "" {
+		return res, fmt.Errorf("unsupported audio type %q", audio.MimeType)
+	}
+
+	media, err := storeMediaFile("audio", ext, audio.MimeType, bytes.NewReader(data), MaxAudioFileSize)
+	if err != nil {
+		return res, err
+	}
+	audio.FilePath = media.Key
+	audio.FileSize = media.FileSize
+
+	var previousPath string
+	err = con.QueryRow("SELECT file_path FROM story_content_audio WHERE story_id = ? AND `order` = ? AND locale = ?", audio.StoryID, audio.Order, audio.Locale).Scan(&previousPath)
+	if err != nil && err != sql.ErrNoRows {
+		return res, err
+	}
+
+	now := time.Now()
+	_, err = con.Exec(
+		"INSERT INTO story_content_audio (story_id, `order`, locale, file_path, mime_type, file_size, duration_ms, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE file_path = VALUES(file_path), mime_type = VALUES(mime_type), file_size = VALUES(file_size), duration_ms = VALUES(duration_ms), updated_at = VALUES(updated_at)",
+		audio.StoryID, audio.Order, audio.Locale, audio.FilePath, audio.MimeType, audio.FileSize, audio.DurationMs, now, now,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	if previousPath != "" && previousPath != audio.FilePath {
+		removeUnusedAudioFile(previousPath)
+	}
+
+	// LastInsertId is not reliable after ON DUPLICATE KEY UPDATE, so read the ID back
+	err = con.QueryRow("SELECT audio_id FROM story_content_audio WHERE story_id = ? AND `order` = ? AND locale = ?", audio.StoryID, audio.Order, audio.Locale).Scan(&audio.AudioID)
+	if err != nil {
+		return res, err
+	}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	audio.URL = audioURL(audio.AudioID)
+	audio.UpdatedAt = now.In(loc)
+
+	res.Data = map[string]interface{}{
+		"audio": audio,
+	}
+
+	return res, nil
+}
+
+// GetStoryContentAudio retrieves the metadata of one narration file
+func GetStoryContentAudio(audioID int) (StoryContentAudio, error) {
+	var audio StoryContentAudio
+
+	con := db.CreateCon()
+
+	err := con.QueryRow("SELECT audio_id, story_id, `order`, locale, file_path, mime_type, file_size, duration_ms, created_at, updated_at FROM story_content_audio WHERE audio_id = ?", audioID).Scan(
+		&audio.AudioID,
+		&audio.StoryID,
+		&audio.Order,
+		&audio.Locale,
+		&audio.FilePath,
+		&audio.MimeType,
+		&audio.FileSize,
+		&audio.DurationMs,
+		&audio.CreatedAt,
+		&audio.UpdatedAt,
+	)
+	if err != nil {
+		return audio, err
+	}
+
+	audio.URL = audioURL(audio.AudioID)
+
+	return audio, nil
+}
+
+// OpenStoryContentAudio opens the narration file of a published story for streaming
+func OpenStoryContentAudio(audioID int) (StoryContentAudio, io.ReadSeekCloser, error) {
+	con := db.CreateCon()
+
+	var published int
+	err := con.QueryRow("SELECT COUNT(*) FROM story_content_audio a JOIN story s ON a.story_id = s.story_id WHERE a.audio_id = ? AND "+publishedStoryCondition, audioID).Scan(&published)
+	if err != nil {
+		return StoryContentAudio{}, nil, err
+	}
+	if published == 0 {
+		return StoryContentAudio{}, nil, sql.ErrNoRows
+	}
+
+	audio, err := GetStoryContentAudio(audioID)
+	if err != nil {
+		return audio, nil, err
+	}
+
+	file, err := storage.GetStorage().Open(audio.FilePath)
+	if err != nil {
+		return audio, nil, err
+	}
+
+	return audio, file, nil
+}
+
+// DeleteStoryContentAudio removes a narration file and its metadata
+func DeleteStoryContentAudio(audioID int) (Response, error) {
+	var res Response
+
+	audio, err := GetStoryContentAudio(audioID)
+	if err != nil {
+		return res, err
+	}
+
+	con := db.CreateCon()
+
+	result, err := con.Exec("DELETE FROM story_content_audio WHERE audio_id = ?", audioID)
+	if err != nil {
+		return res, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+
+	removeUnusedAudioFile(audio.FilePath)
+
+	res.Data = map[string]interface{}{
+		"rowsAffected":     rowsAffected,
+		"deleted_audio_id": audioID,
+	}
+
+	return res, nil
+}
+
+// getStoryAudioByPage returns the narration of every page of a story keyed by page order
+func getStoryAudioByPage(ex dbExecutor, storyID int) (map[int][]StoryContentAudio, error) {
+	audioByPage := make(map[int][]StoryContentAudio)
+
+	rows, err := ex.Query("SELECT audio_id, story_id, `order`, locale, mime_type, file_size, duration_ms, created_at, updated_at FROM story_content_audio WHERE story_id = ? ORDER BY `order`, locale", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var audio StoryContentAudio
+		err := rows.Scan(
+			&audio.AudioID,
+			&audio.StoryID,
+			&audio.Order,
+			&audio.Locale,
+			&audio.MimeType,
+			&audio.FileSize,
+			&audio.DurationMs,
+			&audio.CreatedAt,
+			&audio.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		audio.URL = audioURL(audio.AudioID)
+		audio.CreatedAt = audio.CreatedAt.In(loc)
+		audio.UpdatedAt = audio.UpdatedAt.In(loc)
+		audioByPage[audio.Order] = append(audioByPage[audio.Order], audio)
+	}
+
+	return audioByPage, rows.Err()
+}
+
+// removeUnusedAudioFile deletes a narration file from the media storage unless
+// another page still uses the same content
+func removeUnusedAudioFile(key string) {
+	var count int
+	err := db.CreateCon().QueryRow("SELECT COUNT(*) FROM story_content_audio WHERE file_path = ?", key).Scan(&count)
+	if err != nil || count > 0 {
+		return
+	}
+
+	if err := storage.GetStorage().Delete(key); err != nil {
+		log.Printf("remove audio file %s: %v", key, err)
+	}
+}
@@ -0,0 +1,334 @@
+// Story Content Timing Model
+
+package models
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	TimingFormatJSON   = "json"
+	TimingFormatWebVTT = "vtt"
+)
+
+// ErrInvalidTiming is returned for timings that do not match the page text or the narration
+var ErrInvalidTiming = errors.New("invalid word timings")
+
+// WordTiming is one spoken word. CharStart and CharEnd are rune offsets of the
+// word in the page text, filled in when the timings are validated.
+type WordTiming struct {
+	Word      string `json:"word"`
+	StartMs   int    `json:"start_ms"`
+	EndMs     int    `json:"end_ms"`
+	CharStart int    `json:"char_start"`
+	CharEnd   int    `json:"char_end"`
+}
+
+// StoryContentTiming holds the word timings of one page in one locale. IsStale is
+// set when the page text was edited after the timings were uploaded.
+type StoryContentTiming struct {
+	StoryID   int          `json:"story_id"`
+	Order     int          `json:"order"`
+	Locale    string       `json:"locale"`
+	Format    string       `json:"format"`
+	Words     []WordTiming `json:"words"`
+	IsStale   bool         `json:"is_stale"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// textToken is a word found in page text with its rune offsets
+type textToken struct {
+	word       string
+	start, end int
+}
+
+// tokenizeText splits text into lowercase words. Letters and digits form words,
+// and a hyphen or apostrophe between two of them stays inside the word so that
+// reduplications such as "kupu-kupu" count as one word.
+func tokenizeText(text string) []textToken {
+	var tokens []textToken
+	runes := []rune(text)
+
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) {
+			if isWordRune(runes[i]) {
+				i++
+				continue
+			}
+			if (runes[i] == '-' || runes[i] == '\'' || runes[i] == '’') && i+1 < len(runes) && isWordRune(runes[i+1]) {
+				i++
+				continue
+			}
+			break
+		}
+
+		tokens = append(tokens, textToken{
+			word:  strings.ToLower(string(runes[start:i])),
+			start: start,
+			end:   i,
+		})
+	}
+
+	return tokens
+}
+
+// ValidateWordTimings checks that the timings cover every word of the page text in
+// order and fills in the character offsets of each word
+func ValidateWordTimings(pageText string, words []WordTiming) error {
+	tokens := tokenizeText(pageText)
+	if len(words) != len(tokens) {
+		return fmt.Errorf("%w: page has %d words but %d timings were given", ErrInvalidTiming, len(tokens), len(words))
+	}
+
+	previousEnd := 0
+	for i := range words {
+		word := &words[i]
+
+		wordTokens := tokenizeText(word.Word)
+		if len(wordTokens) != 1 || wordTokens[0].word != tokens[i].word {
+			return fmt.Errorf("%w: timing %d is %q but the page text has %q", ErrInvalidTiming, i+1, word.Word, tokens[i].word)
+		}
+		if word.StartMs < 0 || word.EndMs <= word.StartMs {
+			return fmt.Errorf("%w: timing %d (%q) must end after it starts", ErrInvalidTiming, i+1, word.Word)
+		}
+		if word.StartMs < previousEnd {
+			return fmt.Errorf("%w: timing %d (%q) overlaps the previous word", ErrInvalidTiming, i+1, word.Word)
+		}
+		previousEnd = word.EndMs
+
+		word.CharStart = tokens[i].start
+		word.CharEnd = tokens[i].end
+	}
+
+	return nil
+}
+
+// ParseWebVTTTimings reads a WebVTT file in which every cue holds a single word
+func ParseWebVTTTimings(vtt string) ([]WordTiming, error) {
+	var words []WordTiming
+
+	scanner := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(vtt, "\ufeff")))
+	if !scanner.Scan() || !strings.HasPrefix(strings.TrimSpace(scanner.Text()), "WEBVTT") {
+		return nil, fmt.Errorf("missing WEBVTT header")
+	}
+
+	var current *WordTiming
+	var text []string
+	flush := func() {
+		if current != nil {
+			current.Word = strings.TrimSpace(strings.Join(text, " "))
+			words = append(words, *current)
+		}
+		current = nil
+		text = nil
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			flush()
+		case strings.Contains(line, "-->"):
+			flush()
+			parts := strings.SplitN(line, "-->", 2)
+			start, err := parseVTTTimestamp(strings.TrimSpace(parts[0]))
+			if err != nil {
+				return nil, err
+			}
+			// Cue settings may follow the end timestamp
+			endFields := strings.Fields(parts[1])
+			if len(endFields) == 0 {
+				return nil, fmt.Errorf("missing cue end in %q", line)
+			}
+			end, err := parseVTTTimestamp(endFields[0])
+			if err != nil {
+				return nil, err
+			}
+			current = &WordTiming{StartMs: start, EndMs: end}
+		case current != nil:
+			text = append(text, line)
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
+
+// parseVTTTimestamp parses "mm:ss.ttt" or "hh:mm:ss.ttt" into milliseconds
+func parseVTTTimestamp(timestamp string) (int, error) {
+	invalid := fmt.Errorf("invalid timestamp %q", timestamp)
+
+	secondsPart, millisPart, ok := strings.Cut(timestamp, ".")
+	if !ok || len(millisPart) != 3 {
+		return 0, invalid
+	}
+	millis, err := strconv.Atoi(millisPart)
+	if err != nil {
+		return 0, invalid
+	}
+
+	total := 0
+	for _, field := range strings.Split(secondsPart, ":") {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, invalid
+		}
+		total = total*60 + value
+	}
+
+	return total*1000 + millis, nil
+}
+
+// SaveStoryContentTiming validates word timings against the text of one page in
+// one locale and stores them, replacing earlier timings
+func SaveStoryContentTiming(timing StoryContentTiming) (Response, error) {
+	var res Response
+
+	if !IsSupportedLocale(timing.Locale) {
+		return res, fmt.Errorf("%w: unsupported locale %q", ErrInvalidTiming, timing.Locale)
+	}
+
+	con := db.CreateCon()
+
+	// A page without text in the locale gives sql.ErrNoRows
+	var pageText string
+	err := con.QueryRow("SELECT content FROM story_content_translation WHERE story_id = ? AND `order` = ? AND locale = ?", timing.StoryID, timing.Order, timing.Locale).Scan(&pageText)
+	if err != nil {
+		return res, err
+	}
+
+	if err := ValidateWordTimings(pageText, timing.Words); err != nil {
+		return res, err
+	}
+
+	// The last word cannot end after the narration does
+	var durationMs int
+	err = con.QueryRow("SELECT duration_ms FROM story_content_audio WHERE story_id = ? AND `order` = ? AND locale = ?", timing.StoryID, timing.Order, timing.Locale).Scan(&durationMs)
+	if err != nil && err != sql.ErrNoRows {
+		return res, err
+	}
+	if durationMs > 0 && len(timing.Words) > 0 && timing.Words[len(timing.Words)-1].EndMs > durationMs {
+		return res, fmt.Errorf("%w: timings end at %dms but the narration is %dms long", ErrInvalidTiming, timing.Words[len(timing.Words)-1].EndMs, durationMs)
+	}
+
+	encoded, err := json.Marshal(timing.Words)
+	if err != nil {
+		return res, err
+	}
+
+	now := time.Now()
+	_, err = con.Exec(
+		"INSERT INTO story_content_timing (story_id, `order`, locale, format, words, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE format = VALUES(format), words = VALUES(words), updated_at = VALUES(updated_at)",
+		timing.StoryID, timing.Order, timing.Locale, timing.Format, encoded, now, now,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+	timing.UpdatedAt = now.In(loc)
+
+	res.Data = map[string]interface{}{
+		"timing": timing,
+	}
+
+	return res, nil
+}
+
+// DeleteStoryContentTiming removes the timings of one page in one locale
+func DeleteStoryContentTiming(storyID, order int, locale string) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	result, err := con.Exec("DELETE FROM story_content_timing WHERE story_id = ? AND `order` = ? AND locale = ?", storyID, order, locale)
+	if err != nil {
+		return res, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"rowsAffected": rowsAffected,
+	}
+
+	return res, nil
+}
+
+// getStoryTimingsByPage returns the word timings of every page of a story keyed by page order
+func getStoryTimingsByPage(ex dbExecutor, storyID int) (map[int][]StoryContentTiming, error) {
+	timingsByPage := make(map[int][]StoryContentTiming)
+
+	rows, err := ex.Query("SELECT story_id, `order`, locale, format, words, updated_at FROM story_content_timing WHERE story_id = ? ORDER BY `order`, locale", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var timing StoryContentTiming
+		var words []byte
+		if err := rows.Scan(&timing.StoryID, &timing.Order, &timing.Locale, &timing.Format, &words, &timing.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(words, &timing.Words); err != nil {
+			return nil, err
+		}
+		timing.UpdatedAt = timing.UpdatedAt.In(loc)
+		timingsByPage[timing.Order] = append(timingsByPage[timing.Order], timing)
+	}
+
+	return timingsByPage, rows.Err()
+}
+
+// pageTimings picks the timings of a page in the given locale and checks them
+// against the text currently shown on the page
+func pageTimings(timings []StoryContentTiming, locale, pageText string) []StoryContentTiming {
+	var matching []StoryContentTiming
+	for _, timing := range timings {
+		if timing.Locale != locale {
+			continue
+		}
+		words := append([]WordTiming(nil), timing.Words...)
+		timing.IsStale = ValidateWordTimings(pageText, words) != nil
+		matching = append(matching, timing)
+	}
+	return matching
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTokenizeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want []textToken
+	}{
+		{"", nil},
+		{"Kupu-kupu terbang.", []textToken{{"kupu-kupu", 0, 9}, {"terbang", 10, 17}}},
+		{"Ia berkata, \"Ma'af!\"", []textToken{{"ia", 0, 2}, {"berkata", 3, 10}, {"ma'af", 13, 18}}},
+		{"Rumah -- 2 lantai", []textToken{{"rumah", 0, 5}, {"2", 9, 10}, {"lantai", 11, 17}}},
+		{"Dewi Sri’s padi", []textToken{{"dewi", 0, 4}, {"sri’s", 5, 10}, {"padi", 11, 15}}},
+		{"ujung-", []textToken{{"ujung", 0, 5}}},
+	}
+
+	for _, tt := range tests {
+		got := tokenizeText(tt.text)
+		if len(got) != len(tt.want) {
+			t.Fatalf("tokenizeText(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("tokenizeText(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestValidateWordTimings(t *testing.T) {
+	const page = "Si Kancil cerdik."
+
+	tests := []struct {
+		name  string
+		words []WordTiming
+		valid bool
+	}{
+		{"matches", []WordTiming{{Word: "Si", StartMs: 0, EndMs: 200}, {Word: "kancil", StartMs: 200, EndMs: 600}, {Word: "cerdik", StartMs: 650, EndMs: 1000}}, true},
+		{"too few words", []WordTiming{{Word: "Si", StartMs: 0, EndMs: 200}}, false},
+		{"wrong word", []WordTiming{{Word: "Si", StartMs: 0, EndMs: 200}, {Word: "buaya", StartMs: 200, EndMs: 600}, {Word: "cerdik", StartMs: 650, EndMs: 1000}}, false},
+		{"ends before it starts", []WordTiming{{Word: "Si", StartMs: 0, EndMs: 200}, {Word: "kancil", StartMs: 600, EndMs: 600}, {Word: "cerdik", StartMs: 650, EndMs: 1000}}, false},
+		{"overlaps", []WordTiming{{Word: "Si", StartMs: 0, EndMs: 300}, {Word: "kancil", StartMs: 200, EndMs: 600}, {Word: "cerdik", StartMs: 650, EndMs: 1000}}, false},
+		{"negative start", []WordTiming{{Word: "Si", StartMs: -5, EndMs: 200}, {Word: "kancil", StartMs: 200, EndMs: 600}, {Word: "cerdik", StartMs: 650, EndMs: 1000}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWordTimings(page, tt.words)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("ValidateWordTimings() = %v, want nil", err)
+				}
+				if tt.words[1].CharStart != 3 || tt.words[1].CharEnd != 9 {
+					t.Fatalf("offsets of %q = %d-%d, want 3-9", tt.words[1].Word, tt.words[1].CharStart, tt.words[1].CharEnd)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidTiming) {
+				t.Fatalf("ValidateWordTimings() = %v, want ErrInvalidTiming", err)
+			}
+		})
+	}
+}
+
+func TestParseVTTTimestamp(t *testing.T) {
+	tests := []struct {
+		timestamp string
+		want      int
+		valid     bool
+	}{
+		{"00:01.250", 1250, true},
+		{"01:02.003", 62003, true},
+		{"01:00:00.000", 3600000, true},
+		{"00:01", 0, false},
+		{"00:01.25", 0, false},
+		{"aa:01.250", 0, false},
+		{"00:01.2x0", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := parseVTTTimestamp(tt.timestamp)
+		if (err == nil) != tt.valid || got != tt.want {
+			t.Errorf("parseVTTTimestamp(%q) = %d, %v; want %d, valid %v", tt.timestamp, got, err, tt.want, tt.valid)
+		}
+	}
+}
+
+func TestParseWebVTTTimings(t *testing.T) {
+	tests := []struct {
+		name  string
+		vtt   string
+		want  []WordTiming
+		valid bool
+	}{
+		{
+			name: "cues with identifiers and settings",
+			vtt: "\ufeffWEBVTT - read along\n\n" +
+				"1\n00:00.000 --> 00:00.400 align:start\nSi\n\n" +
+				"2\n00:00.400 --> 00:01.000\nKancil\n",
+			want:  []WordTiming{{Word: "Si", StartMs: 0, EndMs: 400}, {Word: "Kancil", StartMs: 400, EndMs: 1000}},
+			valid: true,
+		},
+		{
+			name:  "cues without blank line between them",
+			vtt:   "WEBVTT\n00:00.000 --> 00:00.400\nSi\n00:00.400 --> 00:01.000\nKancil",
+			want:  []WordTiming{{Word: "Si", StartMs: 0, EndMs: 400}, {Word: "Kancil", StartMs: 400, EndMs: 1000}},
+			valid: true,
+		},
+		{
+			name:  "header only",
+			vtt:   "WEBVTT\n",
+			valid: true,
+		},
+		{name: "missing header", vtt: "00:00.000 --> 00:00.400\nSi\n"},
+		{name: "bad timestamp", vtt: "WEBVTT\n\n00:00 --> 00:00.400\nSi\n"},
+		{name: "missing cue end", vtt: "WEBVTT\n\n00:00.000 -->\nSi\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWebVTTTimings(tt.vtt)
+			if !tt.valid {
+				if err == nil {
+					t.Fatalf("ParseWebVTTTimings() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWebVTTTimings() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseWebVTTTimings() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ParseWebVTTTimings() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Story Like Model
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"kisahloka_be/db"
+	"time"
+)
+
+// isStoryLiked reports whether a user (userID) or device (uid) likes a story
+func isStoryLiked(ex dbExecutor, storyID int, userID *int, uid *string) (bool, error) {
+	condition, readerArg := readerCondition("", userID, uid)
+
+	var likeID int
+	err := ex.QueryRow("SELECT like_id FROM story_like WHERE story_id = ? AND "+condition, storyID, readerArg).Scan(&likeID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SetStoryLike likes (liked) or unlikes a published story for a user (userID)
+// or device (uid). Liking a story twice or unliking one that is not liked
+// changes nothing, so clients can safely retry.
+func SetStoryLike(storyID int, userID *int, uid *string, liked bool) (Response, error) {
+	var res Response
+
+	if userID == nil && uid == nil {
+		return res, fmt.Errorf("user_id or uid is required")
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	// Lock the story row so concurrent likes keep like_count exact
+	var likeCount int
+	err = tx.QueryRow("SELECT s.like_count FROM story s WHERE s.story_id = ? AND "+publishedStoryCondition+" FOR UPDATE", storyID).Scan(&likeCount)
+	if err != nil {
+		return res, err
+	}
+
+	wasLiked, err := isStoryLiked(tx, storyID, userID, uid)
+	if err != nil {
+		return res, err
+	}
+
+	switch {
+	case liked && !wasLiked:
+		// Signed-in likes are keyed by user only so they follow the user across devices
+		var userIDValue, uidValue interface{}
+		if userID != nil {
+			userIDValue = *userID
+		} else {
+			uidValue = *uid
+		}
+		if _, err := tx.Exec("INSERT INTO story_like (story_id, user_id, uid, created_at) VALUES (?, ?, ?, ?)", storyID, userIDValue, uidValue, time.Now()); err != nil {
+			return res, err
+		}
+		likeCount++
+	case !liked && wasLiked:
+		condition, readerArg := readerCondition("", userID, uid)
+		if _, err := tx.Exec("DELETE FROM story_like WHERE story_id = ? AND "+condition, storyID, readerArg); err != nil {
+			return res, err
+		}
+		likeCount--
+	}
+
+	if liked != wasLiked {
+		if _, err := tx.Exec("UPDATE story SET like_count = ? WHERE story_id = ?", likeCount, storyID); err != nil {
+			return res, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id":   storyID,
+		"is_liked":   liked,
+		"like_count": likeCount,
+	}
+
+	return res, nil
+}
@@ -0,0 +1,345 @@
+// Story Review Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// ErrNotReviewOwner is returned when a user changes a review they did not write
+var ErrNotReviewOwner = errors.New("review belongs to another user")
+
+// ErrInvalidRating is returned when a rating is outside MinRating..MaxRating
+var ErrInvalidRating = errors.New("invalid rating")
+
+// ErrReviewUserNotFound is returned when a review is saved for an unknown user
+var ErrReviewUserNotFound = errors.New("user not found")
+
+// RatingSummary aggregates the star ratings of a story
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// StoryReview is a user's rating of a story with an optional written review
+type StoryReview struct {
+	ReviewID  int       `json:"review_id"`
+	StoryID   int       `json:"story_id"`
+	UserID    int       `json:"user_id"`
+	UserName  string    `json:"user_name"`
+	Rating    int       `json:"rating"`
+	Review    string    `json:"review"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// reviewSorts maps the sort query param of the review listing to its ORDER BY
+var reviewSorts = map[string]string{
+	"newest":  "r.updated_at DESC, r.review_id DESC",
+	"oldest":  "r.updated_at, r.review_id",
+	"highest": "r.rating DESC, r.updated_at DESC",
+	"lowest":  "r.rating, r.updated_at DESC",
+}
+
+// IsValidReviewSort reports whether sort is a known review listing order
+func IsValidReviewSort(sort string) bool {
+	_, ok := reviewSorts[sort]
+	return ok
+}
+
+func validateRating(rating int) error {
+	if rating < MinRating || rating > MaxRating {
+		return fmt.Errorf("%w: rating must be between %d and %d", ErrInvalidRating, MinRating, MaxRating)
+	}
+	return nil
+}
+
+// checkReviewableStory returns sql.ErrNoRows unless the story is published
+func checkReviewableStory(ex dbExecutor, storyID int) error {
+	var exists int
+	if err := ex.QueryRow("SELECT COUNT(*) FROM story s WHERE s.story_id = ? AND "+publishedStoryCondition, storyID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const storyReviewColumns = "r.review_id, r.story_id, r.user_id, COALESCE(u.name, ''), r.rating, COALESCE(r.review, ''), r.created_at, r.updated_at"
+
+func scanStoryReview(row interface{ Scan(...interface{}) error }, loc *time.Location) (StoryReview, error) {
+	var review StoryReview
+	err := row.Scan(&review.ReviewID, &review.StoryID, &review.UserID, &review.UserName, &review.Rating, &review.Review, &review.CreatedAt, &review.UpdatedAt)
+	if err != nil {
+		return review, err
+	}
+
+	// Convert time fields to UTC+8 (Asia/Shanghai) before including them in the response
+	review.CreatedAt = review.CreatedAt.In(loc)
+	review.UpdatedAt = review.UpdatedAt.In(loc)
+
+	return review, nil
+}
+
+// getRatingSummaries aggregates the ratings of the given stories. Stories
+// without ratings are missing from the map.
+func getRatingSummaries(ex dbExecutor, storyIDs []int) (map[int]RatingSummary, error) {
+	summaries := make(map[int]RatingSummary)
+	if len(storyIDs) == 0 {
+		return summaries, nil
+	}
+
+	placeholders := make([]string, len(storyIDs))
+	args := make([]interface{}, len(storyIDs))
+	for i, storyID := range storyIDs {
+		placeholders[i] = "?"
+		args[i] = storyID
+	}
+
+	rows, err := ex.Query("SELECT story_id, AVG(rating), COUNT(*) FROM story_review WHERE story_id IN ("+strings.Join(placeholders, ", ")+") GROUP BY story_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var storyID int
+		var summary RatingSummary
+		if err := rows.Scan(&storyID, &summary.Average, &summary.Count); err != nil {
+			return nil, err
+		}
+		summaries[storyID] = summary
+	}
+
+	return summaries, rows.Err()
+}
+
+// attachPreviewRatings fills in the rating summary of story previews
+func attachPreviewRatings(ex dbExecutor, stories []StoryPreview) error {
+	storyIDs := make([]int, len(stories))
+	for i, story := range stories {
+		storyIDs[i] = story.StoryID
+	}
+
+	summaries, err := getRatingSummaries(ex, storyIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range stories {
+		stories[i].Rating = summaries[stories[i].StoryID]
+	}
+
+	return nil
+}
+
+// getUserReview returns the review a user wrote for a story, or nil
+func getUserReview(ex dbExecutor, storyID, userID int) (*StoryReview, error) {
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := scanStoryReview(ex.QueryRow("SELECT "+storyReviewColumns+" FROM story_review r LEFT JOIN user u ON r.user_id = u.user_id WHERE r.story_id = ? AND r.user_id = ?", storyID, userID), loc)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// GetStoryReviews lists the written reviews of a story, with the rating summary
+// of all ratings including those without text
+func GetStoryReviews(storyID, page, pageSize int, sort string) (Response, error) {
+	var res Response
+	var meta Meta
+	reviews := []StoryReview{}
+
+	con := db.CreateCon()
+
+	if err := checkReviewableStory(con, storyID); err != nil {
+		return res, err
+	}
+
+	summaries, err := getRatingSummaries(con, []int{storyID})
+	if err != nil {
+		return res, err
+	}
+
+	// Count how many ratings each star value got
+	distribution := make(map[int]int)
+	for rating := MinRating; rating <= MaxRating; rating++ {
+		distribution[rating] = 0
+	}
+	rows, err := con.Query("SELECT rating, COUNT(*) FROM story_review WHERE story_id = ? GROUP BY rating", storyID)
+	if err != nil {
+		return res, err
+	}
+	for rows.Next() {
+		var rating, count int
+		if err := rows.Scan(&rating, &count); err != nil {
+			rows.Close()
+			return res, err
+		}
+		distribution[rating] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	const whereClause = " WHERE r.story_id = ? AND r.review IS NOT NULL AND r.review <> ''"
+
+	var totalItems int
+	if err := con.QueryRow("SELECT COUNT(*) FROM story_review r"+whereClause, storyID).Scan(&totalItems); err != nil {
+		return res, err
+	}
+
+	meta.Limit = pageSize
+	meta.Page = page
+	meta.TotalPages = calculateTotalPages(totalItems, pageSize)
+	meta.TotalItems = totalItems
+
+	if totalItems > 0 {
+		// Check if the requested page is greater than the total number of pages
+		if page > meta.TotalPages {
+			return res, fmt.Errorf("requested page (%d) exceeds total number of pages (%d)", page, meta.TotalPages)
+		}
+
+		// Load the UTC+8 time zone
+		loc, err := time.LoadLocation("Asia/Shanghai")
+		if err != nil {
+			return res, err
+		}
+
+		// Calculate the offset based on the page number and page size
+		offset := (page - 1) * pageSize
+
+		rows, err := con.Query("SELECT "+storyReviewColumns+" FROM story_review r LEFT JOIN user u ON r.user_id = u.user_id"+whereClause+" ORDER BY "+reviewSorts[sort]+" LIMIT ? OFFSET ?", storyID, pageSize, offset)
+		if err != nil {
+			return res, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			review, err := scanStoryReview(rows, loc)
+			if err != nil {
+				return res, err
+			}
+			reviews = append(reviews, review)
+		}
+
+		if err := rows.Err(); err != nil {
+			return res, err
+		}
+	}
+
+	res.Data = map[string]interface{}{
+		"rating":       summaries[storyID],
+		"distribution": distribution,
+		"reviews":      reviews,
+		"meta":         meta,
+	}
+
+	return res, nil
+}
+
+// SaveStoryReview rates a story for a user, replacing the user's earlier rating
+// and review of it. An empty review keeps only the stars.
+func SaveStoryReview(storyID, userID, rating int, review string) (Response, error) {
+	var res Response
+
+	if err := validateRating(rating); err != nil {
+		return res, err
+	}
+
+	con := db.CreateCon()
+
+	if err := checkReviewableStory(con, storyID); err != nil {
+		return res, err
+	}
+
+	var userExists int
+	if err := con.QueryRow("SELECT COUNT(*) FROM user WHERE user_id = ?", userID).Scan(&userExists); err != nil {
+		return res, err
+	}
+	if userExists == 0 {
+		return res, ErrReviewUserNotFound
+	}
+
+	var reviewText interface{}
+	if review = strings.TrimSpace(review); review != "" {
+		reviewText = review
+	}
+
+	now := time.Now()
+	_, err := con.Exec("INSERT INTO story_review (story_id, user_id, rating, review, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE rating = VALUES(rating), review = VALUES(review), updated_at = VALUES(updated_at)",
+		storyID, userID, rating, reviewText, now, now,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	saved, err := getUserReview(con, storyID, userID)
+	if err != nil {
+		return res, err
+	}
+
+	summaries, err := getRatingSummaries(con, []int{storyID})
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"review": saved,
+		"rating": summaries[storyID],
+	}
+
+	return res, nil
+}
+
+// DeleteStoryReview removes a review, which only its author may do
+func DeleteStoryReview(reviewID, userID int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	var ownerID int
+	if err := con.QueryRow("SELECT user_id FROM story_review WHERE review_id = ?", reviewID).Scan(&ownerID); err != nil {
+		return res, err
+	}
+	if ownerID != userID {
+		return res, ErrNotReviewOwner
+	}
+
+	result, err := con.Exec("DELETE FROM story_review WHERE review_id = ?", reviewID)
+	if err != nil {
+		return res, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"rows_affected": rowsAffected,
+	}
+
+	return res, nil
+}
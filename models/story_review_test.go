@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsValidReviewSort(t *testing.T) {
+	tests := []struct {
+		sort  string
+		valid bool
+	}{
+		{"newest", true},
+		{"oldest", true},
+		{"highest", true},
+		{"lowest", true},
+		{"", false},
+		{"rating", false},
+		{"NEWEST", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			if got := IsValidReviewSort(tt.sort); got != tt.valid {
+				t.Errorf("IsValidReviewSort(%q) = %v, want %v", tt.sort, got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestReviewSorts(t *testing.T) {
+	tests := map[string]string{
+		"newest":  "r.updated_at DESC, r.review_id DESC",
+		"oldest":  "r.updated_at, r.review_id",
+		"highest": "r.rating DESC, r.updated_at DESC",
+		"lowest":  "r.rating, r.updated_at DESC",
+	}
+
+	for sort, want := range tests {
+		if got := reviewSorts[sort]; got != want {
+			t.Errorf("reviewSorts[%q] = %q, want %q", sort, got, want)
+		}
+	}
+}
+
+func TestValidateRating(t *testing.T) {
+	tests := []struct {
+		rating int
+		valid  bool
+	}{
+		{MinRating, true},
+		{3, true},
+		{MaxRating, true},
+		{MinRating - 1, false},
+		{MaxRating + 1, false},
+		{-5, false},
+	}
+
+	for _, tt := range tests {
+		err := validateRating(tt.rating)
+		if tt.valid && err != nil {
+			t.Errorf("validateRating(%d) = %v, want nil", tt.rating, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidRating) {
+			t.Errorf("validateRating(%d) = %v, want ErrInvalidRating", tt.rating, err)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+// Story Revision Model
+
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"kisahloka_be/db"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type StoryRevision struct {
+	RevisionID     int            `json:"revision_id"`
+	StoryID        int            `json:"story_id"`
+	RevisionNumber int            `json:"revision_number"`
+	Note           string         `json:"note"`
+	Snapshot       *StorySnapshot `json:"snapshot,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// StorySnapshot is the editable state of a story and its pages at one revision
+type StorySnapshot struct {
+	TypeID         int                  `json:"type_id"`
+	OriginID       int                  `json:"origin_id"`
+	Title          string               `json:"title"`
+	TotalContent   int                  `json:"total_content"`
+	ReleasedDate   time.Time            `json:"released_date"`
+	Synopsis       string               `json:"synopsis"`
+	MoralIndo      string               `json:"moral_indo"`
+	MoralEng       string               `json:"moral_eng"`
+	ThumbnailImage string               `json:"thumbnail_image"`
+	IsHighlighted  int                  `json:"is_highligthed"`
+	IsFavorited    int                  `json:"is_favorited"`
+	Status         string               `json:"status"`
+	StoryContent   []StoryContentOnList `json:"story_content"`
+	Translations   []StoryTranslation   `json:"translations"`
+}
+
+type RevisionChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// GetStoryRevisions lists the revisions of a story, newest first, without their snapshots
+func GetStoryRevisions(storyID int) (Response, error) {
+	var res Response
+	revisions := make([]StoryRevision, 0)
+
+	con := db.CreateCon()
+
+	rows, err := con.Query("SELECT revision_id, story_id, revision_number, note, created_at FROM story_revision WHERE story_id = ? ORDER BY revision_number DESC", storyID)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	for rows.Next() {
+		var revision StoryRevision
+		err := rows.Scan(
+			&revision.RevisionID,
+			&revision.StoryID,
+			&revision.RevisionNumber,
+			&revision.Note,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return res, err
+		}
+		revision.CreatedAt = revision.CreatedAt.In(loc)
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"revisions": revisions,
+	}
+
+	return res, nil
+}
+
+// GetStoryRevisionDiff returns the field-level changes between two revisions of a story
+func GetStoryRevisionDiff(storyID, fromRevisionID, toRevisionID int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	from, err := getStoryRevision(con, storyID, fromRevisionID)
+	if err != nil {
+		return res, err
+	}
+
+	to, err := getStoryRevision(con, storyID, toRevisionID)
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"from_revision": from.RevisionNumber,
+		"to_revision":   to.RevisionNumber,
+		"changes":       diffStorySnapshots(*from.Snapshot, *to.Snapshot),
+	}
+
+	return res, nil
+}
+
+// RestoreStoryRevision writes a previous revision back to the story and its pages.
+// The story keeps its current status; the restore itself is recorded as a new revision.
+func RestoreStoryRevision(storyID, revisionID int) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	revision, err := getStoryRevision(con, storyID, revisionID)
+	if err != nil {
+		return res, err
+	}
+	snapshot := revision.Snapshot
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	updatedAt := time.Now()
+
+	_, err = tx.Exec(
+		"UPDATE story SET type_id = ?, origin_id = ?, title = ?, released_date = ?, synopsis = ?, moral_indo = ?, moral_eng = ?, thumbnail_image = ?, is_highligthed = ?, is_favorited = ?, updated_at = ? WHERE story_id = ?",
+		snapshot.TypeID,
+		snapshot.OriginID,
+		snapshot.Title,
+		snapshot.ReleasedDate,
+		snapshot.Synopsis,
+		snapshot.MoralIndo,
+		snapshot.MoralEng,
+		snapshot.ThumbnailImage,
+		snapshot.IsHighlighted,
+		snapshot.IsFavorited,
+		updatedAt,
+		storyID,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	if err := syncStoryTitleTranslation(tx, storyID); err != nil {
+		return res, err
+	}
+
+	if err := replaceStoryContent(tx, storyID, snapshot.StoryContent); err != nil {
+		return res, err
+	}
+
+	// Revisions recorded before translations existed leave them untouched
+	if snapshot.Translations != nil {
+		if err := restoreExtraStoryTranslations(tx, storyID, snapshot.Translations); err != nil {
+			return res, err
+		}
+	}
+
+	newRevisionID, err := recordStoryRevision(tx, storyID, fmt.Sprintf("Restored revision #%d", revision.RevisionNumber))
+	if err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"restored_revision": revision.RevisionNumber,
+		"revision_id":       newRevisionID,
+		"updated_at":        updatedAt.In(loc),
+	}
+
+	return res, nil
+}
+
+func getStoryRevision(ex dbExecutor, storyID, revisionID int) (StoryRevision, error) {
+	var revision StoryRevision
+	var snapshot []byte
+
+	err := ex.QueryRow("SELECT revision_id, story_id, revision_number, note, snapshot, created_at FROM story_revision WHERE story_id = ? AND revision_id = ?", storyID, revisionID).Scan(
+		&revision.RevisionID,
+		&revision.StoryID,
+		&revision.RevisionNumber,
+		&revision.Note,
+		&snapshot,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return revision, err
+	}
+
+	revision.Snapshot = &StorySnapshot{}
+	if err := json.Unmarshal(snapshot, revision.Snapshot); err != nil {
+		return revision, err
+	}
+
+	return revision, nil
+}
+
+// loadStorySnapshot reads the current state of a story and its pages
+func loadStorySnapshot(ex dbExecutor, storyID int) (StorySnapshot, error) {
+	var snapshot StorySnapshot
+
+	err := ex.QueryRow("SELECT type_id, origin_id, title, total_content, released_date, synopsis, COALESCE(moral_indo, ''), COALESCE(moral_eng, ''), thumbnail_image, is_highligthed, is_favorited, status FROM story WHERE story_id = ?", storyID).Scan(
+		&snapshot.TypeID,
+		&snapshot.OriginID,
+		&snapshot.Title,
+		&snapshot.TotalContent,
+		&snapshot.ReleasedDate,
+		&snapshot.Synopsis,
+		&snapshot.MoralIndo,
+		&snapshot.MoralEng,
+		&snapshot.ThumbnailImage,
+		&snapshot.IsHighlighted,
+		&snapshot.IsFavorited,
+		&snapshot.Status,
+	)
+	if err != nil {
+		return snapshot, err
+	}
+
+	rows, err := ex.Query("SELECT `order`, image, content_indo, content_eng FROM story_content WHERE story_id = ? ORDER BY `order`", storyID)
+	if err != nil {
+		return snapshot, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c StoryContentOnList
+		if err := rows.Scan(&c.Order, &c.Image, &c.ContentIndo, &c.ContentEng); err != nil {
+			return snapshot, err
+		}
+		snapshot.StoryContent = append(snapshot.StoryContent, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return snapshot, err
+	}
+
+	snapshot.Translations, err = loadExtraStoryTranslations(ex, storyID)
+	return snapshot, err
+}
+
+// lockStoryRevisions locks the story row until the end of the transaction, so
+// concurrent edits of the same story number their revisions one after another
+func lockStoryRevisions(ex dbExecutor, storyID int) error {
+	var id int
+	return ex.QueryRow("SELECT story_id FROM story WHERE story_id = ? FOR UPDATE", storyID).Scan(&id)
+}
+
+// recordStoryRevision stores the current state of a story as its next revision
+func recordStoryRevision(ex dbExecutor, storyID int, note string) (int64, error) {
+	if err := lockStoryRevisions(ex, storyID); err != nil {
+		return 0, err
+	}
+
+	snapshot, err := loadStorySnapshot(ex, storyID)
+	if err != nil {
+		return 0, err
+	}
+
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	// A locking read sees revisions committed while waiting for the lock
+	var lastNumber sql.NullInt64
+	err = ex.QueryRow("SELECT MAX(revision_number) FROM story_revision WHERE story_id = ? FOR UPDATE", storyID).Scan(&lastNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := ex.Exec(
+		"INSERT INTO story_revision (story_id, revision_number, snapshot, note, created_at) VALUES (?, ?, ?, ?, ?)",
+		storyID, lastNumber.Int64+1, encoded, note, time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// ensureStoryRevisionBaseline records the current state of a story that has no
+// revisions yet, so the first edit of a pre-existing story can still be rolled back
+func ensureStoryRevisionBaseline(ex dbExecutor, storyID int) error {
+	if err := lockStoryRevisions(ex, storyID); err != nil {
+		return err
+	}
+
+	var count int
+	err := ex.QueryRow("SELECT COUNT(*) FROM story_revision WHERE story_id = ? FOR UPDATE", storyID).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	_, err = recordStoryRevision(ex, storyID, "Initial revision")
+	return err
+}
+
+// diffStorySnapshots compares two snapshots field by field. Pages are compared
+// by their order, so an added or removed page shows up with a nil side.
+func diffStorySnapshots(from, to StorySnapshot) []RevisionChange {
+	changes := make([]RevisionChange, 0)
+
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"type_id", from.TypeID, to.TypeID},
+		{"origin_id", from.OriginID, to.OriginID},
+		{"title", from.Title, to.Title},
+		{"total_content", from.TotalContent, to.TotalContent},
+		{"released_date", from.ReleasedDate.UTC(), to.ReleasedDate.UTC()},
+		{"synopsis", from.Synopsis, to.Synopsis},
+		{"moral_indo", from.MoralIndo, to.MoralIndo},
+		{"moral_eng", from.MoralEng, to.MoralEng},
+		{"thumbnail_image", from.ThumbnailImage, to.ThumbnailImage},
+		{"is_highligthed", from.IsHighlighted, to.IsHighlighted},
+		{"is_favorited", from.IsFavorited, to.IsFavorited},
+		{"status", from.Status, to.Status},
+	}
+	for _, field := range fields {
+		if !reflect.DeepEqual(field.from, field.to) {
+			changes = append(changes, RevisionChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+
+	fromPages := make(map[int]StoryContentOnList)
+	for _, page := range from.StoryContent {
+		fromPages[page.Order] = page
+	}
+	toPages := make(map[int]StoryContentOnList)
+	for _, page := range to.StoryContent {
+		toPages[page.Order] = page
+	}
+
+	orders := make([]int, 0)
+	for _, page := range from.StoryContent {
+		orders = append(orders, page.Order)
+	}
+	for _, page := range to.StoryContent {
+		if _, ok := fromPages[page.Order]; !ok {
+			orders = append(orders, page.Order)
+		}
+	}
+
+	for _, order := range orders {
+		fromPage, inFrom := fromPages[order]
+		toPage, inTo := toPages[order]
+		field := fmt.Sprintf("story_content[%d]", order)
+
+		switch {
+		case !inTo:
+			changes = append(changes, RevisionChange{Field: field, From: fromPage, To: nil})
+		case !inFrom:
+			changes = append(changes, RevisionChange{Field: field, From: nil, To: toPage})
+		default:
+			if fromPage.Image != toPage.Image {
+				changes = append(changes, RevisionChange{Field: field + ".image", From: fromPage.Image, To: toPage.Image})
+			}
+			if fromPage.ContentIndo != toPage.ContentIndo {
+				changes = append(changes, RevisionChange{Field: field + ".content_indo", From: fromPage.ContentIndo, To: toPage.ContentIndo})
+			}
+			if fromPage.ContentEng != toPage.ContentEng {
+				changes = append(changes, RevisionChange{Field: field + ".content_eng", From: fromPage.ContentEng, To: toPage.ContentEng})
+			}
+		}
+	}
+
+	changes = append(changes, diffStoryTranslations(from.Translations, to.Translations)...)
+
+	return changes
+}
+
+// diffStoryTranslations compares the translations of two snapshots by locale
+func diffStoryTranslations(from, to []StoryTranslation) []RevisionChange {
+	changes := make([]RevisionChange, 0)
+
+	flatten := func(translations []StoryTranslation) map[string]string {
+		fields := make(map[string]string)
+		for _, translation := range translations {
+			prefix := fmt.Sprintf("translations[%s]", translation.Locale)
+			fields[prefix+".title"] = translation.Title
+			fields[prefix+".synopsis"] = translation.Synopsis
+			for _, page := range translation.StoryContent {
+				fields[fmt.Sprintf("%s.story_content[%d]", prefix, page.Order)] = page.Content
+			}
+		}
+		return fields
+	}
+	fromFields := flatten(from)
+	toFields := flatten(to)
+
+	keys := make([]string, 0, len(fromFields)+len(toFields))
+	for key := range fromFields {
+		keys = append(keys, key)
+	}
+	for key := range toFields {
+		if _, ok := fromFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fromValue, inFrom := fromFields[key]
+		toValue, inTo := toFields[key]
+		switch {
+		case !inTo:
+			changes = append(changes, RevisionChange{Field: key, From: fromValue, To: nil})
+		case !inFrom:
+			changes = append(changes, RevisionChange{Field: key, From: nil, To: toValue})
+		case fromValue != toValue:
+			changes = append(changes, RevisionChange{Field: key, From: fromValue, To: toValue})
+		}
+	}
+
+	return changes
+}
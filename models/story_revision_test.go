@@ -0,0 +1,54 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffStorySnapshots(t *testing.T) {
+	released := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	from := StorySnapshot{
+		Title:        "Malin Kundang",
+		ReleasedDate: released,
+		Status:       StoryStatusDraft,
+		StoryContent: []StoryContentOnList{
+			{Order: 1, Image: "1.jpg", ContentIndo: "Dahulu kala", ContentEng: "Once upon a time"},
+			{Order: 2, ContentIndo: "Halaman dua"},
+		},
+		Translations: []StoryTranslation{
+			{Locale: "jv", Title: "Malin", StoryContent: []StoryContentTranslation{{Order: 1, Content: "Biyen"}}},
+		},
+	}
+	to := StorySnapshot{
+		Title: "Malin Kundang",
+		// The same instant in another zone is not a change
+		ReleasedDate: released.In(time.FixedZone("WITA", 8*3600)),
+		Status:       StoryStatusPublished,
+		StoryContent: []StoryContentOnList{
+			{Order: 1, Image: "1b.jpg", ContentIndo: "Dahulu kala", ContentEng: "Once upon a time"},
+			{Order: 3, ContentIndo: "Halaman tiga"},
+		},
+		Translations: []StoryTranslation{
+			{Locale: "jv", Title: "Malin Kundang"},
+		},
+	}
+
+	want := []RevisionChange{
+		{Field: "status", From: StoryStatusDraft, To: StoryStatusPublished},
+		{Field: "story_content[1].image", From: "1.jpg", To: "1b.jpg"},
+		{Field: "story_content[2]", From: from.StoryContent[1], To: nil},
+		{Field: "story_content[3]", From: nil, To: to.StoryContent[1]},
+		{Field: "translations[jv].story_content[1]", From: "Biyen", To: nil},
+		{Field: "translations[jv].title", From: "Malin", To: "Malin Kundang"},
+	}
+
+	if got := diffStorySnapshots(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffStorySnapshots() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := diffStorySnapshots(from, from); len(got) != 0 {
+		t.Errorf("diffStorySnapshots() of the same snapshot = %+v, want no changes", got)
+	}
+}
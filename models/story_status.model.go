@@ -0,0 +1,224 @@
+// Story Status Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"time"
+)
+
+const (
+	StoryStatusDraft     = "draft"
+	StoryStatusInReview  = "in_review"
+	StoryStatusScheduled = "scheduled"
+	StoryStatusPublished = "published"
+	StoryStatusArchived  = "archived"
+)
+
+// publishedStoryCondition limits a query aliasing story as "s" to the stories
+// readers are allowed to see. released_date is stored in UTC by the driver.
+const publishedStoryCondition = "s.status = '" + StoryStatusPublished + "' AND s.released_date <= UTC_TIMESTAMP()"
+
+// ErrInvalidStoryStatus is returned for a status change that is not allowed
+var ErrInvalidStoryStatus = errors.New("invalid story status")
+
+// IsValidStoryStatus reports whether status is one of the known story statuses
+func IsValidStoryStatus(status string) bool {
+	switch status {
+	case StoryStatusDraft, StoryStatusInReview, StoryStatusScheduled, StoryStatusPublished, StoryStatusArchived:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateStoryStatus checks a status and, for scheduled stories, that the
+// release date is still in the future
+func validateStoryStatus(status string, releasedDate *time.Time) error {
+	if !IsValidStoryStatus(status) {
+		return fmt.Errorf("%w %q", ErrInvalidStoryStatus, status)
+	}
+	if status == StoryStatusScheduled && (releasedDate == nil || !releasedDate.After(time.Now())) {
+		return fmt.Errorf("%w: scheduled stories need a future released_date", ErrInvalidStoryStatus)
+	}
+	if status == StoryStatusPublished && releasedDate != nil && releasedDate.After(time.Now()) {
+		return fmt.Errorf("%w: use scheduled for a future released_date", ErrInvalidStoryStatus)
+	}
+	return nil
+}
+
+// UpdateStoryStatus moves a story to another status. A scheduled story needs a
+// release date, which is stored as the new released_date. A published story
+// must already be released, so its followers can be notified right away.
+func UpdateStoryStatus(storyID int, status string, releasedDate *time.Time) (Response, error) {
+	var res Response
+
+	if err := validateStoryStatus(status, releasedDate); err != nil {
+		return res, err
+	}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	updatedAt := time.Now()
+
+	con := db.CreateCon()
+
+	sqlStatement := "UPDATE story SET status = ?, updated_at = ? WHERE story_id = ?"
+	values := []interface{}{status, updatedAt, storyID}
+	if releasedDate != nil {
+		sqlStatement = "UPDATE story SET status = ?, released_date = ?, updated_at = ? WHERE story_id = ?"
+		values = []interface{}{status, *releasedDate, updatedAt, storyID}
+	}
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	// Lock the row and read the current status so story.published only fires
+	// on the change into published
+	var previousStatus string
+	var previousReleasedDate time.Time
+	err = tx.QueryRow("SELECT status, released_date FROM story WHERE story_id = ? FOR UPDATE", storyID).Scan(&previousStatus, &previousReleasedDate)
+	if err != nil {
+		return res, err
+	}
+	if status == StoryStatusPublished && releasedDate == nil && previousReleasedDate.After(time.Now()) {
+		return res, fmt.Errorf("%w: the story's released_date is in the future, use scheduled", ErrInvalidStoryStatus)
+	}
+	published := status == StoryStatusPublished && previousStatus != StoryStatusPublished
+
+	if err := ensureStoryRevisionBaseline(tx, storyID); err != nil {
+		return res, err
+	}
+
+	result, err := tx.Exec(sqlStatement, values...)
+	if err != nil {
+		return res, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+
+	if rowsAffected > 0 {
+		if _, err := recordStoryRevision(tx, storyID, "Status changed to "+status); err != nil {
+			return res, err
+		}
+
+		event := WebhookEventStoryUpdated
+		if published {
+			event = WebhookEventStoryPublished
+		}
+		if err := enqueueWebhookEvent(tx, event, storyID); err != nil {
+			return res, err
+		}
+
+		if published {
+			if err := notifyStoryPublished(tx, storyID); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"rowsAffected": rowsAffected,
+		"status":       status,
+		"updated_at":   updatedAt.In(loc),
+	}
+
+	return res, nil
+}
+
+// PublishScheduledStories publishes every scheduled story whose released_date
+// has passed and returns the IDs of the stories it published
+func PublishScheduledStories() ([]int, error) {
+	var storyIDs []int
+
+	con := db.CreateCon()
+
+	rows, err := con.Query("SELECT story_id FROM story WHERE status = ? AND released_date <= UTC_TIMESTAMP()", StoryStatusScheduled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var storyID int
+		if err := rows.Scan(&storyID); err != nil {
+			return nil, err
+		}
+		storyIDs = append(storyIDs, storyID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	published := make([]int, 0, len(storyIDs))
+	for _, storyID := range storyIDs {
+		ok, err := publishScheduledStory(con, storyID)
+		if err != nil {
+			return published, err
+		}
+		if ok {
+			published = append(published, storyID)
+		}
+	}
+
+	return published, nil
+}
+
+// publishScheduledStory flips one scheduled story to published. It reports false
+// when the story was rescheduled or unpublished in the meantime.
+func publishScheduledStory(con *sql.DB, storyID int) (bool, error) {
+	tx, err := con.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"UPDATE story SET status = ?, updated_at = ? WHERE story_id = ? AND status = ? AND released_date <= UTC_TIMESTAMP()",
+		StoryStatusPublished, time.Now(), storyID, StoryStatusScheduled,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		return false, err
+	}
+
+	if _, err := recordStoryRevision(tx, storyID, "Published on schedule"); err != nil {
+		return false, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryPublished, storyID); err != nil {
+		return false, err
+	}
+
+	if err := notifyStoryPublished(tx, storyID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateStoryStatus(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		status       string
+		releasedDate *time.Time
+		valid        bool
+	}{
+		{"draft", StoryStatusDraft, nil, true},
+		{"published without date", StoryStatusPublished, nil, true},
+		{"published in the past", StoryStatusPublished, &past, true},
+		{"published in the future", StoryStatusPublished, &future, false},
+		{"unknown status", "deleted", nil, false},
+		{"scheduled without date", StoryStatusScheduled, nil, false},
+		{"scheduled in the past", StoryStatusScheduled, &past, false},
+		{"scheduled in the future", StoryStatusScheduled, &future, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStoryStatus(tt.status, tt.releasedDate)
+			if tt.valid && err != nil {
+				t.Fatalf("validateStoryStatus() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidStoryStatus) {
+				t.Fatalf("validateStoryStatus() = %v, want ErrInvalidStoryStatus", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,535 @@
+// Story Translation Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+)
+
+const (
+	LocaleIndonesian = "id"
+	LocaleEnglish    = "en"
+)
+
+// storyLocalesColumn selects the locales a story aliased as "s" has page text in
+const storyLocalesColumn = "(SELECT GROUP_CONCAT(DISTINCT sct.locale) FROM story_content_translation sct WHERE sct.story_id = s.story_id AND sct.content <> '') AS locales"
+
+type Locale struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// SupportedLocales lists the languages stories can be translated into
+var SupportedLocales = []Locale{
+	{Code: LocaleIndonesian, Name: "Bahasa Indonesia"},
+	{Code: LocaleEnglish, Name: "English"},
+	{Code: "jv", Name: "Basa Jawa"},
+	{Code: "su", Name: "Basa Sunda"},
+	{Code: "ban", Name: "Basa Bali"},
+	{Code: "min", Name: "Baso Minang"},
+}
+
+// ErrInvalidTranslation is returned for a translation in an unsupported locale,
+// with an empty title or with pages the story does not have
+var ErrInvalidTranslation = errors.New("invalid translation")
+
+type StoryTranslation struct {
+	StoryID      int                       `json:"story_id"`
+	Locale       string                    `json:"locale"`
+	Title        string                    `json:"title"`
+	Synopsis     string                    `json:"synopsis"`
+	StoryContent []StoryContentTranslation `json:"story_content"`
+}
+
+// StoryTranslationInput is a translation sent by an editor. A title or synopsis
+// left out keeps its stored value.
+type StoryTranslationInput struct {
+	StoryID      int                       `json:"story_id"`
+	Locale       string                    `json:"locale"`
+	Title        *string                   `json:"title"`
+	Synopsis     *string                   `json:"synopsis"`
+	StoryContent []StoryContentTranslation `json:"story_content"`
+}
+
+type StoryContentTranslation struct {
+	Order   int    `json:"order"`
+	Content string `json:"content"`
+}
+
+type StoryLocale struct {
+	Locale          string `json:"locale"`
+	Name            string `json:"name"`
+	HasTitle        bool   `json:"has_title"`
+	TranslatedPages int    `json:"translated_pages"`
+	TotalContent    int    `json:"total_content"`
+	IsComplete      bool   `json:"is_complete"`
+}
+
+// IsSupportedLocale reports whether code is one of SupportedLocales
+func IsSupportedLocale(code string) bool {
+	return localeName(code) != ""
+}
+
+func localeName(code string) string {
+	for _, locale := range SupportedLocales {
+		if locale.Code == code {
+			return locale.Name
+		}
+	}
+	return ""
+}
+
+// GetStoryLocales lists the locales a story has text in and how complete each one is
+func GetStoryLocales(storyID int) (Response, error) {
+	var res Response
+	locales := make([]StoryLocale, 0)
+
+	con := db.CreateCon()
+
+	var totalContent int
+	err := con.QueryRow("SELECT COUNT(*) FROM story_content WHERE story_id = ?", storyID).Scan(&totalContent)
+	if err != nil {
+		return res, err
+	}
+
+	sqlStatement := `
+		SELECT
+			l.locale,
+			MAX(l.has_title) AS has_title,
+			SUM(l.pages) AS translated_pages
+		FROM (
+			SELECT locale, title <> '' AS has_title, 0 AS pages FROM story_translation WHERE story_id = ?
+			UNION ALL
+			SELECT locale, 0 AS has_title, COUNT(*) AS pages FROM story_content_translation WHERE story_id = ? AND content <> '' GROUP BY locale
+		) l
+		GROUP BY
+			l.locale`
+
+	rows, err := con.Query(sqlStatement, storyID, storyID)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]StoryLocale)
+	for rows.Next() {
+		var locale StoryLocale
+		if err := rows.Scan(&locale.Locale, &locale.HasTitle, &locale.TranslatedPages); err != nil {
+			return res, err
+		}
+		locale.Name = localeName(locale.Locale)
+		locale.TotalContent = totalContent
+		locale.IsComplete = locale.HasTitle && locale.TranslatedPages >= totalContent
+		found[locale.Locale] = locale
+	}
+
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	// Keep the order of SupportedLocales
+	for _, supported := range SupportedLocales {
+		if locale, ok := found[supported.Code]; ok {
+			locales = append(locales, locale)
+		}
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id": storyID,
+		"locales":  locales,
+	}
+
+	return res, nil
+}
+
+// GetStoryTranslation returns the title, synopsis and page text of a story in one locale
+func GetStoryTranslation(storyID int, locale string) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	translation, err := loadStoryTranslation(con, storyID, locale)
+	if err != nil {
+		return res, err
+	}
+
+	if translation.Title == "" && translation.Synopsis == "" && len(translation.StoryContent) == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	res.Data = map[string]interface{}{
+		"translation": translation,
+	}
+
+	return res, nil
+}
+
+// SaveStoryTranslation adds or updates the text of a story in one locale. Only the
+// given title, synopsis and pages are touched, and every page has to exist on the
+// story already.
+func SaveStoryTranslation(input StoryTranslationInput) (Response, error) {
+	var res Response
+
+	if err := validateStoryTranslation(input); err != nil {
+		return res, err
+	}
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return res, err
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	if err := ensureStoryRevisionBaseline(tx, input.StoryID); err != nil {
+		return res, err
+	}
+
+	for _, page := range input.StoryContent {
+		var exists int
+		err := tx.QueryRow("SELECT COUNT(*) FROM story_content WHERE story_id = ? AND `order` = ?", input.StoryID, page.Order).Scan(&exists)
+		if err != nil {
+			return res, err
+		}
+		if exists == 0 {
+			return res, fmt.Errorf("%w: story %d has no page with order %d", ErrInvalidTranslation, input.StoryID, page.Order)
+		}
+	}
+
+	title, synopsis, err := loadStoryTranslationTitle(tx, input.StoryID, input.Locale)
+	if err != nil {
+		return res, err
+	}
+	translation := mergeStoryTranslation(input, title, synopsis)
+
+	if err := saveStoryTranslation(tx, translation); err != nil {
+		return res, err
+	}
+
+	updatedAt := time.Now()
+	if _, err := tx.Exec("UPDATE story SET updated_at = ? WHERE story_id = ?", updatedAt, translation.StoryID); err != nil {
+		return res, err
+	}
+
+	revisionID, err := recordStoryRevision(tx, translation.StoryID, "Translation "+translation.Locale+" updated")
+	if err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, translation.StoryID); err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id":    translation.StoryID,
+		"locale":      translation.Locale,
+		"revision_id": revisionID,
+		"updated_at":  updatedAt.In(loc),
+	}
+
+	return res, nil
+}
+
+// DeleteStoryTranslation removes a locale from a story. The Indonesian text is the
+// story's own title and pages, so it cannot be removed.
+func DeleteStoryTranslation(storyID int, locale string) (Response, error) {
+	var res Response
+
+	if locale == LocaleIndonesian {
+		return res, fmt.Errorf("%w: the %q locale cannot be deleted", ErrInvalidTranslation, LocaleIndonesian)
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	if err := ensureStoryRevisionBaseline(tx, storyID); err != nil {
+		return res, err
+	}
+
+	result, err := tx.Exec("DELETE FROM story_translation WHERE story_id = ? AND locale = ?", storyID, locale)
+	if err != nil {
+		return res, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+
+	result, err = tx.Exec("DELETE FROM story_content_translation WHERE story_id = ? AND locale = ?", storyID, locale)
+	if err != nil {
+		return res, err
+	}
+	pagesAffected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+
+	if locale == LocaleEnglish {
+		if _, err := tx.Exec("UPDATE story_content SET content_eng = '' WHERE story_id = ?", storyID); err != nil {
+			return res, err
+		}
+	}
+
+	if rowsAffected+pagesAffected > 0 {
+		if _, err := recordStoryRevision(tx, storyID, "Translation "+locale+" deleted"); err != nil {
+			return res, err
+		}
+
+		if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+			return res, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"rowsAffected":   rowsAffected + pagesAffected,
+		"deleted_locale": locale,
+	}
+
+	return res, nil
+}
+
+// validateStoryTranslation checks the locale and that a title, when given, is not empty
+func validateStoryTranslation(input StoryTranslationInput) error {
+	if !IsSupportedLocale(input.Locale) {
+		return fmt.Errorf("%w: unsupported locale %q", ErrInvalidTranslation, input.Locale)
+	}
+	if input.Title != nil && strings.TrimSpace(*input.Title) == "" {
+		return fmt.Errorf("%w: title cannot be empty", ErrInvalidTranslation)
+	}
+	return nil
+}
+
+// mergeStoryTranslation fills the title and synopsis left out of input with the stored ones
+func mergeStoryTranslation(input StoryTranslationInput, title, synopsis string) StoryTranslation {
+	if input.Title != nil {
+		title = *input.Title
+	}
+	if input.Synopsis != nil {
+		synopsis = *input.Synopsis
+	}
+	return StoryTranslation{
+		StoryID:      input.StoryID,
+		Locale:       input.Locale,
+		Title:        title,
+		Synopsis:     synopsis,
+		StoryContent: input.StoryContent,
+	}
+}
+
+// loadStoryTranslationTitle returns the stored title and synopsis of a locale,
+// which for Indonesian are the story's own
+func loadStoryTranslationTitle(ex dbExecutor, storyID int, locale string) (string, string, error) {
+	var title, synopsis string
+	var err error
+	if locale == LocaleIndonesian {
+		err = ex.QueryRow("SELECT title, COALESCE(synopsis, '') FROM story WHERE story_id = ?", storyID).Scan(&title, &synopsis)
+	} else {
+		err = ex.QueryRow("SELECT title, synopsis FROM story_translation WHERE story_id = ? AND locale = ?", storyID, locale).Scan(&title, &synopsis)
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+	}
+	return title, synopsis, err
+}
+
+func loadStoryTranslation(ex dbExecutor, storyID int, locale string) (StoryTranslation, error) {
+	translation := StoryTranslation{
+		StoryID:      storyID,
+		Locale:       locale,
+		StoryContent: make([]StoryContentTranslation, 0),
+	}
+
+	err := ex.QueryRow("SELECT title, synopsis FROM story_translation WHERE story_id = ? AND locale = ?", storyID, locale).Scan(
+		&translation.Title,
+		&translation.Synopsis,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return translation, err
+	}
+
+	rows, err := ex.Query("SELECT `order`, content FROM story_content_translation WHERE story_id = ? AND locale = ? ORDER BY `order`", storyID, locale)
+	if err != nil {
+		return translation, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var page StoryContentTranslation
+		if err := rows.Scan(&page.Order, &page.Content); err != nil {
+			return translation, err
+		}
+		translation.StoryContent = append(translation.StoryContent, page)
+	}
+
+	return translation, rows.Err()
+}
+
+// saveStoryTranslation upserts a translation and mirrors the Indonesian and
+// English text into the story and story_content columns
+func saveStoryTranslation(ex dbExecutor, translation StoryTranslation) error {
+	now := time.Now()
+
+	_, err := ex.Exec(
+		"INSERT INTO story_translation (story_id, locale, title, synopsis, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE title = VALUES(title), synopsis = VALUES(synopsis), updated_at = VALUES(updated_at)",
+		translation.StoryID, translation.Locale, translation.Title, translation.Synopsis, now, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	if translation.Locale == LocaleIndonesian {
+		_, err := ex.Exec("UPDATE story SET title = ?, synopsis = ? WHERE story_id = ?", translation.Title, translation.Synopsis, translation.StoryID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, page := range translation.StoryContent {
+		if err := saveStoryContentTranslation(ex, translation.StoryID, page.Order, translation.Locale, page.Content, now); err != nil {
+			return err
+		}
+
+		mirrorColumn := ""
+		switch translation.Locale {
+		case LocaleIndonesian:
+			mirrorColumn = "content_indo"
+		case LocaleEnglish:
+			mirrorColumn = "content_eng"
+		}
+		if mirrorColumn != "" {
+			_, err := ex.Exec("UPDATE story_content SET "+mirrorColumn+" = ? WHERE story_id = ? AND `order` = ?", page.Content, translation.StoryID, page.Order)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func saveStoryContentTranslation(ex dbExecutor, storyID, order int, locale, content string, now time.Time) error {
+	_, err := ex.Exec(
+		"INSERT INTO story_content_translation (story_id, `order`, locale, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE content = VALUES(content), updated_at = VALUES(updated_at)",
+		storyID, order, locale, content, now, now,
+	)
+	return err
+}
+
+// syncStoryTitleTranslation copies the story title and synopsis into its Indonesian translation
+func syncStoryTitleTranslation(ex dbExecutor, storyID int) error {
+	now := time.Now()
+
+	_, err := ex.Exec(
+		"INSERT INTO story_translation (story_id, locale, title, synopsis, created_at, updated_at) SELECT story_id, ?, title, synopsis, ?, ? FROM story WHERE story_id = ? ON DUPLICATE KEY UPDATE title = VALUES(title), synopsis = VALUES(synopsis), updated_at = VALUES(updated_at)",
+		LocaleIndonesian, now, now, storyID,
+	)
+	return err
+}
+
+// syncStoryContentTranslations mirrors freshly written pages into the Indonesian and
+// English translations and drops translations of pages that no longer exist
+func syncStoryContentTranslations(ex dbExecutor, storyID int, content []StoryContentOnList) error {
+	now := time.Now()
+
+	orders := make([]string, 0, len(content))
+	for _, page := range content {
+		orders = append(orders, fmt.Sprint(page.Order))
+
+		texts := map[string]string{LocaleIndonesian: page.ContentIndo, LocaleEnglish: page.ContentEng}
+		for locale, text := range texts {
+			if text == "" {
+				_, err := ex.Exec("DELETE FROM story_content_translation WHERE story_id = ? AND `order` = ? AND locale = ?", storyID, page.Order, locale)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if err := saveStoryContentTranslation(ex, storyID, page.Order, locale, text, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	sqlStatement := "DELETE FROM story_content_translation WHERE story_id = ?"
+	if len(orders) > 0 {
+		sqlStatement += " AND `order` NOT IN (" + strings.Join(orders, ",") + ")"
+	}
+	_, err := ex.Exec(sqlStatement, storyID)
+	return err
+}
+
+// loadExtraStoryTranslations returns the translated text that is not mirrored in the
+// story and story_content columns: every title and synopsis except Indonesian, and
+// every page except Indonesian and English
+func loadExtraStoryTranslations(ex dbExecutor, storyID int) ([]StoryTranslation, error) {
+	translations := make([]StoryTranslation, 0)
+
+	for _, locale := range SupportedLocales {
+		if locale.Code == LocaleIndonesian {
+			continue
+		}
+
+		translation, err := loadStoryTranslation(ex, storyID, locale.Code)
+		if err != nil {
+			return nil, err
+		}
+		if locale.Code == LocaleEnglish {
+			translation.StoryContent = make([]StoryContentTranslation, 0)
+		}
+
+		if translation.Title == "" && translation.Synopsis == "" && len(translation.StoryContent) == 0 {
+			continue
+		}
+		translations = append(translations, translation)
+	}
+
+	return translations, nil
+}
+
+// restoreExtraStoryTranslations replaces the unmirrored translations of a story
+// with the ones from a snapshot
+func restoreExtraStoryTranslations(ex dbExecutor, storyID int, translations []StoryTranslation) error {
+	_, err := ex.Exec("DELETE FROM story_translation WHERE story_id = ? AND locale <> ?", storyID, LocaleIndonesian)
+	if err != nil {
+		return err
+	}
+
+	_, err = ex.Exec("DELETE FROM story_content_translation WHERE story_id = ? AND locale NOT IN (?, ?)", storyID, LocaleIndonesian, LocaleEnglish)
+	if err != nil {
+		return err
+	}
+
+	for _, translation := range translations {
+		translation.StoryID = storyID
+		if err := saveStoryTranslation(ex, translation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateStoryTranslation(t *testing.T) {
+	empty := " "
+	title := "Malin Kundang"
+
+	tests := []struct {
+		name  string
+		input StoryTranslationInput
+		valid bool
+	}{
+		{"pages only", StoryTranslationInput{Locale: "jv"}, true},
+		{"with title", StoryTranslationInput{Locale: LocaleIndonesian, Title: &title}, true},
+		{"empty title", StoryTranslationInput{Locale: LocaleIndonesian, Title: &empty}, false},
+		{"unsupported locale", StoryTranslationInput{Locale: "fr"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStoryTranslation(tt.input)
+			if tt.valid && err != nil {
+				t.Fatalf("validateStoryTranslation() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidTranslation) {
+				t.Fatalf("validateStoryTranslation() = %v, want ErrInvalidTranslation", err)
+			}
+		})
+	}
+}
+
+func TestMergeStoryTranslation(t *testing.T) {
+	pages := []StoryContentTranslation{{Order: 1, Content: "Biyen"}}
+	title := "Malin Kundang"
+	synopsis := ""
+
+	tests := []struct {
+		name  string
+		input StoryTranslationInput
+		want  StoryTranslation
+	}{
+		{
+			"pages only keep the stored title and synopsis",
+			StoryTranslationInput{StoryID: 1, Locale: "jv", StoryContent: pages},
+			StoryTranslation{StoryID: 1, Locale: "jv", Title: "Malin", Synopsis: "Anak durhaka", StoryContent: pages},
+		},
+		{
+			"given fields replace the stored ones",
+			StoryTranslationInput{StoryID: 1, Locale: "jv", Title: &title, Synopsis: &synopsis},
+			StoryTranslation{StoryID: 1, Locale: "jv", Title: "Malin Kundang", Synopsis: ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeStoryTranslation(tt.input, "Malin", "Anak durhaka")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeStoryTranslation() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Tag Model
+
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrDuplicateTag is returned when renaming a tag would give it the slug of another tag
+var ErrDuplicateTag = errors.New("another tag has the same slug")
+
+// Tag is a free-form label such as "hewan" or "asal-usul danau". UsageCount is
+// the number of published stories carrying it.
+type Tag struct {
+	TagID      int       `json:"tag_id"`
+	TagName    string    `json:"tag_name"`
+	Slug       string    `json:"slug"`
+	UsageCount int       `json:"usage_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// StoryTag is a tag as shown on a story
+type StoryTag struct {
+	TagID   int    `json:"tag_id"`
+	TagName string `json:"tag_name"`
+	Slug    string `json:"slug"`
+}
+
+// tagColumns selects a tag with its usage count
+const tagColumns = "t.tag_id, t.tag_name, t.slug, (SELECT COUNT(*) FROM story_tag st JOIN story s ON st.story_id = s.story_id WHERE st.tag_id = t.tag_id AND " + publishedStoryCondition + ") AS usage_count, t.created_at, t.updated_at"
+
+// TagSlug normalizes a tag name: lowercase letters and digits with single
+// hyphens between words, so "Asal-usul  Danau" becomes "asal-usul-danau"
+func TagSlug(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+		} else {
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
+
+func scanTags(rows *sql.Rows) ([]Tag, error) {
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.TagID, &tag.TagName, &tag.Slug, &tag.UsageCount, &tag.CreatedAt, &tag.UpdatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetAllTags lists tags with their usage counts, most used first, optionally only
+// those whose name contains keyword
+func GetAllTags(keyword string) ([]Tag, error) {
+	db := db.CreateCon()
+
+	sqlStatement := "SELECT " + tagColumns + " FROM tag t"
+	args := []interface{}{}
+	if keyword != "" {
+		sqlStatement += " WHERE t.tag_name LIKE ?"
+		args = append(args, "%"+keyword+"%")
+	}
+	sqlStatement += " ORDER BY usage_count DESC, t.tag_name"
+
+	rows, err := db.Query(sqlStatement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTags(rows)
+}
+
+// AutocompleteTags suggests up to limit tags whose name or slug starts with
+// prefix, most used first
+func AutocompleteTags(prefix string, limit int) ([]Tag, error) {
+	db := db.CreateCon()
+
+	// Escape LIKE wildcards typed by the user
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.TrimSpace(prefix))
+
+	rows, err := db.Query("SELECT "+tagColumns+" FROM tag t WHERE t.tag_name LIKE ? OR t.slug LIKE ? ORDER BY usage_count DESC, t.tag_name LIMIT ?",
+		escaped+"%", TagSlug(prefix)+"%", limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTags(rows)
+}
+
+// UpdateTag renames a tag, which also changes its slug. Renaming onto the slug
+// of another tag returns ErrDuplicateTag.
+func UpdateTag(tagID int, tagName string) (int64, error) {
+	slug := TagSlug(tagName)
+	if slug == "" {
+		return 0, fmt.Errorf("tag name %q has no letters or digits", tagName)
+	}
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRow("SELECT tag_id FROM tag WHERE tag_id = ? FOR UPDATE", tagID).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	// The locking read also keeps a concurrent insert from taking the slug
+	var otherID int
+	err = tx.QueryRow("SELECT tag_id FROM tag WHERE slug = ? AND tag_id <> ? FOR UPDATE", slug, tagID).Scan(&otherID)
+	if err == nil {
+		return 0, fmt.Errorf("%w: %q is used by tag %d", ErrDuplicateTag, slug, otherID)
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	result, err := tx.Exec("UPDATE tag SET tag_name = ?, slug = ?, updated_at = ? WHERE tag_id = ?",
+		strings.TrimSpace(tagName), slug, time.Now(), tagID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func DeleteTag(tagID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM tag WHERE tag_id = ?", tagID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// SetStoryTags replaces the tags of a story by name. Tags that do not exist yet
+// are created, and names differing only in case or punctuation share one tag.
+func SetStoryTags(storyID int, tagNames []string) (Response, error) {
+	var res Response
+
+	con := db.CreateCon()
+
+	tx, err := con.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM story WHERE story_id = ?", storyID).Scan(&exists); err != nil {
+		return res, err
+	}
+	if exists == 0 {
+		return res, sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec("DELETE FROM story_tag WHERE story_id = ?", storyID); err != nil {
+		return res, err
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	for _, tagName := range tagNames {
+		slug := TagSlug(tagName)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		// Keep the existing name when the tag is already known
+		_, err := tx.Exec("INSERT INTO tag (tag_name, slug, created_at, updated_at) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE tag_id = tag_id",
+			strings.TrimSpace(tagName), slug, now, now,
+		)
+		if err != nil {
+			return res, err
+		}
+
+		if _, err := tx.Exec("INSERT INTO story_tag (story_id, tag_id) SELECT ?, tag_id FROM tag WHERE slug = ?", storyID, slug); err != nil {
+			return res, err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE story SET updated_at = ? WHERE story_id = ?", now, storyID); err != nil {
+		return res, err
+	}
+
+	if err := enqueueWebhookEvent(tx, WebhookEventStoryUpdated, storyID); err != nil {
+		return res, err
+	}
+
+	tags, err := getStoryTags(tx, storyID)
+	if err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+
+	res.Data = map[string]interface{}{
+		"story_id": storyID,
+		"tags":     tags,
+	}
+
+	return res, nil
+}
+
+// getStoryTags lists the tags of a story
+func getStoryTags(ex dbExecutor, storyID int) ([]StoryTag, error) {
+	tags := []StoryTag{}
+
+	rows, err := ex.Query("SELECT t.tag_id, t.tag_name, t.slug FROM story_tag st JOIN tag t ON st.tag_id = t.tag_id WHERE st.story_id = ? ORDER BY t.tag_name", storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag StoryTag
+		if err := rows.Scan(&tag.TagID, &tag.TagName, &tag.Slug); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// tagCondition matches stories carrying every one of the given tag slugs. Repeated
+// slugs are counted once, or no story could match.
+func tagCondition(slugs []string) (string, []interface{}) {
+	placeholders := []string{}
+	args := []interface{}{}
+	seen := make(map[string]bool)
+	for _, slug := range slugs {
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		placeholders = append(placeholders, "?")
+		args = append(args, slug)
+	}
+	args = append(args, len(placeholders))
+	return "(SELECT COUNT(DISTINCT t.tag_id) FROM story_tag st JOIN tag t ON st.tag_id = t.tag_id WHERE st.story_id = s.story_id AND t.slug IN (" + strings.Join(placeholders, ", ") + ")) = ?", args
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTagSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Hewan", "hewan"},
+		{"Asal-usul  Danau", "asal-usul-danau"},
+		{"  asal usul danau  ", "asal-usul-danau"},
+		{"--Legenda!!", "legenda"},
+		{"Cerita Rakyat #1", "cerita-rakyat-1"},
+		{"Dongeng_Anak", "dongeng-anak"},
+		{"Mitos Ñusa", "mitos-ñusa"},
+		{"!!!", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := TagSlug(tt.name); got != tt.want {
+			t.Errorf("TagSlug(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTagConditionDeduplicatesSlugs(t *testing.T) {
+	tests := []struct {
+		slugs     []string
+		wantSlugs []interface{}
+	}{
+		{[]string{"hewan"}, []interface{}{"hewan"}},
+		{[]string{"hewan", "legenda"}, []interface{}{"hewan", "legenda"}},
+		{[]string{"hewan", "legenda", "hewan"}, []interface{}{"hewan", "legenda"}},
+	}
+
+	for _, tt := range tests {
+		condition, args := tagCondition(tt.slugs)
+
+		wantArgs := append(tt.wantSlugs, len(tt.wantSlugs))
+		if len(args) != len(wantArgs) {
+			t.Fatalf("tagCondition(%v) args = %v, want %v", tt.slugs, args, wantArgs)
+		}
+		for i := range args {
+			if args[i] != wantArgs[i] {
+				t.Fatalf("tagCondition(%v) args = %v, want %v", tt.slugs, args, wantArgs)
+			}
+		}
+		if got := strings.Count(condition, "?"); got != len(wantArgs) {
+			t.Fatalf("tagCondition(%v) has %d placeholders, want %d", tt.slugs, got, len(wantArgs))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Taxonomy Translation Model
+
+package models
+
+import (
+	"fmt"
+	"kisahloka_be/db"
+	"time"
+)
+
+type TaxonomyTranslation struct {
+	Taxonomy   string    `json:"taxonomy"`
+	TaxonomyID int       `json:"taxonomy_id"`
+	Locale     string    `json:"locale"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// IsValidTaxonomy reports whether taxonomy names a translatable taxonomy
+func IsValidTaxonomy(taxonomy string) bool {
+	switch taxonomy {
+	case TaxonomyType, TaxonomyOrigin, TaxonomyGenre, TaxonomyMoralValue:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetTaxonomyTranslations lists every translated name of one type, origin, genre or moral value
+func GetTaxonomyTranslations(taxonomy string, taxonomyID int) ([]TaxonomyTranslation, error) {
+	translations := make([]TaxonomyTranslation, 0)
+
+	db := db.CreateCon()
+
+	rows, err := db.Query("SELECT taxonomy, taxonomy_id, locale, name, created_at, updated_at FROM taxonomy_translation WHERE taxonomy = ? AND taxonomy_id = ? ORDER BY locale", taxonomy, taxonomyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var translation TaxonomyTranslation
+		err := rows.Scan(&translation.Taxonomy, &translation.TaxonomyID, &translation.Locale, &translation.Name, &translation.CreatedAt, &translation.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, translation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+// SaveTaxonomyTranslation adds or replaces the name of a type, origin, genre or moral value in one locale
+func SaveTaxonomyTranslation(taxonomy string, taxonomyID int, locale, name string) (int64, error) {
+	if !IsValidTaxonomy(taxonomy) {
+		return 0, fmt.Errorf("invalid taxonomy %q", taxonomy)
+	}
+	if !IsSupportedLocale(locale) {
+		return 0, fmt.Errorf("unsupported locale %q", locale)
+	}
+
+	db := db.CreateCon()
+
+	result, err := db.Exec("INSERT INTO taxonomy_translation (taxonomy, taxonomy_id, locale, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name), updated_at = VALUES(updated_at)",
+		taxonomy, taxonomyID, locale, name, time.Now(), time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// DeleteTaxonomyTranslation removes the name of a type, origin, genre or moral value in one locale
+func DeleteTaxonomyTranslation(taxonomy string, taxonomyID int, locale string) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM taxonomy_translation WHERE taxonomy = ? AND taxonomy_id = ? AND locale = ?", taxonomy, taxonomyID, locale)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
@@ -14,7 +14,8 @@ type Type struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-func GetAllTypes(page, pageSize int, keyword string) (Response, error) {
+// GetAllTypes lists the types with their names in the given locale, if any
+func GetAllTypes(page, pageSize int, keyword, locale string) (Response, error) {
 	var res Response
 	var arrobj reflect.Value
 	var meta Meta
@@ -72,6 +73,8 @@ func GetAllTypes(page, pageSize int, keyword string) (Response, error) {
 	}
 	defer rows.Close()
 
+	l := newLocalizer(locale)
+
 	for rows.Next() {
 		var obj Type
 		err := rows.Scan(
@@ -88,6 +91,12 @@ func GetAllTypes(page, pageSize int, keyword string) (Response, error) {
 		obj.CreatedAt = obj.CreatedAt.In(loc)
 		obj.UpdatedAt = obj.UpdatedAt.In(loc)
 
+		if l != nil {
+			if obj.TypeName, err = l.taxonomyName(TaxonomyType, obj.TypeID, obj.TypeName); err != nil {
+				return res, err
+			}
+		}
+
 		if !arrobj.IsValid() {
 			arrobj = reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(obj)), 0, 0)
 		}
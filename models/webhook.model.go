@@ -0,0 +1,584 @@
+// Webhook Model
+
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kisahloka_be/db"
+	"kisahloka_be/webhook"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrInvalidWebhook is returned for a webhook with a bad URL or event types
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// Story events webhooks can subscribe to
+const (
+	WebhookEventStoryCreated   = "story.created"
+	WebhookEventStoryUpdated   = "story.updated"
+	WebhookEventStoryPublished = "story.published"
+	WebhookEventStoryDeleted   = "story.deleted"
+)
+
+// IsValidWebhookEvent reports whether event is one webhooks can subscribe to
+func IsValidWebhookEvent(event string) bool {
+	switch event {
+	case WebhookEventStoryCreated, WebhookEventStoryUpdated, WebhookEventStoryPublished, WebhookEventStoryDeleted:
+		return true
+	}
+	return false
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// IsValidWebhookDeliveryStatus reports whether status is a known delivery status
+func IsValidWebhookDeliveryStatus(status string) bool {
+	return status == WebhookDeliveryPending || status == WebhookDeliveryDelivered || status == WebhookDeliveryFailed
+}
+
+// Webhook is a subscription of an external system to story events. The secret
+// is only returned when the webhook is created.
+type Webhook struct {
+	WebhookID  int       `json:"webhook_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one webhook
+type WebhookDelivery struct {
+	DeliveryID     int                      `json:"delivery_id"`
+	WebhookID      int                      `json:"webhook_id"`
+	EventType      string                   `json:"event_type"`
+	Payload        json.RawMessage          `json:"payload"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  time.Time                `json:"next_attempt_at"`
+	LastStatusCode *int                     `json:"last_status_code"`
+	LastError      *string                  `json:"last_error"`
+	DeliveredAt    *time.Time               `json:"delivered_at"`
+	CreatedAt      time.Time                `json:"created_at"`
+	Log            []WebhookDeliveryAttempt `json:"log,omitempty"`
+}
+
+// WebhookDeliveryAttempt is an entry of the delivery log
+type WebhookDeliveryAttempt struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  *int      `json:"status_code"`
+	Error       *string   `json:"error"`
+	DurationMs  int       `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// webhookPayload is the JSON body sent to subscribers
+type webhookPayload struct {
+	Event      string               `json:"event"`
+	OccurredAt time.Time            `json:"occurred_at"`
+	Story      webhookStorySnapshot `json:"story"`
+}
+
+type webhookStorySnapshot struct {
+	StoryID      int       `json:"story_id"`
+	Title        string    `json:"title"`
+	Status       string    `json:"status"`
+	TypeID       int       `json:"type_id"`
+	OriginID     int       `json:"origin_id"`
+	ReleasedDate time.Time `json:"released_date"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+const webhookColumns = "webhook_id, url, event_types, is_active, created_at, updated_at"
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (Webhook, error) {
+	var hook Webhook
+	var eventTypes string
+	if err := row.Scan(&hook.WebhookID, &hook.URL, &eventTypes, &hook.IsActive, &hook.CreatedAt, &hook.UpdatedAt); err != nil {
+		return hook, err
+	}
+	hook.EventTypes = strings.Split(eventTypes, ",")
+	return hook, nil
+}
+
+func GetAllWebhooks() ([]Webhook, error) {
+	webhooks := []Webhook{}
+
+	db := db.CreateCon()
+
+	rows, err := db.Query("SELECT " + webhookColumns + " FROM webhook ORDER BY webhook_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, hook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func GetWebhookDetail(webhookID int) (Webhook, error) {
+	db := db.CreateCon()
+
+	return scanWebhook(db.QueryRow("SELECT "+webhookColumns+" FROM webhook WHERE webhook_id = ?", webhookID))
+}
+
+// validateWebhook checks the URL and event types and removes duplicate events
+func validateWebhook(hook *Webhook) error {
+	target, err := url.Parse(strings.TrimSpace(hook.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	hook.URL = target.String()
+
+	if len(hook.EventTypes) == 0 {
+		return fmt.Errorf("%w: event_types is required", ErrInvalidWebhook)
+	}
+	seen := make(map[string]bool)
+	var eventTypes []string
+	for _, event := range hook.EventTypes {
+		if !IsValidWebhookEvent(event) {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			eventTypes = append(eventTypes, event)
+		}
+	}
+	hook.EventTypes = eventTypes
+
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateWebhook adds a subscription and returns it with its secret, which is
+// generated when none is given
+func CreateWebhook(hook Webhook) (Webhook, error) {
+	if err := validateWebhook(&hook); err != nil {
+		return hook, err
+	}
+
+	if hook.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return hook, err
+		}
+		hook.Secret = secret
+	}
+
+	db := db.CreateCon()
+
+	hook.CreatedAt = time.Now()
+	hook.UpdatedAt = hook.CreatedAt
+	result, err := db.Exec("INSERT INTO webhook (url, secret, event_types, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		hook.URL, hook.Secret, strings.Join(hook.EventTypes, ","), hook.IsActive, hook.CreatedAt, hook.UpdatedAt,
+	)
+	if err != nil {
+		return hook, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return hook, err
+	}
+	hook.WebhookID = int(id)
+
+	return hook, nil
+}
+
+// UpdateWebhook replaces a subscription. The secret is kept unless a new one is given.
+func UpdateWebhook(hook Webhook) (int64, error) {
+	if err := validateWebhook(&hook); err != nil {
+		return 0, err
+	}
+
+	db := db.CreateCon()
+
+	sqlStatement := "UPDATE webhook SET url = ?, event_types = ?, is_active = ?, updated_at = ? WHERE webhook_id = ?"
+	values := []interface{}{hook.URL, strings.Join(hook.EventTypes, ","), hook.IsActive, time.Now(), hook.WebhookID}
+	if hook.Secret != "" {
+		sqlStatement = "UPDATE webhook SET url = ?, secret = ?, event_types = ?, is_active = ?, updated_at = ? WHERE webhook_id = ?"
+		values = []interface{}{hook.URL, hook.Secret, strings.Join(hook.EventTypes, ","), hook.IsActive, time.Now(), hook.WebhookID}
+	}
+
+	result, err := db.Exec(sqlStatement, values...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// MySQL reports no affected rows for an update that changes nothing, so
+	// tell an unknown webhook apart from an unchanged one
+	if rowsAffected == 0 {
+		var exists int
+		if err := db.QueryRow("SELECT 1 FROM webhook WHERE webhook_id = ?", hook.WebhookID).Scan(&exists); err != nil {
+			return 0, err
+		}
+	}
+
+	return rowsAffected, nil
+}
+
+func DeleteWebhook(webhookID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("DELETE FROM webhook WHERE webhook_id = ?", webhookID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+const webhookDeliveryColumns = "delivery_id, webhook_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at"
+
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }, loc *time.Location) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	var payload string
+	var lastStatusCode sql.NullInt64
+	var lastError sql.NullString
+	var deliveredAt sql.NullTime
+	err := row.Scan(&delivery.DeliveryID, &delivery.WebhookID, &delivery.EventType, &payload, &delivery.Status, &delivery.Attempts,
+		&delivery.NextAttemptAt, &lastStatusCode, &lastError, &deliveredAt, &delivery.CreatedAt)
+	if err != nil {
+		return delivery, err
+	}
+
+	delivery.Payload = json.RawMessage(payload)
+	if lastStatusCode.Valid {
+		code := int(lastStatusCode.Int64)
+		delivery.LastStatusCode = &code
+	}
+	if lastError.Valid {
+		delivery.LastError = &lastError.String
+	}
+
+	// Convert time fields to UTC+8 (Asia/Shanghai) before including them in the response
+	if deliveredAt.Valid {
+		t := deliveredAt.Time.In(loc)
+		delivery.DeliveredAt = &t
+	}
+	delivery.NextAttemptAt = delivery.NextAttemptAt.In(loc)
+	delivery.CreatedAt = delivery.CreatedAt.In(loc)
+
+	return delivery, nil
+}
+
+// GetWebhookDeliveries lists a page of a webhook's deliveries, newest first,
+// optionally limited to one status
+func GetWebhookDeliveries(webhookID, page, pageSize int, status string) (Response, error) {
+	var res Response
+	var meta Meta
+	deliveries := []WebhookDelivery{}
+
+	con := db.CreateCon()
+
+	whereClause := " WHERE webhook_id = ?"
+	args := []interface{}{webhookID}
+	if status != "" {
+		whereClause += " AND status = ?"
+		args = append(args, status)
+	}
+
+	var totalItems int
+	if err := con.QueryRow("SELECT COUNT(*) FROM webhook_delivery"+whereClause, args...).Scan(&totalItems); err != nil {
+		return res, err
+	}
+
+	meta.Limit = pageSize
+	meta.Page = page
+	meta.TotalPages = calculateTotalPages(totalItems, pageSize)
+	meta.TotalItems = totalItems
+
+	if totalItems > 0 {
+		// Check if the requested page is greater than the total number of pages
+		if page > meta.TotalPages {
+			return res, fmt.Errorf("requested page (%d) exceeds total number of pages (%d)", page, meta.TotalPages)
+		}
+
+		// Load the UTC+8 time zone
+		loc, err := time.LoadLocation("Asia/Shanghai")
+		if err != nil {
+			return res, err
+		}
+
+		// Calculate the offset based on the page number and page size
+		offset := (page - 1) * pageSize
+
+		rows, err := con.Query("SELECT "+webhookDeliveryColumns+" FROM webhook_delivery"+whereClause+" ORDER BY created_at DESC, delivery_id DESC LIMIT ? OFFSET ?", append(args, pageSize, offset)...)
+		if err != nil {
+			return res, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			delivery, err := scanWebhookDelivery(rows, loc)
+			if err != nil {
+				return res, err
+			}
+			deliveries = append(deliveries, delivery)
+		}
+
+		if err := rows.Err(); err != nil {
+			return res, err
+		}
+	}
+
+	res.Data = map[string]interface{}{
+		"deliveries": deliveries,
+		"meta":       meta,
+	}
+
+	return res, nil
+}
+
+// GetWebhookDeliveryDetail returns a delivery with the log of its attempts
+func GetWebhookDeliveryDetail(deliveryID int) (WebhookDelivery, error) {
+	db := db.CreateCon()
+
+	// Load the UTC+8 time zone
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	delivery, err := scanWebhookDelivery(db.QueryRow("SELECT "+webhookDeliveryColumns+" FROM webhook_delivery WHERE delivery_id = ?", deliveryID), loc)
+	if err != nil {
+		return delivery, err
+	}
+
+	rows, err := db.Query("SELECT attempt, status_code, error, duration_ms, attempted_at FROM webhook_delivery_attempt WHERE delivery_id = ? ORDER BY attempt", deliveryID)
+	if err != nil {
+		return delivery, err
+	}
+	defer rows.Close()
+
+	delivery.Log = []WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var attempt WebhookDeliveryAttempt
+		var statusCode sql.NullInt64
+		var attemptError sql.NullString
+		if err := rows.Scan(&attempt.Attempt, &statusCode, &attemptError, &attempt.DurationMs, &attempt.AttemptedAt); err != nil {
+			return delivery, err
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			attempt.StatusCode = &code
+		}
+		if attemptError.Valid {
+			attempt.Error = &attemptError.String
+		}
+		attempt.AttemptedAt = attempt.AttemptedAt.In(loc)
+		delivery.Log = append(delivery.Log, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return delivery, err
+	}
+
+	return delivery, nil
+}
+
+// RetryWebhookDelivery queues a delivery again right away. A failed delivery
+// gets one more attempt; its earlier attempts stay in the log.
+func RetryWebhookDelivery(deliveryID int) (int64, error) {
+	db := db.CreateCon()
+
+	result, err := db.Exec("UPDATE webhook_delivery SET status = ?, next_attempt_at = ? WHERE delivery_id = ? AND status <> ?",
+		WebhookDeliveryPending, time.Now(), deliveryID, WebhookDeliveryDelivered,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// enqueueWebhookEvent queues a story event for every active webhook subscribed
+// to it. Called inside the transaction changing the story, so an event is
+// queued exactly when the change is committed; for story.deleted it has to
+// run before the story row is gone.
+func enqueueWebhookEvent(ex dbExecutor, event string, storyID int) error {
+	rows, err := ex.Query("SELECT webhook_id FROM webhook WHERE is_active = 1 AND FIND_IN_SET(?, event_types) > 0", event)
+	if err != nil {
+		return err
+	}
+	var webhookIDs []int
+	for rows.Next() {
+		var webhookID int
+		if err := rows.Scan(&webhookID); err != nil {
+			rows.Close()
+			return err
+		}
+		webhookIDs = append(webhookIDs, webhookID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(webhookIDs) == 0 {
+		return nil
+	}
+
+	payload := webhookPayload{Event: event, OccurredAt: time.Now().UTC()}
+	err = ex.QueryRow("SELECT story_id, title, status, type_id, origin_id, released_date, updated_at FROM story WHERE story_id = ?", storyID).Scan(
+		&payload.Story.StoryID, &payload.Story.Title, &payload.Story.Status, &payload.Story.TypeID, &payload.Story.OriginID,
+		&payload.Story.ReleasedDate, &payload.Story.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, webhookID := range webhookIDs {
+		_, err := ex.Exec("INSERT INTO webhook_delivery (webhook_id, event_type, payload, status, attempts, next_attempt_at, created_at) VALUES (?, ?, ?, ?, 0, ?, ?)",
+			webhookID, event, string(body), WebhookDeliveryPending, now, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeliverDueWebhooks sends up to limit pending deliveries whose next attempt is
+// due. Failed attempts are retried with exponential backoff until the
+// configured number of attempts is used up. Returns how many were delivered
+// and how many were given up.
+func DeliverDueWebhooks(limit int) (int, int, error) {
+	con := db.CreateCon()
+
+	type dueDelivery struct {
+		webhook.Request
+		attempts int
+	}
+
+	rows, err := con.Query(`
+		SELECT d.delivery_id, d.event_type, d.payload, d.attempts, w.url, w.secret
+		FROM webhook_delivery d
+		JOIN webhook w ON d.webhook_id = w.webhook_id
+		WHERE d.status = ? AND d.next_attempt_at <= UTC_TIMESTAMP() AND w.is_active = 1
+		ORDER BY d.next_attempt_at, d.delivery_id
+		LIMIT ?`,
+		WebhookDeliveryPending, limit,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	var due []dueDelivery
+	for rows.Next() {
+		var d dueDelivery
+		var payload string
+		if err := rows.Scan(&d.DeliveryID, &d.Event, &payload, &d.attempts, &d.URL, &d.Secret); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		d.Body = []byte(payload)
+		due = append(due, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	delivered, failed := 0, 0
+	for _, d := range due {
+		attempt := d.attempts + 1
+
+		started := time.Now()
+		statusCode, sendErr := webhook.Deliver(context.Background(), d.Request)
+		duration := time.Since(started)
+
+		var code interface{}
+		if statusCode > 0 {
+			code = statusCode
+		}
+		var errText interface{}
+		if sendErr != nil {
+			errText = sendErr.Error()
+		}
+
+		_, err := con.Exec("INSERT INTO webhook_delivery_attempt (delivery_id, attempt, status_code, error, duration_ms, attempted_at) VALUES (?, ?, ?, ?, ?, ?)",
+			d.DeliveryID, attempt, code, errText, duration.Milliseconds(), started,
+		)
+		if err != nil {
+			return delivered, failed, err
+		}
+
+		switch {
+		case sendErr == nil:
+			_, err = con.Exec("UPDATE webhook_delivery SET status = ?, attempts = ?, last_status_code = ?, last_error = NULL, delivered_at = ? WHERE delivery_id = ?",
+				WebhookDeliveryDelivered, attempt, code, time.Now(), d.DeliveryID,
+			)
+			delivered++
+		case attempt >= webhook.MaxAttempts():
+			_, err = con.Exec("UPDATE webhook_delivery SET status = ?, attempts = ?, last_status_code = ?, last_error = ? WHERE delivery_id = ?",
+				WebhookDeliveryFailed, attempt, code, errText, d.DeliveryID,
+			)
+			failed++
+		default:
+			_, err = con.Exec("UPDATE webhook_delivery SET attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ? WHERE delivery_id = ?",
+				attempt, time.Now().Add(webhook.Backoff(attempt)), code, errText, d.DeliveryID,
+			)
+		}
+		if err != nil {
+			return delivered, failed, err
+		}
+	}
+
+	return delivered, failed, nil
+}
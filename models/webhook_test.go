@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		hook       Webhook
+		valid      bool
+		eventTypes []string
+	}{
+		{"valid", Webhook{URL: "https://example.com/hook", EventTypes: []string{WebhookEventStoryCreated}}, true, []string{WebhookEventStoryCreated}},
+		{"duplicate events", Webhook{URL: "http://example.com", EventTypes: []string{WebhookEventStoryPublished, WebhookEventStoryPublished}}, true, []string{WebhookEventStoryPublished}},
+		{"relative url", Webhook{URL: "/hook", EventTypes: []string{WebhookEventStoryCreated}}, false, nil},
+		{"ftp url", Webhook{URL: "ftp://example.com", EventTypes: []string{WebhookEventStoryCreated}}, false, nil},
+		{"no events", Webhook{URL: "https://example.com"}, false, nil},
+		{"unknown event", Webhook{URL: "https://example.com", EventTypes: []string{"story.liked"}}, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := tt.hook
+			err := validateWebhook(&hook)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidWebhook) {
+					t.Fatalf("validateWebhook() = %v, want ErrInvalidWebhook", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateWebhook() = %v, want nil", err)
+			}
+			if !reflect.DeepEqual(hook.EventTypes, tt.eventTypes) {
+				t.Errorf("EventTypes = %v, want %v", hook.EventTypes, tt.eventTypes)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package moderation
+
+import (
+	"kisahloka_be/config"
+	"strings"
+	"unicode"
+)
+
+// Filter flags text containing banned words. Single words match whole words
+// only, so "anjing" does not flag "anjungan"; phrases match word sequences.
+type Filter struct {
+	words   map[string]bool
+	phrases [][]string
+}
+
+var (
+	filter         = NewFilter()
+	trustThreshold = 3
+)
+
+// ModerationInit loads the banned-word lists (Indonesian and English) and the
+// comment trust threshold from the config
+func ModerationInit() {
+	conf := config.GetModerationConfig()
+	filter = NewFilter(conf.BANNED_WORDS_ID, conf.BANNED_WORDS_EN)
+	trustThreshold = conf.COMMENT_TRUST_THRESHOLD
+}
+
+func GetFilter() *Filter {
+	return filter
+}
+
+// TrustThreshold is the number of approved comments, less those offset by
+// rejected or hidden ones, after which a user's new comments skip the
+// moderation queue
+func TrustThreshold() int {
+	return trustThreshold
+}
+
+// NewFilter creates a filter from one or more word lists
+func NewFilter(lists ...[]string) *Filter {
+	f := &Filter{words: make(map[string]bool)}
+	for _, list := range lists {
+		for _, entry := range list {
+			tokens := tokenize(entry)
+			switch len(tokens) {
+			case 0:
+			case 1:
+				f.words[tokens[0]] = true
+			default:
+				f.phrases = append(f.phrases, tokens)
+			}
+		}
+	}
+	return f
+}
+
+// Matches returns the banned words and phrases found in text, each once, in
+// the order they appear
+func (f *Filter) Matches(text string) []string {
+	tokens := tokenize(text)
+
+	var matches []string
+	seen := make(map[string]bool)
+	add := func(match string) {
+		if !seen[match] {
+			seen[match] = true
+			matches = append(matches, match)
+		}
+	}
+
+	for i, token := range tokens {
+		if f.words[token] {
+			add(token)
+		}
+		for _, phrase := range f.phrases {
+			if i+len(phrase) <= len(tokens) && equalTokens(tokens[i:i+len(phrase)], phrase) {
+				add(strings.Join(phrase, " "))
+			}
+		}
+	}
+
+	return matches
+}
+
+// tokenize lowercases text and splits it into words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func equalTokens(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterMatches(t *testing.T) {
+	f := NewFilter(
+		[]string{"anjing", "Bodoh", "babi hutan", "  "},
+		[]string{"stupid", "shut up"},
+	)
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"clean text", "Ceritanya bagus sekali!", nil},
+		{"whole word only", "Kami berjalan ke anjungan pantai", nil},
+		{"single word", "dasar anjing", []string{"anjing"}},
+		{"case and punctuation", "BODOH!!! sungguh...", []string{"bodoh"}},
+		{"each match once in order", "stupid anjing, anjing stupid", []string{"stupid", "anjing"}},
+		{"phrase across extra spaces", "Shut   up, please", []string{"shut up"}},
+		{"phrase words apart", "shut the door and up we go", nil},
+		{"phrase at the end", "ada babi hutan", []string{"babi hutan"}},
+		{"phrase cut off", "ada babi", nil},
+		{"word and phrase", "babi hutan bodoh", []string{"babi hutan", "bodoh"}},
+		{"empty text", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Matches(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Matches(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmptyFilterMatchesNothing(t *testing.T) {
+	if got := NewFilter().Matches("anjing bodoh"); got != nil {
+		t.Fatalf("Matches() = %q, want nil", got)
+	}
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake records the messages it is asked to send so tests can inspect them.
+// Tokens listed in Invalid fail with ErrInvalidToken.
+type Fake struct {
+	mu      sync.Mutex
+	sent    []Message
+	Invalid map[string]bool
+}
+
+func (f *Fake) Send(ctx context.Context, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Invalid[msg.Token] {
+		return ErrInvalidToken
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+// Sent returns a copy of the messages sent so far
+func (f *Fake) Sent() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Message(nil), f.sent...)
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"kisahloka_be/config"
+	"log"
+)
+
+// ErrInvalidToken is returned when the push provider no longer knows a device
+// token, so the caller can forget it
+var ErrInvalidToken = errors.New("notify: invalid device token")
+
+// Message is one push notification for one device
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	// Data is delivered to the app alongside the notification, e.g. the story_id to open
+	Data map[string]string
+}
+
+// Sender delivers push notifications through a provider
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var sender Sender = Log{}
+
+// NotifyInit creates the push sender configured by PUSH_DRIVER ("fcm", "log" or "none")
+func NotifyInit() {
+	conf := config.GetNotificationConfig()
+
+	switch conf.PUSH_DRIVER {
+	case "fcm":
+		fcm, err := NewFCM(FCMConfig{
+			ProjectID:       conf.FCM_PROJECT_ID,
+			CredentialsFile: conf.FCM_CREDENTIALS_FILE,
+		})
+		if err != nil {
+			log.Fatalf("notify: %v", err)
+		}
+		sender = fcm
+	case "", "log":
+		sender = Log{}
+	case "none":
+		sender = None{}
+	default:
+		log.Fatalf("notify: unknown PUSH_DRIVER %q", conf.PUSH_DRIVER)
+	}
+}
+
+func GetSender() Sender {
+	return sender
+}
+
+// SetSender replaces the sender, e.g. with a Fake in tests
+func SetSender(s Sender) {
+	sender = s
+}
+
+// Log writes push notifications to the log instead of sending them, for development
+type Log struct{}
+
+func (Log) Send(ctx context.Context, msg Message) error {
+	log.Printf("notify: push to %s: %s - %s %v", msg.Token, msg.Title, msg.Body, msg.Data)
+	return nil
+}
+
+// None drops push notifications; the in-app inbox still gets them
+type None struct{}
+
+func (None) Send(ctx context.Context, msg Message) error {
+	return nil
+}
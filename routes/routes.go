@@ -2,6 +2,7 @@ package routes
 
 import (
 	"kisahloka_be/controllers"
+	"kisahloka_be/storage"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -23,6 +24,8 @@ func Init() *echo.Echo {
 
 	// Origin
 	e.GET("/api/v1/origin", controllers.GetAllOrigins)
+	e.GET("/api/v1/origin/map", controllers.GetOriginMap)
+	e.GET("/api/v1/origin/tree", controllers.GetOriginTree)
 	e.GET("/api/v1/origin/:origin_id", controllers.GetOriginDetail)
 	e.POST("/api/v1/origin", controllers.CreateOrigin)
 	e.PUT("/api/v1/origin", controllers.UpdateOrigin)
@@ -35,6 +38,98 @@ func Init() *echo.Echo {
 	e.PUT("/api/v1/genre", controllers.UpdateGenre)
 	e.DELETE("/api/v1/genre/:genre_id", controllers.DeleteGenre)
 
+	// Moral Value
+	e.GET("/api/v1/moral_value", controllers.GetAllMoralValues)
+	e.GET("/api/v1/moral_value/:moral_value_id", controllers.GetMoralValueDetail)
+	e.POST("/api/v1/moral_value", controllers.CreateMoralValue)
+	e.PUT("/api/v1/moral_value", controllers.UpdateMoralValue)
+	e.DELETE("/api/v1/moral_value/:moral_value_id", controllers.DeleteMoralValue)
+	e.PUT("/api/v1/story/moral_values", controllers.SetStoryMoralValues)
+
+	// Character
+	e.GET("/api/v1/character", controllers.GetAllCharacters)
+	e.GET("/api/v1/character/:character_id", controllers.GetCharacterDetail)
+	e.GET("/api/v1/character/:character_id/stories", controllers.GetCharacterStories)
+	e.POST("/api/v1/character", controllers.CreateCharacter)
+	e.POST("/api/v1/character/:character_id/image", controllers.UploadCharacterImage)
+	e.PUT("/api/v1/character", controllers.UpdateCharacter)
+	e.DELETE("/api/v1/character/:character_id", controllers.DeleteCharacter)
+	e.PUT("/api/v1/story/characters", controllers.SetStoryCharacters)
+
+	// Tag
+	e.GET("/api/v1/tag", controllers.GetAllTags)
+	e.GET("/api/v1/tag/autocomplete", controllers.AutocompleteTags)
+	e.PUT("/api/v1/tag", controllers.UpdateTag)
+	e.DELETE("/api/v1/tag/:tag_id", controllers.DeleteTag)
+	e.PUT("/api/v1/story/tags", controllers.SetStoryTags)
+
+	// Collection
+	e.GET("/api/v1/collection", controllers.GetAllCollections)
+	e.GET("/api/v1/collection/:collection_id", controllers.GetCollectionDetail)
+	e.GET("/api/v1/admin/collection", controllers.GetAllCollectionsAdmin)
+	e.GET("/api/v1/admin/collection/:collection_id", controllers.GetCollectionDetailAdmin)
+	e.POST("/api/v1/collection", controllers.CreateCollection)
+	e.POST("/api/v1/collection/:collection_id/cover", controllers.UploadCollectionCover)
+	e.PUT("/api/v1/collection", controllers.UpdateCollection)
+	e.PUT("/api/v1/collection/stories", controllers.SetCollectionStories)
+	e.DELETE("/api/v1/collection/:collection_id", controllers.DeleteCollection)
+
+	// Like
+	e.PUT("/api/v1/story/like/:story_id", controllers.LikeStory)
+	e.DELETE("/api/v1/story/like/:story_id", controllers.UnlikeStory)
+
+	// Review
+	e.GET("/api/v1/story/reviews/:story_id", controllers.GetStoryReviews)
+	e.PUT("/api/v1/story/review", controllers.SaveStoryReview)
+	e.DELETE("/api/v1/story/review/:review_id", controllers.DeleteStoryReview)
+
+	// Comment
+	e.GET("/api/v1/story/comments/:story_id", controllers.GetStoryComments)
+	e.POST("/api/v1/story/comments/:story_id", controllers.CreateStoryComment)
+	e.GET("/api/v1/admin/comment", controllers.GetModerationQueue)
+	e.PUT("/api/v1/admin/comment/status", controllers.ModerateStoryComment)
+
+	// Reading Activity
+	e.POST("/api/v1/read_event", controllers.RecordReadEvent)
+	e.GET("/api/v1/streak/user/:user_id", controllers.GetReadingStreak)
+	e.GET("/api/v1/badge/user/:user_id", controllers.GetUserBadges)
+
+	// Follow
+	e.GET("/api/v1/follow/user/:user_id", controllers.GetUserFollows)
+	e.POST("/api/v1/follow", controllers.CreateFollow)
+	e.DELETE("/api/v1/follow/:follow_id", controllers.DeleteFollow)
+
+	// Notification
+	e.GET("/api/v1/notification/user/:user_id", controllers.GetNotifications)
+	e.PUT("/api/v1/notification/read", controllers.MarkNotificationsRead)
+	e.POST("/api/v1/push_token", controllers.SavePushToken)
+	e.DELETE("/api/v1/push_token", controllers.DeletePushToken)
+
+	// Badge
+	e.GET("/api/v1/admin/badge", controllers.GetAllBadges)
+	e.GET("/api/v1/admin/badge/:badge_id", controllers.GetBadgeDetail)
+	e.POST("/api/v1/admin/badge", controllers.CreateBadge)
+	e.PUT("/api/v1/admin/badge", controllers.UpdateBadge)
+	e.DELETE("/api/v1/admin/badge/:badge_id", controllers.DeleteBadge)
+
+	// Webhook
+	e.GET("/api/v1/admin/webhook", controllers.GetAllWebhooks)
+	e.GET("/api/v1/admin/webhook/:webhook_id", controllers.GetWebhookDetail)
+	e.POST("/api/v1/admin/webhook", controllers.CreateWebhook)
+	e.PUT("/api/v1/admin/webhook", controllers.UpdateWebhook)
+	e.DELETE("/api/v1/admin/webhook/:webhook_id", controllers.DeleteWebhook)
+	e.GET("/api/v1/admin/webhook/:webhook_id/deliveries", controllers.GetWebhookDeliveries)
+	e.GET("/api/v1/admin/webhook/delivery/:delivery_id", controllers.GetWebhookDeliveryDetail)
+	e.POST("/api/v1/admin/webhook/delivery/:delivery_id/retry", controllers.RetryWebhookDelivery)
+
+	// Feed
+	e.GET("/feeds/stories.atom", controllers.GetStoriesFeed)
+	e.GET("/feeds/stories.rss", controllers.GetStoriesFeed)
+	e.GET("/feeds/genre/:genre_id/stories.atom", controllers.GetGenreStoriesFeed)
+	e.GET("/feeds/genre/:genre_id/stories.rss", controllers.GetGenreStoriesFeed)
+	e.GET("/feeds/origin/:origin_id/stories.atom", controllers.GetOriginStoriesFeed)
+	e.GET("/feeds/origin/:origin_id/stories.rss", controllers.GetOriginStoriesFeed)
+
 	// Role
 	e.GET("/api/v1/role", controllers.GetAllRoles)
 	e.GET("/api/v1/role/:role_id", controllers.GetRoleDetail)
@@ -52,14 +147,67 @@ func Init() *echo.Echo {
 
 	// Story
 	e.GET("/api/v1/story", controllers.GetAllStoriesCompleted)
+	e.GET("/api/v1/admin/story", controllers.GetAllStoriesAdmin)
 	e.GET("/api/v1/story_preview", controllers.GetAllStoriesPreview)
 	e.GET("/api/v1/story/:story_id", controllers.GetStoryDetail)
 	e.GET("/api/v1/story/contents/:story_id", controllers.GetStoryContentOnStory)
 	e.POST("/api/v1/story", controllers.CreateStory)
 	e.PUT("/api/v1/story", controllers.UpdateStory)
+	e.PUT("/api/v1/story/status", controllers.UpdateStoryStatus)
+	e.PUT("/api/v1/story/contents", controllers.UpdateStoryContent)
 	e.DELETE("/api/v1/story/:story_id", controllers.DeleteStory)
 	e.GET("/api/v1/story_recommendation/random/:exclude_story_id", controllers.GetStoriesRecommendationRandom)
 
+	// Story Revision
+	e.GET("/api/v1/story/revisions/:story_id", controllers.GetStoryRevisions)
+	e.GET("/api/v1/story/revisions/:story_id/diff", controllers.GetStoryRevisionDiff)
+	e.POST("/api/v1/story/revisions/:story_id/restore", controllers.RestoreStoryRevision)
+
+	// Story Translation
+	e.GET("/api/v1/locale", controllers.GetSupportedLocales)
+	e.GET("/api/v1/story/locales/:story_id", controllers.GetStoryLocales)
+	e.GET("/api/v1/story/translations/:story_id/:locale", controllers.GetStoryTranslation)
+	e.PUT("/api/v1/story/translations", controllers.SaveStoryTranslation)
+	e.DELETE("/api/v1/story/translations/:story_id/:locale", controllers.DeleteStoryTranslation)
+
+	// Story Content Audio
+	e.POST("/api/v1/story/contents/:story_id/:order/audio", controllers.UploadStoryContentAudio)
+	e.GET("/api/v1/audio/:audio_id", controllers.StreamStoryContentAudio)
+	e.DELETE("/api/v1/audio/:audio_id", controllers.DeleteStoryContentAudio)
+
+	// Media
+	e.POST("/api/v1/story/thumbnail/:story_id", controllers.UploadStoryThumbnail)
+	e.POST("/api/v1/story/contents/:story_id/:order/image", controllers.UploadStoryContentImage)
+	if local, ok := storage.GetStorage().(*storage.Local); ok {
+		// Files kept on disk are served by the API itself
+		e.Static(local.BaseURL(), local.Dir())
+	}
+
+	// Story Content Timing
+	e.PUT("/api/v1/story/contents/:story_id/:order/timings", controllers.SaveStoryContentTiming)
+	e.DELETE("/api/v1/story/contents/:story_id/:order/timings/:locale", controllers.DeleteStoryContentTiming)
+
+	// Glossary
+	e.GET("/api/v1/glossary", controllers.GetAllGlossary)
+	e.GET("/api/v1/glossary/:glossary_id", controllers.GetGlossaryDetail)
+	e.POST("/api/v1/glossary", controllers.CreateGlossary)
+	e.PUT("/api/v1/glossary", controllers.UpdateGlossary)
+	e.DELETE("/api/v1/glossary/:glossary_id", controllers.DeleteGlossary)
+	e.GET("/api/v1/story/glossary/:story_id", controllers.GetStoryGlossary)
+	e.POST("/api/v1/story/contents/:story_id/:order/annotations", controllers.CreateStoryContentAnnotation)
+	e.DELETE("/api/v1/annotation/:annotation_id", controllers.DeleteStoryContentAnnotation)
+
+	// Quiz
+	e.GET("/api/v1/story/quiz/:story_id", controllers.GetStoryQuiz)
+	e.PUT("/api/v1/story/quiz", controllers.SaveStoryQuiz)
+	e.POST("/api/v1/story/quiz/:story_id/submit", controllers.SubmitStoryQuiz)
+	e.GET("/api/v1/story/quiz/:story_id/attempts", controllers.GetQuizAttempts)
+
+	// Taxonomy Translation
+	e.GET("/api/v1/taxonomy/translations/:taxonomy/:taxonomy_id", controllers.GetTaxonomyTranslations)
+	e.PUT("/api/v1/taxonomy/translations", controllers.SaveTaxonomyTranslation)
+	e.DELETE("/api/v1/taxonomy/translations/:taxonomy/:taxonomy_id/:locale", controllers.DeleteTaxonomyTranslation)
+
 	// Bookmark
 	e.GET("/api/v1/bookmark", controllers.GetAllBookmarks)
 	e.GET("/api/v1/bookmark/user/:user_id", controllers.GetAllBookmarksByUserID)
@@ -70,6 +218,15 @@ func Init() *echo.Echo {
 
 	// Home
 	e.GET("/api/v1/home", controllers.GetHomeData)
+	e.PUT("/api/v1/story/progress", controllers.SaveReadingProgress)
+
+	// Home Section
+	e.GET("/api/v1/admin/home_section", controllers.GetAllHomeSections)
+	e.GET("/api/v1/admin/home_section/:section_id", controllers.GetHomeSectionDetail)
+	e.POST("/api/v1/admin/home_section", controllers.CreateHomeSection)
+	e.PUT("/api/v1/admin/home_section", controllers.UpdateHomeSection)
+	e.PUT("/api/v1/admin/home_section/order", controllers.ReorderHomeSections)
+	e.DELETE("/api/v1/admin/home_section/:section_id", controllers.DeleteHomeSection)
 
 	return e
 }
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"kisahloka_be/config"
+	"log"
+)
+
+// ErrNotFound is returned when a key does not exist in the storage
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage keeps uploaded media files under slash-separated keys such as
+// "images/ab/abcdef.jpg"
+type Storage interface {
+	// Put stores size bytes read from r under key
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Open returns a seekable reader over the object so it can be served with Range support
+	Open(key string) (io.ReadSeekCloser, error)
+	// Delete removes the object; deleting a missing key is not an error
+	Delete(key string) error
+	// URL returns the address clients download the object from
+	URL(key string) string
+}
+
+var store Storage
+
+// StorageInit creates the storage configured by STORAGE_DRIVER ("local" or "s3")
+func StorageInit() {
+	conf := config.GetMediaConfig()
+
+	switch conf.STORAGE_DRIVER {
+	case "s3":
+		store = NewS3(S3Config{
+			Endpoint:  conf.S3_ENDPOINT,
+			Region:    conf.S3_REGION,
+			Bucket:    conf.S3_BUCKET,
+			AccessKey: conf.S3_ACCESS_KEY,
+			SecretKey: conf.S3_SECRET_KEY,
+			PublicURL: conf.S3_PUBLIC_URL,
+		})
+	case "", "local":
+		store = NewLocal(conf.MEDIA_DIR, conf.MEDIA_BASE_URL)
+	default:
+		log.Fatalf("storage: unknown STORAGE_DRIVER %q", conf.STORAGE_DRIVER)
+	}
+}
+
+func GetStorage() Storage {
+	return store
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"kisahloka_be/config"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every delivery. The signature is an HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret, so receivers can
+// verify the payload and reject old replays.
+const (
+	HeaderEvent     = "X-Kisahloka-Event"
+	HeaderDelivery  = "X-Kisahloka-Delivery"
+	HeaderTimestamp = "X-Kisahloka-Timestamp"
+	HeaderSignature = "X-Kisahloka-Signature"
+)
+
+const (
+	backoffBase = 30 * time.Second
+	backoffMax  = 6 * time.Hour
+)
+
+var (
+	client      = &http.Client{Timeout: 10 * time.Second}
+	maxAttempts = 8
+)
+
+// WebhookInit loads the retry limit and request timeout from the config
+func WebhookInit() {
+	conf := config.GetWebhookConfig()
+	client = &http.Client{Timeout: time.Duration(conf.WEBHOOK_TIMEOUT_SECONDS) * time.Second}
+	maxAttempts = conf.WEBHOOK_MAX_ATTEMPTS
+}
+
+// MaxAttempts is the number of attempts after which a delivery is given up
+func MaxAttempts() int {
+	return maxAttempts
+}
+
+// Backoff is how long to wait after the given failed attempt: 30s, 1m, 2m, ...
+// doubling up to 6 hours
+func Backoff(attempt int) time.Duration {
+	delay := backoffBase
+	for i := 1; i < attempt && delay < backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > backoffMax {
+		delay = backoffMax
+	}
+	return delay
+}
+
+// Sign returns the signature header value for a body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Request is one signed POST to a subscriber
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID int
+	Body       []byte
+}
+
+// Deliver posts the request and returns the response status code. Any status
+// outside 2xx is an error, as is a request that could not be sent (status 0).
+func Deliver(ctx context.Context, req Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "Kisahloka-Webhook/1.0")
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, strconv.Itoa(req.DeliveryID))
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Keep a little of the response to explain failures in the delivery log
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	return resp.StatusCode, nil
+}